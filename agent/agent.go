@@ -3,30 +3,66 @@ package agent
 import (
 	"context"
 	"fmt"
+	"net"
+	"slices"
 	"sync"
+	"time"
 
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/forward"
 	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/socks5"
 	"github.com/fr13n8/raido/proxy/transport"
 	"github.com/fr13n8/raido/proxy/tunnel"
-	"github.com/lithammer/shortuuid/v4"
+	"github.com/rs/zerolog/log"
 )
 
 type Agent struct {
 	ID       string
 	Hostname string
+	// Parent is the ID of the agent relaying this agent's connection, empty
+	// for agents connected directly to the proxy.
+	Parent   string
 	conn     transport.StreamConn
 	mu       sync.RWMutex
 	routes   []string
 	tunnel   *tunnel.Tunnel
+	forwards map[string]*forward.Forward
+	reverse  map[uint32]*forward.ReverseForward
+	// nextReverseID is the wire ID of the next reverse forward.
+	nextReverseID uint32
+	socks         *socks5.Server
+	// followRoutes makes the tunnel follow the routes the agent reports.
+	followRoutes bool
+
+	// Version and Capabilities are negotiated during the handshake.
+	Version      uint16
+	Capabilities protocol.Capability
+	Build        protocol.BuildInfo
+	Host         protocol.HostInfo
+
+	// Connected is when the current connection was established and Started
+	// when the agent process started, both by the proxy's clock.
+	Connected time.Time
+	Started   time.Time
+	// RemoteAddr is the agent's address as seen by the proxy and Transport
+	// the name of the transport it is connected over.
+	RemoteAddr string
+	Transport  string
+	// Listener is the ID of the proxy listener that accepted the connection.
+	Listener string
 }
 
-func New(name string, conn transport.StreamConn, routes []string) *Agent {
-	agentiId := shortuuid.New()
+// New creates the agent connected over conn. id identifies the agent across
+// connections. Loopback and link-local routes are dropped.
+func New(id, name string, conn transport.StreamConn, routes []string) *Agent {
 	return &Agent{
-		ID:       agentiId,
+		ID:       id,
 		Hostname: name,
 		conn:     conn,
-		routes:   routes,
+		routes:   filterRoutes(routes),
+		forwards: make(map[string]*forward.Forward),
+		reverse:  make(map[uint32]*forward.ReverseForward),
 	}
 }
 
@@ -34,6 +70,19 @@ func (a *Agent) Close() error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	for id, f := range a.forwards {
+		f.Close()
+		delete(a.forwards, id)
+	}
+	for id, f := range a.reverse {
+		f.Close()
+		delete(a.reverse, id)
+	}
+	if a.socks != nil {
+		a.socks.Close()
+		a.socks = nil
+	}
+
 	if a.tunnel != nil {
 		if err := a.tunnel.Close(); err != nil {
 			return fmt.Errorf("failed to close tunnel: %w", err)
@@ -47,7 +96,42 @@ func (a *Agent) Close() error {
 	return nil
 }
 
-func (a *Agent) TunnelStart(ctx context.Context, routes []string) error {
+// adopt takes over the tunnel of old, an earlier connection of the same agent,
+// and rebinds it to a's connection.
+func (a *Agent) adopt(old *Agent) {
+	old.mu.Lock()
+	t := old.tunnel
+	old.tunnel = nil
+	follow, oldRoutes := old.followRoutes, old.routes
+	old.mu.Unlock()
+
+	if t == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := t.Rebind(a.conn, a.Capabilities); err != nil {
+		log.Error().Err(err).Str("agent_id", a.ID).Msg("failed to rebind tunnel to the new connection")
+		if err := t.Close(); err != nil {
+			log.Error().Err(err).Str("agent_id", a.ID).Msg("failed to close tunnel")
+		}
+		return
+	}
+	a.tunnel = t
+	a.followRoutes = follow
+	if follow {
+		a.moveTunnelRoutes(oldRoutes, a.routes)
+	}
+
+	log.Info().Str("agent_id", a.ID).Str("interface", t.Name()).Msg("tunnel rebound to the new connection")
+}
+
+// TunnelStart starts a tunnel to routes, all routes of the agent if empty.
+// With follow, routes the agent reports later are added to the tunnel and
+// routes it no longer reports are removed.
+func (a *Agent) TunnelStart(ctx context.Context, routes []string, follow bool, ct config.Conntrack) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -55,11 +139,12 @@ func (a *Agent) TunnelStart(ctx context.Context, routes []string) error {
 		return nil
 	}
 
-	tun, err := tunnel.NewTunnel(ctx, a.conn)
+	tun, err := tunnel.NewTunnel(ctx, a.conn, a.Capabilities, ct)
 	if err != nil {
 		return fmt.Errorf("failed to create tunnel: %w", err)
 	}
 	a.tunnel = tun
+	a.followRoutes = follow
 
 	if len(routes) == 0 {
 		routes = a.routes
@@ -152,6 +237,30 @@ func (a *Agent) TunnelLoopbackRoute() (string, error) {
 	return a.tunnel.GetLoopbackRoute()
 }
 
+func (a *Agent) TunnelLoopbackRoute6() (string, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.tunnel == nil {
+		return "", fmt.Errorf("tunnel is not initialized")
+	}
+
+	return a.tunnel.GetLoopbackRoute6()
+}
+
+// TunnelResolverAddress returns the address of the tunnel's DNS resolver, or
+// an empty string if the agent does not resolve names.
+func (a *Agent) TunnelResolverAddress() (string, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.tunnel == nil {
+		return "", fmt.Errorf("tunnel is not initialized")
+	}
+
+	return a.tunnel.ResolverAddress()
+}
+
 func (a *Agent) TunnelStatus() string {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -174,9 +283,257 @@ func (a *Agent) TunnelName() string {
 	return a.tunnel.Name()
 }
 
+// Conn returns the connection to the agent.
+func (a *Agent) Conn() transport.StreamConn {
+	return a.conn
+}
+
 func (a *Agent) Routes() []string {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
 	return a.routes
 }
+
+// ForwardAdd starts forwarding connections accepted on local to remote
+// through the agent. network is "tcp" or "udp".
+func (a *Agent) ForwardAdd(ctx context.Context, network, local, remote string) (*forward.Forward, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := forward.Listen(ctx, a.conn, a.Capabilities, network, local, remote)
+	if err != nil {
+		return nil, err
+	}
+	a.forwards[f.ID] = f
+
+	return f, nil
+}
+
+// ForwardRemove stops the forward with the given id. It reports whether the
+// forward belonged to the agent.
+func (a *Agent) ForwardRemove(id string) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, ok := a.forwards[id]
+	if !ok {
+		return false, nil
+	}
+	delete(a.forwards, id)
+
+	return true, f.Close()
+}
+
+func (a *Agent) Forwards() []*forward.Forward {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	forwards := make([]*forward.Forward, 0, len(a.forwards))
+	for _, f := range a.forwards {
+		forwards = append(forwards, f)
+	}
+
+	return forwards
+}
+
+// ReverseForwardAdd asks the agent to listen on listen and relays every
+// connection it accepts to target, dialed from the proxy host.
+func (a *Agent) ReverseForwardAdd(ctx context.Context, listen, target string) (*forward.ReverseForward, error) {
+	if !a.Capabilities.Has(protocol.CapReverseForward) {
+		return nil, fmt.Errorf("agent does not support reverse forwarding")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.nextReverseID++
+	f, err := forward.ListenReverse(ctx, a.conn, a.nextReverseID, listen, target)
+	if err != nil {
+		return nil, err
+	}
+	a.reverse[f.WireID] = f
+
+	// Forget the forward if the agent stops listening on its own.
+	go func() {
+		<-f.Done()
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.reverse[f.WireID] == f {
+			delete(a.reverse, f.WireID)
+		}
+	}()
+
+	return f, nil
+}
+
+// ReverseForwardRemove stops the reverse forward with the given id. It
+// reports whether the forward belonged to the agent.
+func (a *Agent) ReverseForwardRemove(id string) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for wireID, f := range a.reverse {
+		if f.ID == id {
+			delete(a.reverse, wireID)
+			return true, f.Close()
+		}
+	}
+
+	return false, nil
+}
+
+func (a *Agent) ReverseForwards() []*forward.ReverseForward {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	forwards := make([]*forward.ReverseForward, 0, len(a.reverse))
+	for _, f := range a.reverse {
+		forwards = append(forwards, f)
+	}
+
+	return forwards
+}
+
+// SocksStart starts a SOCKS5 server on address that relays connections
+// through the agent.
+func (a *Agent) SocksStart(ctx context.Context, address string) (*socks5.Server, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.socks != nil {
+		return nil, fmt.Errorf("SOCKS5 server is already listening on %s", a.socks.Address)
+	}
+
+	s, err := socks5.Listen(ctx, a.conn, a.Capabilities, address)
+	if err != nil {
+		return nil, err
+	}
+	a.socks = s
+
+	return s, nil
+}
+
+func (a *Agent) SocksStop() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.socks == nil {
+		return fmt.Errorf("SOCKS5 server is not running")
+	}
+
+	s := a.socks
+	a.socks = nil
+
+	return s.Close()
+}
+
+// Socks returns the agent's SOCKS5 server, or nil if it is not running.
+func (a *Agent) Socks() *socks5.Server {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.socks
+}
+
+// HandleStream serves a stream opened by the agent whose first frame was dec.
+func (a *Agent) HandleStream(ctx context.Context, stream transport.Stream, dec protocol.Data) {
+	// Streams carry a single request and are never reused.
+	defer stream.Close()
+
+	switch dec.Command {
+	case protocol.RoutesUpdateCmd:
+		var update protocol.RoutesUpdate
+		if err := update.UnmarshalBinary(dec.Body); err != nil {
+			log.Error().Err(err).Msg("could not decode routes update")
+			return
+		}
+
+		a.setRoutes(update.Routes)
+	case protocol.ReverseConnectCmd:
+		var req protocol.ReverseConnectRequest
+		if err := req.UnmarshalBinary(dec.Body); err != nil {
+			log.Error().Err(err).Msg("could not decode reverse connect request")
+			return
+		}
+
+		a.mu.RLock()
+		f, ok := a.reverse[req.ID]
+		a.mu.RUnlock()
+		if !ok {
+			log.Warn().Uint32("id", req.ID).Str("agent_id", a.ID).Msg("connection for unknown reverse forward")
+			if err := protocol.Send(stream, protocol.ConnectResponseCmd, protocol.ConnectResponse{Reason: protocol.ReasonRefused}); err != nil {
+				log.Error().Err(err).Str("agent_id", a.ID).Msg("could not encode connection response")
+			}
+			return
+		}
+
+		f.Serve(ctx, stream, req)
+	default:
+		log.Error().Stringer("command", dec.Command).Str("agent_id", a.ID).Msg("unknown command")
+	}
+}
+
+// setRoutes replaces the agent's routes with those it reported after a
+// network change.
+func (a *Agent) setRoutes(routes []string) {
+	routes = filterRoutes(routes)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	old := a.routes
+	if len(old) == len(routes) && !slices.ContainsFunc(routes, func(r string) bool { return !slices.Contains(old, r) }) {
+		return
+	}
+	a.routes = routes
+	log.Info().Str("agent_id", a.ID).Strs("routes", routes).Msg("agent routes changed")
+
+	if a.tunnel != nil && a.followRoutes {
+		a.moveTunnelRoutes(old, routes)
+	}
+}
+
+// moveTunnelRoutes updates the tunnel from the agent routes old to routes.
+// The caller holds a.mu.
+func (a *Agent) moveTunnelRoutes(old, routes []string) {
+	var added, removed []string
+	for _, r := range routes {
+		if !slices.Contains(old, r) {
+			added = append(added, r)
+		}
+	}
+	for _, r := range old {
+		if !slices.Contains(routes, r) {
+			removed = append(removed, r)
+		}
+	}
+
+	if len(removed) > 0 {
+		if err := a.tunnel.RemoveRoutes(removed...); err != nil {
+			log.Error().Err(err).Str("agent_id", a.ID).Msg("failed to remove routes from tunnel")
+		}
+	}
+	if len(added) > 0 {
+		if err := a.tunnel.AddRoutes(added...); err != nil {
+			log.Error().Err(err).Str("agent_id", a.ID).Msg("failed to add routes to tunnel")
+		}
+	}
+}
+
+// filterRoutes drops the routes that cannot be tunneled: loopback and
+// link-local networks and invalid entries.
+func filterRoutes(routes []string) []string {
+	var filtered []string
+	for _, route := range routes {
+		ip, _, err := net.ParseCIDR(route)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to parse route")
+			continue
+		}
+		if !ip.IsLoopback() && !ip.IsLinkLocalUnicast() {
+			filtered = append(filtered, route)
+		}
+	}
+	return filtered
+}
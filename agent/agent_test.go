@@ -0,0 +1,25 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+)
+
+func TestRoutesUpdate(t *testing.T) {
+	a := New("id", "host", nil, []string{"10.1.0.2/24", "127.0.0.1/8", "fe80::1/64"})
+	if got := a.Routes(); !slices.Equal(got, []string{"10.1.0.2/24"}) {
+		t.Errorf("got routes %v, want loopback and link-local routes dropped", got)
+	}
+
+	body, _ := protocol.RoutesUpdate{Routes: []string{"10.1.0.2/24", "192.168.5.3/24", "::1/128"}}.MarshalBinary()
+	_, stream := net.Pipe()
+	a.HandleStream(context.Background(), stream, protocol.Data{Command: protocol.RoutesUpdateCmd, Body: body})
+
+	if got := a.Routes(); !slices.Equal(got, []string{"10.1.0.2/24", "192.168.5.3/24"}) {
+		t.Errorf("got routes %v after the update", got)
+	}
+}
@@ -3,7 +3,10 @@ package agent
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"sync"
+
+	"github.com/rs/zerolog/log"
 )
 
 type Manager struct {
@@ -31,28 +34,83 @@ func (m *Manager) GetAgent(id string) *Agent {
 	return m.agents[id]
 }
 
+// RemoveAgent closes the agent and every agent relayed through it.
 func (m *Manager) RemoveAgent(id string) error {
 	m.rwMutex.Lock()
 	defer m.rwMutex.Unlock()
 
+	return m.removeAgent(id)
+}
+
+// RemoveAgentInstance removes a only while it is the agent registered under
+// its ID, so a stale connection cannot remove the agent that replaced it. It
+// reports whether a was removed.
+func (m *Manager) RemoveAgentInstance(a *Agent) (bool, error) {
+	m.rwMutex.Lock()
+	defer m.rwMutex.Unlock()
+
+	if m.agents[a.ID] != a {
+		return false, nil
+	}
+	return true, m.removeAgent(a.ID)
+}
+
+// RemoveListenerAgents closes the agents connected to the proxy listener with
+// the given ID and every agent relayed through them.
+func (m *Manager) RemoveListenerAgents(listener string) error {
+	m.rwMutex.Lock()
+	defer m.rwMutex.Unlock()
+
+	var errs []error
+	for id, a := range m.agents {
+		if a.Listener == listener {
+			if err := m.removeAgent(id); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *Manager) removeAgent(id string) error {
 	a, ok := m.agents[id]
 	if !ok {
 		return nil
 	}
 
+	var errs []error
+	for childId, child := range m.agents {
+		if child.Parent == id {
+			if err := m.removeAgent(childId); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
 	if err := a.Close(); err != nil {
-		return fmt.Errorf("failed to close agent: %w", err)
+		errs = append(errs, fmt.Errorf("failed to close agent: %w", err))
 	}
 
 	delete(m.agents, id)
 
-	return nil
+	return errors.Join(errs...)
 }
 
+// AddAgent registers a. An agent already registered under the same ID, left
+// over from a previous connection, is replaced: its tunnel moves to a and the
+// rest of it is closed. Agents it relayed keep their entries until they
+// reconnect through a.
 func (m *Manager) AddAgent(a *Agent) *Agent {
 	m.rwMutex.Lock()
 	defer m.rwMutex.Unlock()
 
+	if old, ok := m.agents[a.ID]; ok && old != a {
+		a.adopt(old)
+		if err := old.Close(); err != nil {
+			log.Error().Err(err).Str("agent_id", a.ID).Msg("failed to close replaced agent")
+		}
+	}
 	m.agents[a.ID] = a
 
 	return m.agents[a.ID]
@@ -86,9 +144,11 @@ func (m *Manager) Cleanup() error {
 	return nil
 }
 
+// GetAllAgents returns a copy of the registered agents by ID, safe to range
+// over while agents connect and disconnect.
 func (cm *Manager) GetAllAgents() map[string]*Agent {
-	cm.rwMutex.Lock()
-	defer cm.rwMutex.Unlock()
+	cm.rwMutex.RLock()
+	defer cm.rwMutex.RUnlock()
 
-	return cm.agents
+	return maps.Clone(cm.agents)
 }
@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/fr13n8/raido/proxy/transport"
+)
+
+type closeConn struct {
+	transport.StreamConn
+	closed *bool
+}
+
+func (c closeConn) CloseWithError(code uint64, reason string) error {
+	*c.closed = true
+	return nil
+}
+
+func TestReplaceAgent(t *testing.T) {
+	m := &Manager{agents: make(map[string]*Agent)}
+
+	var oldClosed, newClosed bool
+	old := New("id", "host", closeConn{closed: &oldClosed}, nil)
+	m.AddAgent(old)
+	a := New("id", "host", closeConn{closed: &newClosed}, nil)
+	m.AddAgent(a)
+
+	if got := m.GetAgent("id"); got != a {
+		t.Fatal("reconnected agent did not replace the old entry")
+	}
+	if len(m.GetAllAgents()) != 1 {
+		t.Errorf("got %d agents, want 1", len(m.GetAllAgents()))
+	}
+	if !oldClosed {
+		t.Error("replaced agent was not closed")
+	}
+
+	// The old connection going away must not remove its successor.
+	if removed, _ := m.RemoveAgentInstance(old); removed || m.GetAgent("id") != a {
+		t.Error("stale agent removed its replacement")
+	}
+	if removed, _ := m.RemoveAgentInstance(a); !removed || !newClosed {
+		t.Error("current agent was not removed")
+	}
+}
+
+func TestRemoveListenerAgents(t *testing.T) {
+	m := &Manager{agents: make(map[string]*Agent)}
+
+	var aClosed, childClosed, otherClosed bool
+	a := New("a", "host", closeConn{closed: &aClosed}, nil)
+	a.Listener = "quic"
+	m.AddAgent(a)
+	child := New("child", "host", closeConn{closed: &childClosed}, nil)
+	child.Parent = "a"
+	child.Listener = "quic"
+	m.AddAgent(child)
+	other := New("other", "host", closeConn{closed: &otherClosed}, nil)
+	other.Listener = "tcp"
+	m.AddAgent(other)
+
+	if err := m.RemoveListenerAgents("quic"); err != nil {
+		t.Fatal(err)
+	}
+	if !aClosed || !childClosed || m.GetAgent("a") != nil || m.GetAgent("child") != nil {
+		t.Error("agents of the stopped listener were not removed")
+	}
+	if otherClosed || m.GetAgent("other") != other {
+		t.Error("agent of another listener was removed")
+	}
+}
+
+func TestGetAllAgentsCopy(t *testing.T) {
+	m := &Manager{agents: make(map[string]*Agent)}
+	var closed bool
+	m.AddAgent(New("a", "host", closeConn{closed: &closed}, nil))
+
+	agents := m.GetAllAgents()
+	m.AddAgent(New("b", "host", closeConn{closed: &closed}, nil))
+	if len(agents) != 1 {
+		t.Errorf("copy has %d agents after another one connected, want 1", len(agents))
+	}
+}
@@ -7,11 +7,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fr13n8/raido/config"
 	"github.com/fr13n8/raido/proto/service"
 	"github.com/fr13n8/raido/proto/service/serviceconnect"
 
 	"connectrpc.com/connect"
-	"github.com/fr13n8/raido/config"
 	"github.com/peterbourgon/unixtransport"
 )
 
@@ -120,22 +120,40 @@ func (c *Client) AgentRemove(ctx context.Context, agentId string) error {
 	return nil
 }
 
-func (c *Client) ProxyStart(ctx context.Context, proxyAddr, protocol string) ([]byte, error) {
+func (c *Client) ProxyList(ctx context.Context) ([]*service.ProxyListener, error) {
+	resp, err := c.serviceClient.ProxyList(ctx, &connect.Request[service.Empty]{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request proxy listeners: %w", err)
+	}
+
+	return resp.Msg.GetListeners(), nil
+}
+
+// ProxyStart starts a proxy listener on the transport URIs in uri, comma
+// separated, and returns its ID, certificate hash and the connection string
+// agents connect with.
+func (c *Client) ProxyStart(ctx context.Context, uri, authorizedKeys string) (*service.ProxyStartResponse, error) {
 	pStartResp, err := c.serviceClient.ProxyStart(ctx, &connect.Request[service.ProxyStartRequest]{
 		Msg: &service.ProxyStartRequest{
-			ProxyAddress:      proxyAddr,
-			TransportProtocol: protocol,
+			Uri:            uri,
+			AuthorizedKeys: authorizedKeys,
 		},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to request proxy start: %w", err)
 	}
 
-	return pStartResp.Msg.GetCertHash(), nil
+	return pStartResp.Msg, nil
 }
 
-func (c *Client) ProxyStop(ctx context.Context) error {
-	_, err := c.serviceClient.ProxyStop(ctx, &connect.Request[service.Empty]{})
+// ProxyStop stops the proxy listener with the given ID, all of them if id is
+// empty.
+func (c *Client) ProxyStop(ctx context.Context, id string) error {
+	_, err := c.serviceClient.ProxyStop(ctx, &connect.Request[service.ProxyStopRequest]{
+		Msg: &service.ProxyStopRequest{
+			Id: id,
+		},
+	})
 	if err != nil {
 		return fmt.Errorf("failed to request proxy stop: %w", err)
 	}
@@ -143,6 +161,28 @@ func (c *Client) ProxyStop(ctx context.Context) error {
 	return nil
 }
 
+func (c *Client) HTTPProxyStart(ctx context.Context, address string) (string, error) {
+	resp, err := c.serviceClient.HTTPProxyStart(ctx, &connect.Request[service.HTTPProxyStartRequest]{
+		Msg: &service.HTTPProxyStartRequest{
+			Address: address,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to request HTTP proxy start: %w", err)
+	}
+
+	return resp.Msg.GetAddress(), nil
+}
+
+func (c *Client) HTTPProxyStop(ctx context.Context) error {
+	_, err := c.serviceClient.HTTPProxyStop(ctx, &connect.Request[service.Empty]{})
+	if err != nil {
+		return fmt.Errorf("failed to request HTTP proxy stop: %w", err)
+	}
+
+	return nil
+}
+
 func (c *Client) AgentList(ctx context.Context) (map[string]*service.Agent, error) {
 	resp, err := c.serviceClient.AgentList(ctx, &connect.Request[service.Empty]{})
 	if err != nil {
@@ -152,11 +192,14 @@ func (c *Client) AgentList(ctx context.Context) (map[string]*service.Agent, erro
 	return resp.Msg.GetAgents(), nil
 }
 
-func (c *Client) TunnelStart(ctx context.Context, agentId string, routes []string) error {
+func (c *Client) TunnelStart(ctx context.Context, agentId string, routes []string, followRoutes bool, ct config.Conntrack) error {
 	_, err := c.serviceClient.TunnelStart(ctx, &connect.Request[service.TunnelStartRequest]{
 		Msg: &service.TunnelStartRequest{
-			AgentId: agentId,
-			Routes:  routes,
+			AgentId:        agentId,
+			Routes:         routes,
+			UdpIdleTimeout: uint32(ct.IdleTimeout / time.Second),
+			UdpMaxFlows:    uint32(ct.MaxFlows),
+			FollowRoutes:   followRoutes,
 		},
 	})
 	if err != nil {
@@ -178,3 +221,114 @@ func (c *Client) TunnelStop(ctx context.Context, agentId string) error {
 
 	return nil
 }
+
+func (c *Client) ForwardList(ctx context.Context) ([]*service.Forward, error) {
+	resp, err := c.serviceClient.ForwardList(ctx, &connect.Request[service.Empty]{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request forwards: %w", err)
+	}
+
+	return resp.Msg.GetForwards(), nil
+}
+
+func (c *Client) ForwardAdd(ctx context.Context, agentId, protocol, local, remote string) (*service.Forward, error) {
+	resp, err := c.serviceClient.ForwardAdd(ctx, &connect.Request[service.ForwardAddRequest]{
+		Msg: &service.ForwardAddRequest{
+			AgentId:  agentId,
+			Protocol: protocol,
+			Local:    local,
+			Remote:   remote,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request forward add: %w", err)
+	}
+
+	return resp.Msg.GetForward(), nil
+}
+
+func (c *Client) ForwardRemove(ctx context.Context, id string) error {
+	_, err := c.serviceClient.ForwardRemove(ctx, &connect.Request[service.ForwardRemoveRequest]{
+		Msg: &service.ForwardRemoveRequest{
+			Id: id,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to request forward remove: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) ReverseForwardList(ctx context.Context) ([]*service.ReverseForward, error) {
+	resp, err := c.serviceClient.ReverseForwardList(ctx, &connect.Request[service.Empty]{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request reverse forwards: %w", err)
+	}
+
+	return resp.Msg.GetForwards(), nil
+}
+
+func (c *Client) ReverseForwardAdd(ctx context.Context, agentId, agentListen, target string) (*service.ReverseForward, error) {
+	resp, err := c.serviceClient.ReverseForwardAdd(ctx, &connect.Request[service.ReverseForwardAddRequest]{
+		Msg: &service.ReverseForwardAddRequest{
+			AgentId:     agentId,
+			AgentListen: agentListen,
+			Target:      target,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request reverse forward add: %w", err)
+	}
+
+	return resp.Msg.GetForward(), nil
+}
+
+func (c *Client) ReverseForwardRemove(ctx context.Context, id string) error {
+	_, err := c.serviceClient.ReverseForwardRemove(ctx, &connect.Request[service.ReverseForwardRemoveRequest]{
+		Msg: &service.ReverseForwardRemoveRequest{
+			Id: id,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to request reverse forward remove: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) SocksList(ctx context.Context) ([]*service.Socks, error) {
+	resp, err := c.serviceClient.SocksList(ctx, &connect.Request[service.Empty]{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request SOCKS5 servers: %w", err)
+	}
+
+	return resp.Msg.GetServers(), nil
+}
+
+func (c *Client) SocksStart(ctx context.Context, agentId, address string) (*service.Socks, error) {
+	resp, err := c.serviceClient.SocksStart(ctx, &connect.Request[service.SocksStartRequest]{
+		Msg: &service.SocksStartRequest{
+			AgentId: agentId,
+			Address: address,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request SOCKS5 server start: %w", err)
+	}
+
+	return resp.Msg.GetServer(), nil
+}
+
+func (c *Client) SocksStop(ctx context.Context, agentId string) error {
+	_, err := c.serviceClient.SocksStop(ctx, &connect.Request[service.SocksStopRequest]{
+		Msg: &service.SocksStopRequest{
+			AgentId: agentId,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to request SOCKS5 server stop: %w", err)
+	}
+
+	return nil
+}
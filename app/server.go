@@ -1,11 +1,19 @@
 package app
 
 import (
+	"cmp"
 	"context"
+	"crypto"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	pb "github.com/fr13n8/raido/proto/service"
 	"github.com/fr13n8/raido/proto/service/serviceconnect"
@@ -14,9 +22,13 @@ import (
 	"connectrpc.com/connect"
 	"github.com/fr13n8/raido/agent"
 	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/forward"
+	"github.com/fr13n8/raido/proxy/httpproxy"
 	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/socks5"
 	"github.com/fr13n8/raido/proxy/transport"
-	"github.com/fr13n8/raido/proxy/transport/quic"
+	_ "github.com/fr13n8/raido/proxy/transport/all"
+	"github.com/fr13n8/raido/proxy/transport/ssh"
 	"github.com/fr13n8/raido/proxy/transport/tcp"
 	"github.com/fr13n8/raido/utils/certs"
 	"github.com/rs/zerolog/log"
@@ -28,24 +40,61 @@ import (
 var _ serviceconnect.RaidoServiceHandler = (*ServiceHandler)(nil)
 
 type ServiceHandler struct {
-	agentManager        *agent.Manager
-	proxyServerInstance *proxy.Server
-	ctx                 context.Context
-	proxyCancell        context.CancelFunc
+	agentManager *agent.Manager
+	httpProxy    *httpproxy.Server
+	ctx          context.Context
+	// proxies are the running proxy listeners by ID.
+	proxies   map[string]*proxyListener
+	proxiesMu sync.Mutex
 	serviceconnect.UnimplementedRaidoServiceHandler
 }
 
+// proxyListener is a running proxy server, the agents of all of them share
+// the agent manager.
+type proxyListener struct {
+	server     *proxy.Server
+	cancel     context.CancelFunc
+	transports []*pb.ProxyTransport
+}
+
+func (s *ServiceHandler) ProxyList(ctx context.Context, req *connect.Request[pb.Empty]) (*connect.Response[pb.ProxyListResponse], error) {
+	log.Info().Any("req", req).Msg("ProxyList()")
+
+	// Agents carry the ID of the listener that accepted them, or accepted
+	// the agent relaying them.
+	agents := make(map[string]uint32)
+	for _, a := range s.agentManager.GetAllAgents() {
+		agents[a.Listener]++
+	}
+
+	s.proxiesMu.Lock()
+	defer s.proxiesMu.Unlock()
+
+	listeners := make([]*pb.ProxyListener, 0, len(s.proxies))
+	for id, p := range s.proxies {
+		listeners = append(listeners, &pb.ProxyListener{
+			Id:         id,
+			Transports: p.transports,
+			Agents:     agents[id],
+		})
+	}
+	slices.SortFunc(listeners, func(a, b *pb.ProxyListener) int {
+		return cmp.Or(strings.Compare(a.Transports[0].Address, b.Transports[0].Address), strings.Compare(a.Id, b.Id))
+	})
+
+	return connect.NewResponse(&pb.ProxyListResponse{
+		Listeners: listeners,
+	}), nil
+}
+
 func (s *ServiceHandler) ProxyStart(ctx context.Context, req *connect.Request[pb.ProxyStartRequest]) (*connect.Response[pb.ProxyStartResponse], error) {
 	log.Info().Any("req", req).Msg("ProxyStart()")
 
-	if s.proxyServerInstance != nil {
-		log.Info().Msg("proxy server instance already exists")
-		return nil, fmt.Errorf("proxy server already exists")
+	uris := req.Msg.Uri
+	if uris == "" {
+		uris = req.Msg.TransportProtocol + "://" + req.Msg.ProxyAddress
 	}
 
-	proxyAddr := req.Msg.ProxyAddress
-	transportProtocol := req.Msg.TransportProtocol
-
 	cm := certs.NewSelfSignedCertManager("raido_proxy", config.RaidoPath)
 	tc, err := cm.GetTLSConfig()
 	if err != nil {
@@ -53,66 +102,192 @@ func (s *ServiceHandler) ProxyStart(ctx context.Context, req *connect.Request[pb
 		return nil, fmt.Errorf("failed to get tls config")
 	}
 	tc.NextProtos = []string{protocol.Name}
+	certHash, err := cm.GetCertHash()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get cert hash")
+		return nil, fmt.Errorf("failed to get cert hash")
+	}
+	// The key of the certificate doubles as the host key of the ssh transport.
+	key, _ := tc.Certificates[0].PrivateKey.(crypto.Signer)
+
+	// The first URI is the main transport, agents may fall back to the others.
+	var endpoints []proxy.Endpoint
+	var transports []*pb.ProxyTransport
+	var connURIs []string
+	for uri := range strings.SplitSeq(uris, ",") {
+		u, err := transport.ParseURI(strings.TrimSpace(uri))
+		if err != nil {
+			return nil, err
+		}
+		if req.Msg.AuthorizedKeys != "" {
+			q := u.Query()
+			q.Set("authorized_keys", req.Msg.AuthorizedKeys)
+			u.RawQuery = q.Encode()
+		}
 
-	var transportImpl transport.Transport
-	switch transportProtocol {
-	case "quic":
-		transportImpl = quic.NewQUICTransport(tc)
-	case "tcp":
-		transportImpl = tcp.NewTCPTransport(tc)
-	default:
-		return nil, fmt.Errorf("unsupported transport protocol: %s", transportProtocol)
+		tr, err := transport.New(u, transport.Options{TLSConfig: tc, Key: key})
+		if err != nil {
+			log.Error().Err(err).Msg("failed to create transport")
+			return nil, fmt.Errorf("failed to create transport: %w", err)
+		}
+		endpoints = append(endpoints, proxy.Endpoint{Address: u.Host, Transport: tr})
+
+		hash := certHash
+		if t, ok := tr.(*ssh.SSHTransport); ok {
+			// Agents verify the host key instead of the certificate.
+			hash = ssh.HashHostKey(t.PublicKey())
+		}
+
+		// The settings of the proxy side mean nothing to agents.
+		connURI := url.URL{
+			Scheme:   u.Scheme,
+			Host:     u.Host,
+			Path:     u.Path,
+			RawQuery: url.Values{"ch": {hex.EncodeToString(hash)}}.Encode(),
+		}
+		connURIs = append(connURIs, connURI.String())
+		transports = append(transports, &pb.ProxyTransport{
+			Address:   u.Host,
+			Transport: tr.Name(),
+			CertHash:  hash,
+			Uri:       connURI.String(),
+		})
 	}
 
-	s.proxyServerInstance, err = proxy.NewServer(ctx, transportImpl, proxyAddr)
+	// Agents relayed by other agents always speak the tcp transport.
+	opts := []proxy.ServerOption{proxy.WithRelayTransport(tcp.NewTCPTransport(tc))}
+	for _, ep := range endpoints[1:] {
+		opts = append(opts, proxy.WithListener(ep.Transport, ep.Address))
+	}
+	srv, err := proxy.NewServer(ctx, endpoints[0].Transport, endpoints[0].Address, opts...)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to create proxy server")
-		return nil, fmt.Errorf("failed to create proxy server")
+		return nil, fmt.Errorf("failed to create proxy server: %w", err)
 	}
 
+	listenCtx, cancel := context.WithCancel(s.ctx)
+	s.proxiesMu.Lock()
+	s.proxies[srv.ID] = &proxyListener{server: srv, cancel: cancel, transports: transports}
+	s.proxiesMu.Unlock()
+
 	go func() {
-		ctx, cancel := context.WithCancel(s.ctx)
-		s.proxyCancell = cancel
-		if err := s.proxyServerInstance.Listen(ctx); err != nil {
+		if err := srv.Listen(listenCtx); err != nil {
 			log.Error().Err(err).Msg("Failed to listen proxy")
 		}
 	}()
 
-	certHash, err := cm.GetCertHash()
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get cert hash")
-		return nil, fmt.Errorf("failed to get cert hash")
-	}
-
 	return connect.NewResponse(&pb.ProxyStartResponse{
-		CertHash: certHash,
+		CertHash: transports[0].CertHash,
+		Uri:      strings.Join(connURIs, ","),
+		Id:       srv.ID,
 	}), nil
 }
 
-func (s *ServiceHandler) ProxyStop(ctx context.Context, req *connect.Request[pb.Empty]) (*connect.Response[pb.Empty], error) {
+func (s *ServiceHandler) ProxyStop(ctx context.Context, req *connect.Request[pb.ProxyStopRequest]) (*connect.Response[pb.Empty], error) {
 	log.Info().Any("req", req).Msg("ProxyStop()")
 
-	if s.proxyServerInstance == nil {
-		log.Info().Msg("proxy server instance is nil")
+	s.proxiesMu.Lock()
+	defer s.proxiesMu.Unlock()
+
+	id := req.Msg.Id
+	if id == "" {
+		for id, p := range s.proxies {
+			p.cancel()
+			delete(s.proxies, id)
+		}
 		return connect.NewResponse(&pb.Empty{}), nil
 	}
 
-	s.proxyCancell()
+	p, ok := s.proxies[id]
+	if !ok {
+		log.Error().Msgf("proxy listener with id \"%s\" doesnt exist", id)
+		return nil, fmt.Errorf("proxy listener with id \"%s\" doesnt exist", id)
+	}
+	p.cancel()
+	delete(s.proxies, id)
 
-	s.proxyServerInstance = nil
+	return connect.NewResponse(&pb.Empty{}), nil
+}
+
+func (s *ServiceHandler) HTTPProxyStart(ctx context.Context, req *connect.Request[pb.HTTPProxyStartRequest]) (*connect.Response[pb.HTTPProxyStartResponse], error) {
+	log.Info().Any("req", req).Msg("HTTPProxyStart()")
+
+	if s.httpProxy != nil {
+		log.Info().Msg("HTTP proxy instance already exists")
+		return nil, fmt.Errorf("HTTP proxy already listening on %s", s.httpProxy.Address)
+	}
+
+	srv, err := httpproxy.Listen(s.ctx, req.Msg.Address, s.httpProxyAgents)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to start HTTP proxy")
+		return nil, fmt.Errorf("failed to start HTTP proxy: %w", err)
+	}
+	s.httpProxy = srv
+
+	return connect.NewResponse(&pb.HTTPProxyStartResponse{
+		Address: srv.Address,
+	}), nil
+}
+
+func (s *ServiceHandler) HTTPProxyStop(ctx context.Context, req *connect.Request[pb.Empty]) (*connect.Response[pb.Empty], error) {
+	log.Info().Any("req", req).Msg("HTTPProxyStop()")
+
+	if s.httpProxy == nil {
+		log.Info().Msg("HTTP proxy instance is nil")
+		return connect.NewResponse(&pb.Empty{}), nil
+	}
+
+	if err := s.httpProxy.Close(); err != nil {
+		log.Error().Err(err).Msg("failed to stop HTTP proxy")
+	}
+	s.httpProxy = nil
 
 	return connect.NewResponse(&pb.Empty{}), nil
 }
 
+// httpProxyAgents returns the connected agents.
+func (s *ServiceHandler) httpProxyAgents() []httpproxy.Agent {
+	var agents []httpproxy.Agent
+	for id, a := range s.agentManager.GetAllAgents() {
+		agents = append(agents, httpproxy.Agent{
+			ID:           id,
+			Conn:         a.Conn(),
+			Capabilities: a.Capabilities,
+			Routes:       a.Routes(),
+		})
+	}
+	return agents
+}
+
 func (s *ServiceHandler) AgentList(ctx context.Context, req *connect.Request[pb.Empty]) (*connect.Response[pb.AgentListResponse], error) {
 	log.Info().Any("req", req).Msg("GetAgents()")
 	agentsResponse := s.agentManager.GetAllAgents()
 
 	agents := make(map[string]*pb.Agent, len(agentsResponse))
 	for id, a := range agentsResponse {
+		interfaces := make([]*pb.Interface, 0, len(a.Host.Interfaces))
+		for _, iface := range a.Host.Interfaces {
+			interfaces = append(interfaces, &pb.Interface{
+				Name:      iface.Name,
+				Mac:       iface.MAC,
+				Mtu:       iface.MTU,
+				Addresses: iface.Addrs,
+			})
+		}
+
 		agents[id] = &pb.Agent{
-			Name:   a.Hostname,
-			Routes: a.Routes(),
+			Name:          a.Hostname,
+			Routes:        a.Routes(),
+			ParentId:      a.Parent,
+			Os:            a.Build.OS,
+			Arch:          a.Build.Arch,
+			Version:       a.Build.Version,
+			Pid:           a.Host.PID,
+			StartedAt:     a.Started.Unix(),
+			ConnectedAt:   a.Connected.Unix(),
+			RemoteAddress: a.RemoteAddr,
+			Transport:     a.Transport,
+			Interfaces:    interfaces,
 		}
 	}
 
@@ -132,7 +307,11 @@ func (s *ServiceHandler) TunnelStart(ctx context.Context, req *connect.Request[p
 		return nil, fmt.Errorf("agent with id \"%s\" doesnt exist", id)
 	}
 
-	if err := a.TunnelStart(s.ctx, req.Msg.Routes); err != nil {
+	ct := config.Conntrack{
+		IdleTimeout: time.Duration(req.Msg.UdpIdleTimeout) * time.Second,
+		MaxFlows:    int(req.Msg.UdpMaxFlows),
+	}
+	if err := a.TunnelStart(s.ctx, req.Msg.Routes, req.Msg.FollowRoutes, ct); err != nil {
 		log.Error().Err(err).Msgf("failed to start tunnel for \"%s\"", id)
 		return nil, fmt.Errorf("failed to start tunnel for \"%s\"", id)
 	}
@@ -251,12 +430,24 @@ func (s *ServiceHandler) TunnelList(ctx context.Context, req *connect.Request[pb
 			log.Error().Err(err).Msgf("failed to get address for \"%s\"", id)
 		}
 
+		addr6, err := a.TunnelLoopbackRoute6()
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to get IPv6 address for \"%s\"", id)
+		}
+
+		resolver, err := a.TunnelResolverAddress()
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to get resolver address for \"%s\"", id)
+		}
+
 		tunnels = append(tunnels, &pb.Tunnel{
 			Routes:    routes,
 			Status:    a.TunnelStatus(),
 			AgentId:   id,
 			Interface: a.TunnelName(),
 			Loopback:  addr,
+			Loopback6: addr6,
+			Resolver:  resolver,
 		})
 	}
 
@@ -277,6 +468,208 @@ func (s *ServiceHandler) AgentRemove(ctx context.Context, req *connect.Request[p
 	return connect.NewResponse(&pb.Empty{}), nil
 }
 
+func (s *ServiceHandler) ForwardList(ctx context.Context, req *connect.Request[pb.Empty]) (*connect.Response[pb.ForwardListResponse], error) {
+	log.Info().Any("req", req).Msg("ForwardList()")
+
+	var forwards []*pb.Forward
+	for id, a := range s.agentManager.GetAllAgents() {
+		for _, f := range a.Forwards() {
+			forwards = append(forwards, forwardToPb(id, f))
+		}
+	}
+
+	return connect.NewResponse(&pb.ForwardListResponse{
+		Forwards: forwards,
+	}), nil
+}
+
+func (s *ServiceHandler) ForwardAdd(ctx context.Context, req *connect.Request[pb.ForwardAddRequest]) (*connect.Response[pb.ForwardAddResponse], error) {
+	log.Info().Any("req", req).Msg("ForwardAdd()")
+
+	id := req.Msg.AgentId
+
+	a := s.agentManager.GetAgent(id)
+	if a == nil {
+		log.Error().Msgf("agent with id \"%s\" doesnt exist", id)
+		return nil, fmt.Errorf("agent with id \"%s\" doesnt exist", id)
+	}
+
+	network := req.Msg.Protocol
+	if network == "" {
+		network = "tcp"
+	}
+
+	f, err := a.ForwardAdd(s.ctx, network, req.Msg.Local, req.Msg.Remote)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to add forward for \"%s\"", id)
+		return nil, fmt.Errorf("failed to add forward for \"%s\": %w", id, err)
+	}
+
+	return connect.NewResponse(&pb.ForwardAddResponse{
+		Forward: forwardToPb(id, f),
+	}), nil
+}
+
+func (s *ServiceHandler) ForwardRemove(ctx context.Context, req *connect.Request[pb.ForwardRemoveRequest]) (*connect.Response[pb.Empty], error) {
+	log.Info().Any("req", req).Msg("ForwardRemove()")
+
+	id := req.Msg.Id
+	for agentId, a := range s.agentManager.GetAllAgents() {
+		ok, err := a.ForwardRemove(id)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to remove forward \"%s\" of \"%s\"", id, agentId)
+		}
+		return connect.NewResponse(&pb.Empty{}), nil
+	}
+
+	log.Error().Msgf("forward with id \"%s\" doesnt exist", id)
+	return nil, fmt.Errorf("forward with id \"%s\" doesnt exist", id)
+}
+
+func forwardToPb(agentId string, f *forward.Forward) *pb.Forward {
+	return &pb.Forward{
+		Id:          f.ID,
+		AgentId:     agentId,
+		Protocol:    f.Network,
+		Local:       f.Local,
+		Remote:      f.Remote,
+		Connections: f.Connections(),
+	}
+}
+
+func (s *ServiceHandler) ReverseForwardList(ctx context.Context, req *connect.Request[pb.Empty]) (*connect.Response[pb.ReverseForwardListResponse], error) {
+	log.Info().Any("req", req).Msg("ReverseForwardList()")
+
+	var forwards []*pb.ReverseForward
+	for id, a := range s.agentManager.GetAllAgents() {
+		for _, f := range a.ReverseForwards() {
+			forwards = append(forwards, reverseForwardToPb(id, f))
+		}
+	}
+
+	return connect.NewResponse(&pb.ReverseForwardListResponse{
+		Forwards: forwards,
+	}), nil
+}
+
+func (s *ServiceHandler) ReverseForwardAdd(ctx context.Context, req *connect.Request[pb.ReverseForwardAddRequest]) (*connect.Response[pb.ReverseForwardAddResponse], error) {
+	log.Info().Any("req", req).Msg("ReverseForwardAdd()")
+
+	id := req.Msg.AgentId
+
+	a := s.agentManager.GetAgent(id)
+	if a == nil {
+		log.Error().Msgf("agent with id \"%s\" doesnt exist", id)
+		return nil, fmt.Errorf("agent with id \"%s\" doesnt exist", id)
+	}
+
+	f, err := a.ReverseForwardAdd(s.ctx, req.Msg.AgentListen, req.Msg.Target)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to add reverse forward for \"%s\"", id)
+		return nil, fmt.Errorf("failed to add reverse forward for \"%s\": %w", id, err)
+	}
+
+	return connect.NewResponse(&pb.ReverseForwardAddResponse{
+		Forward: reverseForwardToPb(id, f),
+	}), nil
+}
+
+func (s *ServiceHandler) ReverseForwardRemove(ctx context.Context, req *connect.Request[pb.ReverseForwardRemoveRequest]) (*connect.Response[pb.Empty], error) {
+	log.Info().Any("req", req).Msg("ReverseForwardRemove()")
+
+	id := req.Msg.Id
+	for agentId, a := range s.agentManager.GetAllAgents() {
+		ok, err := a.ReverseForwardRemove(id)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to remove reverse forward \"%s\" of \"%s\"", id, agentId)
+		}
+		return connect.NewResponse(&pb.Empty{}), nil
+	}
+
+	log.Error().Msgf("reverse forward with id \"%s\" doesnt exist", id)
+	return nil, fmt.Errorf("reverse forward with id \"%s\" doesnt exist", id)
+}
+
+func reverseForwardToPb(agentId string, f *forward.ReverseForward) *pb.ReverseForward {
+	return &pb.ReverseForward{
+		Id:          f.ID,
+		AgentId:     agentId,
+		AgentListen: f.AgentListen,
+		Target:      f.Target,
+		Connections: f.Connections(),
+	}
+}
+
+func (s *ServiceHandler) SocksList(ctx context.Context, req *connect.Request[pb.Empty]) (*connect.Response[pb.SocksListResponse], error) {
+	log.Info().Any("req", req).Msg("SocksList()")
+
+	var servers []*pb.Socks
+	for id, a := range s.agentManager.GetAllAgents() {
+		if srv := a.Socks(); srv != nil {
+			servers = append(servers, socksToPb(id, srv))
+		}
+	}
+
+	return connect.NewResponse(&pb.SocksListResponse{
+		Servers: servers,
+	}), nil
+}
+
+func (s *ServiceHandler) SocksStart(ctx context.Context, req *connect.Request[pb.SocksStartRequest]) (*connect.Response[pb.SocksStartResponse], error) {
+	log.Info().Any("req", req).Msg("SocksStart()")
+
+	id := req.Msg.AgentId
+
+	a := s.agentManager.GetAgent(id)
+	if a == nil {
+		log.Error().Msgf("agent with id \"%s\" doesnt exist", id)
+		return nil, fmt.Errorf("agent with id \"%s\" doesnt exist", id)
+	}
+
+	srv, err := a.SocksStart(s.ctx, req.Msg.Address)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to start SOCKS5 server for \"%s\"", id)
+		return nil, fmt.Errorf("failed to start SOCKS5 server for \"%s\": %w", id, err)
+	}
+
+	return connect.NewResponse(&pb.SocksStartResponse{
+		Server: socksToPb(id, srv),
+	}), nil
+}
+
+func (s *ServiceHandler) SocksStop(ctx context.Context, req *connect.Request[pb.SocksStopRequest]) (*connect.Response[pb.Empty], error) {
+	log.Info().Any("req", req).Msg("SocksStop()")
+
+	id := req.Msg.AgentId
+
+	a := s.agentManager.GetAgent(id)
+	if a == nil {
+		log.Error().Msgf("agent with id \"%s\" doesnt exist", id)
+		return nil, fmt.Errorf("agent with id \"%s\" doesnt exist", id)
+	}
+
+	if err := a.SocksStop(); err != nil {
+		log.Error().Err(err).Msgf("failed to stop SOCKS5 server for \"%s\"", id)
+		return nil, fmt.Errorf("failed to stop SOCKS5 server for \"%s\": %w", id, err)
+	}
+
+	return connect.NewResponse(&pb.Empty{}), nil
+}
+
+func socksToPb(agentId string, s *socks5.Server) *pb.Socks {
+	return &pb.Socks{
+		AgentId:     agentId,
+		Address:     s.Address,
+		Connections: s.Connections(),
+	}
+}
+
 type Server struct {
 	serverInstance *http.Server
 	ctx            context.Context
@@ -286,6 +679,7 @@ func NewServer(ctx context.Context, cfg *config.ServiceServer) *Server {
 	mux := http.NewServeMux()
 	mux.Handle(serviceconnect.NewRaidoServiceHandler(&ServiceHandler{
 		agentManager: agent.NewAgentManager(),
+		proxies:      make(map[string]*proxyListener),
 		ctx:          ctx,
 	}))
 
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/fr13n8/raido/proxy"
+	"github.com/fr13n8/raido/proxy/transport"
+	_ "github.com/fr13n8/raido/proxy/transport/all"
+)
+
+// transportOptions are the settings shared by the transports of all
+// endpoints.
+type transportOptions struct {
+	insecureSkipVerify bool
+	// httpProxy, if not nil, is the HTTP proxy of the ws and wss transports.
+	httpProxy *url.URL
+	// identity is the key the ssh transport authenticates with.
+	identity ed25519.PrivateKey
+}
+
+// parseEndpoints builds the proxy endpoints from the comma separated
+// addresses, transports and certificate hashes. An address is either a
+// transport URI, which carries its own transport and hash, or host:port.
+// Transports and hashes are matched to the addresses by position, a single
+// value applies to all.
+func parseEndpoints(addresses, transports, certHashes string, opts transportOptions) ([]proxy.Endpoint, error) {
+	addrs := strings.Split(addresses, ",")
+	tps, err := expandList(transports, len(addrs), "-tp")
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := expandList(certHashes, len(addrs), "-ch")
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]proxy.Endpoint, 0, len(addrs))
+	for i, addr := range addrs {
+		addr = strings.TrimSpace(addr)
+		u, err := endpointURI(addr, tps[i], hashes[i], opts)
+		if err != nil {
+			return nil, fmt.Errorf("proxy address \"%s\": %w", addr, err)
+		}
+		tr, err := transport.New(u, transport.Options{Key: opts.identity})
+		if err != nil {
+			return nil, fmt.Errorf("proxy address \"%s\": %w", addr, err)
+		}
+		endpoints = append(endpoints, proxy.Endpoint{Address: u.Host, Transport: tr})
+	}
+
+	return endpoints, nil
+}
+
+// endpointURI returns the transport URI of addr. The flags only fill in what
+// the URI does not set.
+func endpointURI(addr, tp, certHash string, opts transportOptions) (*url.URL, error) {
+	uri := addr
+	if !strings.Contains(addr, "://") {
+		uri = tp + "://" + addr
+	}
+	u, err := transport.ParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	q := u.Query()
+	if certHash != "" && !q.Has("ch") {
+		q.Set("ch", certHash)
+	}
+	if opts.insecureSkipVerify && !q.Has("insecure") {
+		q.Set("insecure", "true")
+	}
+	if opts.httpProxy != nil && !q.Has("proxy") {
+		q.Set("proxy", opts.httpProxy.String())
+	}
+	u.RawQuery = q.Encode()
+
+	return u, nil
+}
+
+// expandList splits the comma separated value of flag into n elements.
+func expandList(value string, n int, flag string) ([]string, error) {
+	values := strings.Split(value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	switch len(values) {
+	case n:
+		return values, nil
+	case 1:
+		expanded := make([]string, n)
+		for i := range expanded {
+			expanded[i] = values[0]
+		}
+		return expanded, nil
+	default:
+		return nil, fmt.Errorf("%s has %d values for %d proxy addresses", flag, len(values), n)
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
 	"github.com/fr13n8/raido/app"
 	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proto/service"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -60,11 +62,11 @@ var (
 
 					return RowStyle
 				}).
-				Headers("№", "ID", "Hostname", "Routes")
+				Headers("№", "ID", "Hostname", "Parent", "Platform", "Remote", "Uptime", "Routes")
 
 			i := 1
 			for id, a := range agents {
-				t.Row(fmt.Sprintf("%d", i), id, a.Name, strings.Join(a.Routes, "\n"))
+				t.Row(fmt.Sprintf("%d", i), id, a.Name, a.ParentId, platform(a), remote(a), uptime(a), strings.Join(a.Routes, "\n"))
 				i++
 			}
 
@@ -72,6 +74,58 @@ var (
 		},
 	}
 
+	agentInfoCmd = &cobra.Command{
+		Use:   "info",
+		Short: "Show agent details and network interfaces",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			agents, err := c.AgentList(cmd.Context())
+			if err != nil {
+				log.Error().Err(err).Msg("failed to get agents")
+				return
+			}
+			a, ok := agents[agentId]
+			if !ok {
+				log.Error().Msgf("agent %s not found", agentId)
+				return
+			}
+
+			info := table.New().
+				Border(lipgloss.HiddenBorder()).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					return CellStyle
+				}).
+				Row("ID", agentId).
+				Row("Hostname", a.Name).
+				Row("Parent", a.ParentId).
+				Row("Platform", platform(a)).
+				Row("PID", fmt.Sprintf("%d", a.Pid)).
+				Row("Uptime", uptime(a)).
+				Row("Connected", time.Unix(a.ConnectedAt, 0).Format(time.DateTime)).
+				Row("Remote", remote(a))
+			fmt.Println(info)
+
+			t := table.New().
+				Border(lipgloss.NormalBorder()).
+				BorderStyle(BorderStyle).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					if row == 0 {
+						return HeaderStyle
+					}
+
+					return RowStyle
+				}).
+				Headers("Interface", "MAC", "MTU", "Addresses")
+
+			for _, iface := range a.Interfaces {
+				t.Row(iface.Name, iface.Mac, fmt.Sprintf("%d", iface.Mtu), strings.Join(iface.Addresses, "\n"))
+			}
+
+			fmt.Println(t)
+		},
+	}
+
 	agentRemoveCmd = &cobra.Command{
 		Use:   "remove",
 		Short: "Remove agent",
@@ -88,12 +142,31 @@ var (
 	}
 )
 
+// platform returns the OS, architecture and build version of agent a.
+func platform(a *service.Agent) string {
+	return fmt.Sprintf("%s/%s %s", a.Os, a.Arch, a.Version)
+}
+
+// remote returns the address agent a connects from and its transport.
+func remote(a *service.Agent) string {
+	return fmt.Sprintf("%s://%s", a.Transport, a.RemoteAddress)
+}
+
+// uptime returns how long the process of agent a has been running.
+func uptime(a *service.Agent) string {
+	return time.Since(time.Unix(a.StartedAt, 0)).Round(time.Second).String()
+}
+
 func init() {
+	agentInfoCmd.Flags().StringVar(&agentId, "agent-id", "", "Agent ID to show")
+	agentInfoCmd.MarkFlagRequired("agent-id")
+
 	agentRemoveCmd.Flags().StringVar(&agentId, "agent-id", "", "Agent ID to remove")
 	agentRemoveCmd.MarkFlagRequired("agent-id")
 
 	agentCmd.AddCommand(
 		agentListCmd,
+		agentInfoCmd,
 		agentRemoveCmd,
 	)
 }
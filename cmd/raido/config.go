@@ -18,8 +18,27 @@ var (
 	serviceAddr   string
 	agentId       string
 	routes        []string
+	followRoutes  bool
 	proxyDomain   string
 	logFile       string
+
+	udpIdleTimeout time.Duration
+	udpMaxFlows    int
+
+	forwardId       string
+	forwardLocal    string
+	forwardRemote   string
+	forwardProtocol string
+
+	rforwardAgentListen string
+	rforwardTarget      string
+
+	socksAddr     string
+	httpProxyAddr string
+
+	proxyId        string
+	proxyURI       string
+	authorizedKeys string
 )
 
 var (
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/fr13n8/raido/app"
+	"github.com/fr13n8/raido/config"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	forwardCmd = &cobra.Command{
+		Use:   "forward",
+		Short: "Port forwarding commands, no TUN interface required",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			c := app.NewClient(context.TODO(), &config.ServiceDialer{
+				ServiceAddress: serviceAddr,
+			})
+
+			ctx := context.WithValue(cmd.Context(), app.ClientKey{}, c)
+			cmd.SetContext(ctx)
+
+			return nil
+		},
+	}
+
+	forwardListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List port forwards",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			forwards, err := c.ForwardList(cmd.Context())
+			if err != nil {
+				log.Error().Err(err).Msg("failed to get forwards")
+				return
+			}
+
+			t := table.New().
+				Border(lipgloss.NormalBorder()).
+				BorderStyle(BorderStyle).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					if row == 0 {
+						return HeaderStyle
+					}
+
+					return RowStyle
+				}).
+				Headers("№", "ID", "Agent ID", "Protocol", "Local", "Remote", "Connections")
+
+			for id, f := range forwards {
+				t.Row(fmt.Sprintf("%d", id+1), f.Id, f.AgentId, f.Protocol, f.Local, f.Remote, fmt.Sprintf("%d", f.Connections))
+			}
+
+			fmt.Println(t)
+		},
+	}
+
+	forwardAddCmd = &cobra.Command{
+		Use:   "add",
+		Short: "Forward a local address to a remote address through the agent",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			log.Info().Msg("add forward...")
+			f, err := c.ForwardAdd(cmd.Context(), agentId, forwardProtocol, forwardLocal, forwardRemote)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to add forward")
+				return
+			}
+
+			log.Info().Msgf("forward %s added, %s %s -> %s", f.Id, f.Protocol, f.Local, f.Remote)
+		},
+	}
+
+	forwardRemoveCmd = &cobra.Command{
+		Use:   "remove",
+		Short: "Remove port forward",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			log.Info().Msg("remove forward...")
+			if err := c.ForwardRemove(cmd.Context(), forwardId); err != nil {
+				log.Error().Err(err).Msg("failed to remove forward")
+				return
+			}
+
+			log.Info().Msg("forward removed")
+		},
+	}
+)
+
+func init() {
+	forwardAddCmd.Flags().StringVar(&agentId, "agent-id", "", "Agent ID to forward through")
+	forwardAddCmd.MarkFlagRequired("agent-id")
+	forwardAddCmd.Flags().StringVar(&forwardLocal, "local", "", "Local address to listen on (e.g., 127.0.0.1:5432)")
+	forwardAddCmd.MarkFlagRequired("local")
+	forwardAddCmd.Flags().StringVar(&forwardRemote, "remote", "", "Remote address the agent connects to (e.g., 10.2.0.4:5432)")
+	forwardAddCmd.MarkFlagRequired("remote")
+	forwardAddCmd.Flags().StringVar(&forwardProtocol, "protocol", "tcp", "Protocol to forward (tcp, udp)")
+
+	forwardRemoveCmd.Flags().StringVar(&forwardId, "id", "", "Forward ID to remove")
+	forwardRemoveCmd.MarkFlagRequired("id")
+
+	forwardCmd.AddCommand(
+		forwardAddCmd,
+		forwardRemoveCmd,
+		forwardListCmd,
+	)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+
+	"github.com/fr13n8/raido/app"
+	"github.com/fr13n8/raido/config"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	httpProxyCmd = &cobra.Command{
+		Use:   "http-proxy",
+		Short: "HTTP proxy commands, requests are relayed through the agent routing the destination",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			c := app.NewClient(cmd.Context(), &config.ServiceDialer{
+				ServiceAddress: serviceAddr,
+			})
+
+			ctx := context.WithValue(cmd.Context(), app.ClientKey{}, c)
+			cmd.SetContext(ctx)
+
+			return nil
+		},
+	}
+
+	httpProxyStartCmd = &cobra.Command{
+		Use:   "start",
+		Short: "Start HTTP proxy",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			address, err := c.HTTPProxyStart(cmd.Context(), httpProxyAddr)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to start HTTP proxy")
+				return
+			}
+
+			log.Info().Msgf("HTTP proxy listening on %s", address)
+		},
+	}
+
+	httpProxyStopCmd = &cobra.Command{
+		Use:   "stop",
+		Short: "Stop HTTP proxy",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			if err := c.HTTPProxyStop(cmd.Context()); err != nil {
+				log.Error().Err(err).Msg("failed to stop HTTP proxy")
+				return
+			}
+
+			log.Info().Msg("HTTP proxy stopped")
+		},
+	}
+)
+
+func init() {
+	httpProxyStartCmd.Flags().StringVar(&httpProxyAddr, "address", "127.0.0.1:3128", "HTTP proxy listen address")
+
+	httpProxyCmd.AddCommand(
+		httpProxyStartCmd, httpProxyStopCmd,
+	)
+}
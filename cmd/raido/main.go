@@ -23,6 +23,10 @@ func init() {
 		serviceCmd,
 		agentCmd,
 		tunnelCmd,
+		forwardCmd,
+		rforwardCmd,
+		socksCmd,
+		httpProxyCmd,
 		proxyCmd,
 	)
 }
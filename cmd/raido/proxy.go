@@ -2,9 +2,14 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
 	"github.com/fr13n8/raido/app"
 	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/transport"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -31,13 +36,56 @@ var (
 		Run: func(cmd *cobra.Command, args []string) {
 			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
 
-			certHash, err := c.ProxyStart(cmd.Context(), proxyAddr, proxyProtocol)
+			uri := proxyURI
+			if uri == "" {
+				uri = proxyProtocol + "://" + proxyAddr
+			}
+			resp, err := c.ProxyStart(cmd.Context(), uri, authorizedKeys)
 			if err != nil {
 				log.Error().Err(err).Msg("failed to start proxy")
 				return
 			}
 
-			log.Info().Msgf("proxy started with cert hash: %X", certHash)
+			log.Info().Msgf("proxy %s started with cert hash: %X", resp.Id, resp.CertHash)
+			log.Info().Msgf("connect agents with: -pa %s", resp.Uri)
+		},
+	}
+
+	proxyListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List proxy listeners",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			listeners, err := c.ProxyList(cmd.Context())
+			if err != nil {
+				log.Error().Err(err).Msg("failed to get proxy listeners")
+				return
+			}
+
+			t := table.New().
+				Border(lipgloss.NormalBorder()).
+				BorderStyle(BorderStyle).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					if row == 0 {
+						return HeaderStyle
+					}
+
+					return RowStyle
+				}).
+				Headers("№", "ID", "Address", "Transport", "Cert Hash", "Agents")
+
+			for id, l := range listeners {
+				var addresses, transports, hashes []string
+				for _, tr := range l.Transports {
+					addresses = append(addresses, tr.Address)
+					transports = append(transports, tr.Transport)
+					hashes = append(hashes, fmt.Sprintf("%X", tr.CertHash))
+				}
+				t.Row(fmt.Sprintf("%d", id+1), l.Id, strings.Join(addresses, "\n"), strings.Join(transports, "\n"), strings.Join(hashes, "\n"), fmt.Sprintf("%d", l.Agents))
+			}
+
+			fmt.Println(t)
 		},
 	}
 
@@ -47,22 +95,29 @@ var (
 		Run: func(cmd *cobra.Command, args []string) {
 			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
 
-			err := c.ProxyStop(cmd.Context())
+			err := c.ProxyStop(cmd.Context(), proxyId)
 			if err != nil {
 				log.Error().Err(err).Msg("failed to stop proxy")
 				return
 			}
 
-			log.Info().Msg("proxy stopped")
+			if proxyId == "" {
+				log.Info().Msg("all proxy listeners stopped")
+				return
+			}
+			log.Info().Msgf("proxy %s stopped", proxyId)
 		},
 	}
 )
 
 func init() {
 	proxyStartCmd.Flags().StringVar(&proxyAddr, "proxy-addr", "0.0.0.0:8787", "Proxy listen address (e.g., :8787)")
-	proxyStartCmd.Flags().StringVar(&proxyProtocol, "proxy-protocol", "quic", "Proxy type (e.g., quic, tcp)")
+	proxyStartCmd.Flags().StringVar(&proxyProtocol, "proxy-protocol", "quic", "Proxy type ("+strings.Join(transport.Schemes(), ", ")+")")
+	proxyStartCmd.Flags().StringVar(&proxyURI, "uri", "", "Proxy transport URI (e.g., tcp+tls://0.0.0.0:443), replaces --proxy-addr and --proxy-protocol, comma separated to listen on several transports")
+	proxyStartCmd.Flags().StringVar(&authorizedKeys, "authorized-keys", "", "authorized_keys file of the agent keys the ssh proxy accepts, required unless its URI sets insecure=true")
+	proxyStopCmd.Flags().StringVar(&proxyId, "id", "", "Proxy listener ID to stop, all listeners if empty")
 
 	proxyCmd.AddCommand(
-		proxyStartCmd, proxyStopCmd,
+		proxyListCmd, proxyStartCmd, proxyStopCmd,
 	)
 }
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/fr13n8/raido/app"
+	"github.com/fr13n8/raido/config"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rforwardCmd = &cobra.Command{
+		Use:   "rforward",
+		Short: "Reverse port forwarding commands, the agent listens and the proxy connects",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			c := app.NewClient(context.TODO(), &config.ServiceDialer{
+				ServiceAddress: serviceAddr,
+			})
+
+			ctx := context.WithValue(cmd.Context(), app.ClientKey{}, c)
+			cmd.SetContext(ctx)
+
+			return nil
+		},
+	}
+
+	rforwardListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List reverse port forwards",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			forwards, err := c.ReverseForwardList(cmd.Context())
+			if err != nil {
+				log.Error().Err(err).Msg("failed to get reverse forwards")
+				return
+			}
+
+			t := table.New().
+				Border(lipgloss.NormalBorder()).
+				BorderStyle(BorderStyle).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					if row == 0 {
+						return HeaderStyle
+					}
+
+					return RowStyle
+				}).
+				Headers("№", "ID", "Agent ID", "Agent Listen", "Target", "Connections")
+
+			for id, f := range forwards {
+				t.Row(fmt.Sprintf("%d", id+1), f.Id, f.AgentId, f.AgentListen, f.Target, fmt.Sprintf("%d", f.Connections))
+			}
+
+			fmt.Println(t)
+		},
+	}
+
+	rforwardAddCmd = &cobra.Command{
+		Use:   "add",
+		Short: "Listen on the agent and forward connections to a target reachable from the proxy",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			log.Info().Msg("add reverse forward...")
+			f, err := c.ReverseForwardAdd(cmd.Context(), agentId, rforwardAgentListen, rforwardTarget)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to add reverse forward")
+				return
+			}
+
+			log.Info().Msgf("reverse forward %s added, agent %s -> %s", f.Id, f.AgentListen, f.Target)
+		},
+	}
+
+	rforwardRemoveCmd = &cobra.Command{
+		Use:   "remove",
+		Short: "Remove reverse port forward",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			log.Info().Msg("remove reverse forward...")
+			if err := c.ReverseForwardRemove(cmd.Context(), forwardId); err != nil {
+				log.Error().Err(err).Msg("failed to remove reverse forward")
+				return
+			}
+
+			log.Info().Msg("reverse forward removed")
+		},
+	}
+)
+
+func init() {
+	rforwardAddCmd.Flags().StringVar(&agentId, "agent-id", "", "Agent ID to listen on")
+	rforwardAddCmd.MarkFlagRequired("agent-id")
+	rforwardAddCmd.Flags().StringVar(&rforwardAgentListen, "agent-listen", "", "Address the agent listens on (e.g., 0.0.0.0:8080)")
+	rforwardAddCmd.MarkFlagRequired("agent-listen")
+	rforwardAddCmd.Flags().StringVar(&rforwardTarget, "target", "", "Address the proxy connects to (e.g., 127.0.0.1:80)")
+	rforwardAddCmd.MarkFlagRequired("target")
+
+	rforwardRemoveCmd.Flags().StringVar(&forwardId, "id", "", "Reverse forward ID to remove")
+	rforwardRemoveCmd.MarkFlagRequired("id")
+
+	rforwardCmd.AddCommand(
+		rforwardAddCmd,
+		rforwardRemoveCmd,
+		rforwardListCmd,
+	)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/fr13n8/raido/app"
+	"github.com/fr13n8/raido/config"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	socksCmd = &cobra.Command{
+		Use:   "socks",
+		Short: "SOCKS5 server commands, no TUN interface required",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			c := app.NewClient(context.TODO(), &config.ServiceDialer{
+				ServiceAddress: serviceAddr,
+			})
+
+			ctx := context.WithValue(cmd.Context(), app.ClientKey{}, c)
+			cmd.SetContext(ctx)
+
+			return nil
+		},
+	}
+
+	socksListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List SOCKS5 servers",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			servers, err := c.SocksList(cmd.Context())
+			if err != nil {
+				log.Error().Err(err).Msg("failed to get SOCKS5 servers")
+				return
+			}
+
+			t := table.New().
+				Border(lipgloss.NormalBorder()).
+				BorderStyle(BorderStyle).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					if row == 0 {
+						return HeaderStyle
+					}
+
+					return RowStyle
+				}).
+				Headers("№", "Agent ID", "Address", "Connections")
+
+			for id, s := range servers {
+				t.Row(fmt.Sprintf("%d", id+1), s.AgentId, s.Address, fmt.Sprintf("%d", s.Connections))
+			}
+
+			fmt.Println(t)
+		},
+	}
+
+	socksStartCmd = &cobra.Command{
+		Use:   "start",
+		Short: "Start a SOCKS5 server that relays connections through the agent",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			log.Info().Msg("start SOCKS5 server...")
+			s, err := c.SocksStart(cmd.Context(), agentId, socksAddr)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to start SOCKS5 server")
+				return
+			}
+
+			log.Info().Msgf("SOCKS5 server listening on %s", s.Address)
+		},
+	}
+
+	socksStopCmd = &cobra.Command{
+		Use:   "stop",
+		Short: "Stop SOCKS5 server",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
+
+			log.Info().Msg("stop SOCKS5 server...")
+			if err := c.SocksStop(cmd.Context(), agentId); err != nil {
+				log.Error().Err(err).Msg("failed to stop SOCKS5 server")
+				return
+			}
+
+			log.Info().Msg("SOCKS5 server stopped")
+		},
+	}
+)
+
+func init() {
+	socksStartCmd.Flags().StringVar(&agentId, "agent-id", "", "Agent ID to relay through")
+	socksStartCmd.MarkFlagRequired("agent-id")
+	socksStartCmd.Flags().StringVar(&socksAddr, "address", "127.0.0.1:1080", "Address the SOCKS5 server listens on")
+
+	socksStopCmd.Flags().StringVar(&agentId, "agent-id", "", "Agent ID of the SOCKS5 server to stop")
+	socksStopCmd.MarkFlagRequired("agent-id")
+
+	socksCmd.AddCommand(
+		socksStartCmd,
+		socksStopCmd,
+		socksListCmd,
+	)
+}
@@ -51,10 +51,14 @@ var (
 
 					return RowStyle
 				}).
-				Headers("№", "Agent ID", "Interface", "Routes", "Loopback", "Status")
+				Headers("№", "Agent ID", "Interface", "Routes", "Loopback", "Resolver", "Status")
 
 			for id, tunnel := range tunnels {
-				t.Row(fmt.Sprintf("%d", id+1), tunnel.AgentId, tunnel.Interface, strings.Join(tunnel.Routes, "\n"), tunnel.Loopback, tunnel.Status)
+				loopback := tunnel.Loopback
+				if tunnel.Loopback6 != "" {
+					loopback += "\n" + tunnel.Loopback6
+				}
+				t.Row(fmt.Sprintf("%d", id+1), tunnel.AgentId, tunnel.Interface, strings.Join(tunnel.Routes, "\n"), loopback, tunnel.Resolver, tunnel.Status)
 			}
 
 			fmt.Println(t)
@@ -68,7 +72,10 @@ var (
 			c := cmd.Context().Value(app.ClientKey{}).(*app.Client)
 
 			log.Info().Msg("start tunnel...")
-			if err := c.TunnelStart(cmd.Context(), agentId, routes); err != nil {
+			if err := c.TunnelStart(cmd.Context(), agentId, routes, followRoutes, config.Conntrack{
+				IdleTimeout: udpIdleTimeout,
+				MaxFlows:    udpMaxFlows,
+			}); err != nil {
 				log.Error().Err(err).Msg("failed to start tunnel")
 				return
 			}
@@ -162,6 +169,9 @@ func init() {
 	tunnelStartCmd.Flags().StringVar(&agentId, "agent-id", "", "Agent ID for starting tunnel")
 	tunnelStartCmd.MarkFlagRequired("agent-id")
 	tunnelStartCmd.Flags().StringArrayVar(&routes, "routes", nil, "Routes to tunnel (e.g., 10.1.0.2/16,10.2.0.2/32,10.3.0.2/24)\nIf not provided, all routes will be tunneled")
+	tunnelStartCmd.Flags().DurationVar(&udpIdleTimeout, "udp-idle-timeout", config.UDPIdleTimeout, "Close UDP flows without traffic for this long")
+	tunnelStartCmd.Flags().BoolVar(&followRoutes, "follow-routes", false, "Add and remove routes as the agent's networks change")
+	tunnelStartCmd.Flags().IntVar(&udpMaxFlows, "udp-max-flows", config.UDPMaxFlows, "Maximum number of concurrent UDP flows, the least recently used flow is evicted when reached")
 
 	tunnelStopCmd.Flags().StringVar(&agentId, "agent-id", "", "Agent ID for stopping tunnel")
 	tunnelStopCmd.MarkFlagRequired("agent-id")
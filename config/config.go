@@ -6,8 +6,23 @@ import (
 )
 
 var (
-	ShutdownTimeout = 2 * time.Second
-	RaidoPath       = "/etc/raido"
+	ShutdownTimeout   = 2 * time.Second
+	HandshakeTimeout  = 10 * time.Second
+	DialTimeout       = 5 * time.Second
+	ConnectTimeout    = 10 * time.Second
+	EchoTimeout       = 5 * time.Second
+	ResolveTimeout    = 5 * time.Second
+	UDPIdleTimeout    = 60 * time.Second
+	UDPMaxFlows       = 4096
+	AgentGracePeriod  = 2 * time.Minute
+	RouteUpdateDelay  = 2 * time.Second
+	RoutePollInterval = 30 * time.Second
+	SSHKeepAlive      = 15 * time.Second
+	RaidoPath         = "/etc/raido"
+
+	// Version is overridden at build time with
+	// -ldflags "-X github.com/fr13n8/raido/config.Version=..."
+	Version = "dev"
 )
 
 type ProxyServer struct {
@@ -20,6 +35,16 @@ type ServiceServer struct {
 	TLSConfig *tls.Config
 }
 
+// Conntrack holds the limits of a tunnel's UDP flow table. Zero values select
+// UDPIdleTimeout and UDPMaxFlows.
+type Conntrack struct {
+	// IdleTimeout is the time after which a flow without traffic is closed.
+	IdleTimeout time.Duration
+	// MaxFlows is the maximum number of concurrent flows. When it is reached
+	// the least recently used flow is evicted.
+	MaxFlows int
+}
+
 type ServiceDialer struct {
 	ServiceAddress string
 	TLSConfig      *tls.Config
@@ -33,10 +33,18 @@ const (
 // reflection-formatted method names, remove the leading slash and convert the remaining slash to a
 // period.
 const (
+	// RaidoServiceProxyListProcedure is the fully-qualified name of the RaidoService's ProxyList RPC.
+	RaidoServiceProxyListProcedure = "/service.RaidoService/ProxyList"
 	// RaidoServiceProxyStartProcedure is the fully-qualified name of the RaidoService's ProxyStart RPC.
 	RaidoServiceProxyStartProcedure = "/service.RaidoService/ProxyStart"
 	// RaidoServiceProxyStopProcedure is the fully-qualified name of the RaidoService's ProxyStop RPC.
 	RaidoServiceProxyStopProcedure = "/service.RaidoService/ProxyStop"
+	// RaidoServiceHTTPProxyStartProcedure is the fully-qualified name of the RaidoService's
+	// HTTPProxyStart RPC.
+	RaidoServiceHTTPProxyStartProcedure = "/service.RaidoService/HTTPProxyStart"
+	// RaidoServiceHTTPProxyStopProcedure is the fully-qualified name of the RaidoService's
+	// HTTPProxyStop RPC.
+	RaidoServiceHTTPProxyStopProcedure = "/service.RaidoService/HTTPProxyStop"
 	// RaidoServiceAgentListProcedure is the fully-qualified name of the RaidoService's AgentList RPC.
 	RaidoServiceAgentListProcedure = "/service.RaidoService/AgentList"
 	// RaidoServiceAgentRemoveProcedure is the fully-qualified name of the RaidoService's AgentRemove
@@ -61,12 +69,38 @@ const (
 	// RaidoServiceTunnelRemoveRouteProcedure is the fully-qualified name of the RaidoService's
 	// TunnelRemoveRoute RPC.
 	RaidoServiceTunnelRemoveRouteProcedure = "/service.RaidoService/TunnelRemoveRoute"
+	// RaidoServiceForwardListProcedure is the fully-qualified name of the RaidoService's ForwardList
+	// RPC.
+	RaidoServiceForwardListProcedure = "/service.RaidoService/ForwardList"
+	// RaidoServiceForwardAddProcedure is the fully-qualified name of the RaidoService's ForwardAdd RPC.
+	RaidoServiceForwardAddProcedure = "/service.RaidoService/ForwardAdd"
+	// RaidoServiceForwardRemoveProcedure is the fully-qualified name of the RaidoService's
+	// ForwardRemove RPC.
+	RaidoServiceForwardRemoveProcedure = "/service.RaidoService/ForwardRemove"
+	// RaidoServiceReverseForwardListProcedure is the fully-qualified name of the RaidoService's
+	// ReverseForwardList RPC.
+	RaidoServiceReverseForwardListProcedure = "/service.RaidoService/ReverseForwardList"
+	// RaidoServiceReverseForwardAddProcedure is the fully-qualified name of the RaidoService's
+	// ReverseForwardAdd RPC.
+	RaidoServiceReverseForwardAddProcedure = "/service.RaidoService/ReverseForwardAdd"
+	// RaidoServiceReverseForwardRemoveProcedure is the fully-qualified name of the RaidoService's
+	// ReverseForwardRemove RPC.
+	RaidoServiceReverseForwardRemoveProcedure = "/service.RaidoService/ReverseForwardRemove"
+	// RaidoServiceSocksListProcedure is the fully-qualified name of the RaidoService's SocksList RPC.
+	RaidoServiceSocksListProcedure = "/service.RaidoService/SocksList"
+	// RaidoServiceSocksStartProcedure is the fully-qualified name of the RaidoService's SocksStart RPC.
+	RaidoServiceSocksStartProcedure = "/service.RaidoService/SocksStart"
+	// RaidoServiceSocksStopProcedure is the fully-qualified name of the RaidoService's SocksStop RPC.
+	RaidoServiceSocksStopProcedure = "/service.RaidoService/SocksStop"
 )
 
 // RaidoServiceClient is a client for the service.RaidoService service.
 type RaidoServiceClient interface {
+	ProxyList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.ProxyListResponse], error)
 	ProxyStart(context.Context, *connect.Request[service.ProxyStartRequest]) (*connect.Response[service.ProxyStartResponse], error)
-	ProxyStop(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.Empty], error)
+	ProxyStop(context.Context, *connect.Request[service.ProxyStopRequest]) (*connect.Response[service.Empty], error)
+	HTTPProxyStart(context.Context, *connect.Request[service.HTTPProxyStartRequest]) (*connect.Response[service.HTTPProxyStartResponse], error)
+	HTTPProxyStop(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.Empty], error)
 	AgentList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.AgentListResponse], error)
 	AgentRemove(context.Context, *connect.Request[service.AgentRemoveRequest]) (*connect.Response[service.Empty], error)
 	TunnelList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.TunnelListResponse], error)
@@ -76,6 +110,15 @@ type RaidoServiceClient interface {
 	TunnelResume(context.Context, *connect.Request[service.TunnelResumeRequest]) (*connect.Response[service.Empty], error)
 	TunnelAddRoute(context.Context, *connect.Request[service.TunnelAddRouteRequest]) (*connect.Response[service.Empty], error)
 	TunnelRemoveRoute(context.Context, *connect.Request[service.TunnelRemoveRouteRequest]) (*connect.Response[service.Empty], error)
+	ForwardList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.ForwardListResponse], error)
+	ForwardAdd(context.Context, *connect.Request[service.ForwardAddRequest]) (*connect.Response[service.ForwardAddResponse], error)
+	ForwardRemove(context.Context, *connect.Request[service.ForwardRemoveRequest]) (*connect.Response[service.Empty], error)
+	ReverseForwardList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.ReverseForwardListResponse], error)
+	ReverseForwardAdd(context.Context, *connect.Request[service.ReverseForwardAddRequest]) (*connect.Response[service.ReverseForwardAddResponse], error)
+	ReverseForwardRemove(context.Context, *connect.Request[service.ReverseForwardRemoveRequest]) (*connect.Response[service.Empty], error)
+	SocksList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.SocksListResponse], error)
+	SocksStart(context.Context, *connect.Request[service.SocksStartRequest]) (*connect.Response[service.SocksStartResponse], error)
+	SocksStop(context.Context, *connect.Request[service.SocksStopRequest]) (*connect.Response[service.Empty], error)
 }
 
 // NewRaidoServiceClient constructs a client for the service.RaidoService service. By default, it
@@ -89,18 +132,36 @@ func NewRaidoServiceClient(httpClient connect.HTTPClient, baseURL string, opts .
 	baseURL = strings.TrimRight(baseURL, "/")
 	raidoServiceMethods := service.File_service_proto.Services().ByName("RaidoService").Methods()
 	return &raidoServiceClient{
+		proxyList: connect.NewClient[service.Empty, service.ProxyListResponse](
+			httpClient,
+			baseURL+RaidoServiceProxyListProcedure,
+			connect.WithSchema(raidoServiceMethods.ByName("ProxyList")),
+			connect.WithClientOptions(opts...),
+		),
 		proxyStart: connect.NewClient[service.ProxyStartRequest, service.ProxyStartResponse](
 			httpClient,
 			baseURL+RaidoServiceProxyStartProcedure,
 			connect.WithSchema(raidoServiceMethods.ByName("ProxyStart")),
 			connect.WithClientOptions(opts...),
 		),
-		proxyStop: connect.NewClient[service.Empty, service.Empty](
+		proxyStop: connect.NewClient[service.ProxyStopRequest, service.Empty](
 			httpClient,
 			baseURL+RaidoServiceProxyStopProcedure,
 			connect.WithSchema(raidoServiceMethods.ByName("ProxyStop")),
 			connect.WithClientOptions(opts...),
 		),
+		hTTPProxyStart: connect.NewClient[service.HTTPProxyStartRequest, service.HTTPProxyStartResponse](
+			httpClient,
+			baseURL+RaidoServiceHTTPProxyStartProcedure,
+			connect.WithSchema(raidoServiceMethods.ByName("HTTPProxyStart")),
+			connect.WithClientOptions(opts...),
+		),
+		hTTPProxyStop: connect.NewClient[service.Empty, service.Empty](
+			httpClient,
+			baseURL+RaidoServiceHTTPProxyStopProcedure,
+			connect.WithSchema(raidoServiceMethods.ByName("HTTPProxyStop")),
+			connect.WithClientOptions(opts...),
+		),
 		agentList: connect.NewClient[service.Empty, service.AgentListResponse](
 			httpClient,
 			baseURL+RaidoServiceAgentListProcedure,
@@ -155,22 +216,93 @@ func NewRaidoServiceClient(httpClient connect.HTTPClient, baseURL string, opts .
 			connect.WithSchema(raidoServiceMethods.ByName("TunnelRemoveRoute")),
 			connect.WithClientOptions(opts...),
 		),
+		forwardList: connect.NewClient[service.Empty, service.ForwardListResponse](
+			httpClient,
+			baseURL+RaidoServiceForwardListProcedure,
+			connect.WithSchema(raidoServiceMethods.ByName("ForwardList")),
+			connect.WithClientOptions(opts...),
+		),
+		forwardAdd: connect.NewClient[service.ForwardAddRequest, service.ForwardAddResponse](
+			httpClient,
+			baseURL+RaidoServiceForwardAddProcedure,
+			connect.WithSchema(raidoServiceMethods.ByName("ForwardAdd")),
+			connect.WithClientOptions(opts...),
+		),
+		forwardRemove: connect.NewClient[service.ForwardRemoveRequest, service.Empty](
+			httpClient,
+			baseURL+RaidoServiceForwardRemoveProcedure,
+			connect.WithSchema(raidoServiceMethods.ByName("ForwardRemove")),
+			connect.WithClientOptions(opts...),
+		),
+		reverseForwardList: connect.NewClient[service.Empty, service.ReverseForwardListResponse](
+			httpClient,
+			baseURL+RaidoServiceReverseForwardListProcedure,
+			connect.WithSchema(raidoServiceMethods.ByName("ReverseForwardList")),
+			connect.WithClientOptions(opts...),
+		),
+		reverseForwardAdd: connect.NewClient[service.ReverseForwardAddRequest, service.ReverseForwardAddResponse](
+			httpClient,
+			baseURL+RaidoServiceReverseForwardAddProcedure,
+			connect.WithSchema(raidoServiceMethods.ByName("ReverseForwardAdd")),
+			connect.WithClientOptions(opts...),
+		),
+		reverseForwardRemove: connect.NewClient[service.ReverseForwardRemoveRequest, service.Empty](
+			httpClient,
+			baseURL+RaidoServiceReverseForwardRemoveProcedure,
+			connect.WithSchema(raidoServiceMethods.ByName("ReverseForwardRemove")),
+			connect.WithClientOptions(opts...),
+		),
+		socksList: connect.NewClient[service.Empty, service.SocksListResponse](
+			httpClient,
+			baseURL+RaidoServiceSocksListProcedure,
+			connect.WithSchema(raidoServiceMethods.ByName("SocksList")),
+			connect.WithClientOptions(opts...),
+		),
+		socksStart: connect.NewClient[service.SocksStartRequest, service.SocksStartResponse](
+			httpClient,
+			baseURL+RaidoServiceSocksStartProcedure,
+			connect.WithSchema(raidoServiceMethods.ByName("SocksStart")),
+			connect.WithClientOptions(opts...),
+		),
+		socksStop: connect.NewClient[service.SocksStopRequest, service.Empty](
+			httpClient,
+			baseURL+RaidoServiceSocksStopProcedure,
+			connect.WithSchema(raidoServiceMethods.ByName("SocksStop")),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // raidoServiceClient implements RaidoServiceClient.
 type raidoServiceClient struct {
-	proxyStart        *connect.Client[service.ProxyStartRequest, service.ProxyStartResponse]
-	proxyStop         *connect.Client[service.Empty, service.Empty]
-	agentList         *connect.Client[service.Empty, service.AgentListResponse]
-	agentRemove       *connect.Client[service.AgentRemoveRequest, service.Empty]
-	tunnelList        *connect.Client[service.Empty, service.TunnelListResponse]
-	tunnelStart       *connect.Client[service.TunnelStartRequest, service.Empty]
-	tunnelStop        *connect.Client[service.TunnelStopRequest, service.Empty]
-	tunnelPause       *connect.Client[service.TunnelPauseRequest, service.Empty]
-	tunnelResume      *connect.Client[service.TunnelResumeRequest, service.Empty]
-	tunnelAddRoute    *connect.Client[service.TunnelAddRouteRequest, service.Empty]
-	tunnelRemoveRoute *connect.Client[service.TunnelRemoveRouteRequest, service.Empty]
+	proxyList            *connect.Client[service.Empty, service.ProxyListResponse]
+	proxyStart           *connect.Client[service.ProxyStartRequest, service.ProxyStartResponse]
+	proxyStop            *connect.Client[service.ProxyStopRequest, service.Empty]
+	hTTPProxyStart       *connect.Client[service.HTTPProxyStartRequest, service.HTTPProxyStartResponse]
+	hTTPProxyStop        *connect.Client[service.Empty, service.Empty]
+	agentList            *connect.Client[service.Empty, service.AgentListResponse]
+	agentRemove          *connect.Client[service.AgentRemoveRequest, service.Empty]
+	tunnelList           *connect.Client[service.Empty, service.TunnelListResponse]
+	tunnelStart          *connect.Client[service.TunnelStartRequest, service.Empty]
+	tunnelStop           *connect.Client[service.TunnelStopRequest, service.Empty]
+	tunnelPause          *connect.Client[service.TunnelPauseRequest, service.Empty]
+	tunnelResume         *connect.Client[service.TunnelResumeRequest, service.Empty]
+	tunnelAddRoute       *connect.Client[service.TunnelAddRouteRequest, service.Empty]
+	tunnelRemoveRoute    *connect.Client[service.TunnelRemoveRouteRequest, service.Empty]
+	forwardList          *connect.Client[service.Empty, service.ForwardListResponse]
+	forwardAdd           *connect.Client[service.ForwardAddRequest, service.ForwardAddResponse]
+	forwardRemove        *connect.Client[service.ForwardRemoveRequest, service.Empty]
+	reverseForwardList   *connect.Client[service.Empty, service.ReverseForwardListResponse]
+	reverseForwardAdd    *connect.Client[service.ReverseForwardAddRequest, service.ReverseForwardAddResponse]
+	reverseForwardRemove *connect.Client[service.ReverseForwardRemoveRequest, service.Empty]
+	socksList            *connect.Client[service.Empty, service.SocksListResponse]
+	socksStart           *connect.Client[service.SocksStartRequest, service.SocksStartResponse]
+	socksStop            *connect.Client[service.SocksStopRequest, service.Empty]
+}
+
+// ProxyList calls service.RaidoService.ProxyList.
+func (c *raidoServiceClient) ProxyList(ctx context.Context, req *connect.Request[service.Empty]) (*connect.Response[service.ProxyListResponse], error) {
+	return c.proxyList.CallUnary(ctx, req)
 }
 
 // ProxyStart calls service.RaidoService.ProxyStart.
@@ -179,10 +311,20 @@ func (c *raidoServiceClient) ProxyStart(ctx context.Context, req *connect.Reques
 }
 
 // ProxyStop calls service.RaidoService.ProxyStop.
-func (c *raidoServiceClient) ProxyStop(ctx context.Context, req *connect.Request[service.Empty]) (*connect.Response[service.Empty], error) {
+func (c *raidoServiceClient) ProxyStop(ctx context.Context, req *connect.Request[service.ProxyStopRequest]) (*connect.Response[service.Empty], error) {
 	return c.proxyStop.CallUnary(ctx, req)
 }
 
+// HTTPProxyStart calls service.RaidoService.HTTPProxyStart.
+func (c *raidoServiceClient) HTTPProxyStart(ctx context.Context, req *connect.Request[service.HTTPProxyStartRequest]) (*connect.Response[service.HTTPProxyStartResponse], error) {
+	return c.hTTPProxyStart.CallUnary(ctx, req)
+}
+
+// HTTPProxyStop calls service.RaidoService.HTTPProxyStop.
+func (c *raidoServiceClient) HTTPProxyStop(ctx context.Context, req *connect.Request[service.Empty]) (*connect.Response[service.Empty], error) {
+	return c.hTTPProxyStop.CallUnary(ctx, req)
+}
+
 // AgentList calls service.RaidoService.AgentList.
 func (c *raidoServiceClient) AgentList(ctx context.Context, req *connect.Request[service.Empty]) (*connect.Response[service.AgentListResponse], error) {
 	return c.agentList.CallUnary(ctx, req)
@@ -228,10 +370,58 @@ func (c *raidoServiceClient) TunnelRemoveRoute(ctx context.Context, req *connect
 	return c.tunnelRemoveRoute.CallUnary(ctx, req)
 }
 
+// ForwardList calls service.RaidoService.ForwardList.
+func (c *raidoServiceClient) ForwardList(ctx context.Context, req *connect.Request[service.Empty]) (*connect.Response[service.ForwardListResponse], error) {
+	return c.forwardList.CallUnary(ctx, req)
+}
+
+// ForwardAdd calls service.RaidoService.ForwardAdd.
+func (c *raidoServiceClient) ForwardAdd(ctx context.Context, req *connect.Request[service.ForwardAddRequest]) (*connect.Response[service.ForwardAddResponse], error) {
+	return c.forwardAdd.CallUnary(ctx, req)
+}
+
+// ForwardRemove calls service.RaidoService.ForwardRemove.
+func (c *raidoServiceClient) ForwardRemove(ctx context.Context, req *connect.Request[service.ForwardRemoveRequest]) (*connect.Response[service.Empty], error) {
+	return c.forwardRemove.CallUnary(ctx, req)
+}
+
+// ReverseForwardList calls service.RaidoService.ReverseForwardList.
+func (c *raidoServiceClient) ReverseForwardList(ctx context.Context, req *connect.Request[service.Empty]) (*connect.Response[service.ReverseForwardListResponse], error) {
+	return c.reverseForwardList.CallUnary(ctx, req)
+}
+
+// ReverseForwardAdd calls service.RaidoService.ReverseForwardAdd.
+func (c *raidoServiceClient) ReverseForwardAdd(ctx context.Context, req *connect.Request[service.ReverseForwardAddRequest]) (*connect.Response[service.ReverseForwardAddResponse], error) {
+	return c.reverseForwardAdd.CallUnary(ctx, req)
+}
+
+// ReverseForwardRemove calls service.RaidoService.ReverseForwardRemove.
+func (c *raidoServiceClient) ReverseForwardRemove(ctx context.Context, req *connect.Request[service.ReverseForwardRemoveRequest]) (*connect.Response[service.Empty], error) {
+	return c.reverseForwardRemove.CallUnary(ctx, req)
+}
+
+// SocksList calls service.RaidoService.SocksList.
+func (c *raidoServiceClient) SocksList(ctx context.Context, req *connect.Request[service.Empty]) (*connect.Response[service.SocksListResponse], error) {
+	return c.socksList.CallUnary(ctx, req)
+}
+
+// SocksStart calls service.RaidoService.SocksStart.
+func (c *raidoServiceClient) SocksStart(ctx context.Context, req *connect.Request[service.SocksStartRequest]) (*connect.Response[service.SocksStartResponse], error) {
+	return c.socksStart.CallUnary(ctx, req)
+}
+
+// SocksStop calls service.RaidoService.SocksStop.
+func (c *raidoServiceClient) SocksStop(ctx context.Context, req *connect.Request[service.SocksStopRequest]) (*connect.Response[service.Empty], error) {
+	return c.socksStop.CallUnary(ctx, req)
+}
+
 // RaidoServiceHandler is an implementation of the service.RaidoService service.
 type RaidoServiceHandler interface {
+	ProxyList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.ProxyListResponse], error)
 	ProxyStart(context.Context, *connect.Request[service.ProxyStartRequest]) (*connect.Response[service.ProxyStartResponse], error)
-	ProxyStop(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.Empty], error)
+	ProxyStop(context.Context, *connect.Request[service.ProxyStopRequest]) (*connect.Response[service.Empty], error)
+	HTTPProxyStart(context.Context, *connect.Request[service.HTTPProxyStartRequest]) (*connect.Response[service.HTTPProxyStartResponse], error)
+	HTTPProxyStop(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.Empty], error)
 	AgentList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.AgentListResponse], error)
 	AgentRemove(context.Context, *connect.Request[service.AgentRemoveRequest]) (*connect.Response[service.Empty], error)
 	TunnelList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.TunnelListResponse], error)
@@ -241,6 +431,15 @@ type RaidoServiceHandler interface {
 	TunnelResume(context.Context, *connect.Request[service.TunnelResumeRequest]) (*connect.Response[service.Empty], error)
 	TunnelAddRoute(context.Context, *connect.Request[service.TunnelAddRouteRequest]) (*connect.Response[service.Empty], error)
 	TunnelRemoveRoute(context.Context, *connect.Request[service.TunnelRemoveRouteRequest]) (*connect.Response[service.Empty], error)
+	ForwardList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.ForwardListResponse], error)
+	ForwardAdd(context.Context, *connect.Request[service.ForwardAddRequest]) (*connect.Response[service.ForwardAddResponse], error)
+	ForwardRemove(context.Context, *connect.Request[service.ForwardRemoveRequest]) (*connect.Response[service.Empty], error)
+	ReverseForwardList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.ReverseForwardListResponse], error)
+	ReverseForwardAdd(context.Context, *connect.Request[service.ReverseForwardAddRequest]) (*connect.Response[service.ReverseForwardAddResponse], error)
+	ReverseForwardRemove(context.Context, *connect.Request[service.ReverseForwardRemoveRequest]) (*connect.Response[service.Empty], error)
+	SocksList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.SocksListResponse], error)
+	SocksStart(context.Context, *connect.Request[service.SocksStartRequest]) (*connect.Response[service.SocksStartResponse], error)
+	SocksStop(context.Context, *connect.Request[service.SocksStopRequest]) (*connect.Response[service.Empty], error)
 }
 
 // NewRaidoServiceHandler builds an HTTP handler from the service implementation. It returns the
@@ -250,6 +449,12 @@ type RaidoServiceHandler interface {
 // and JSON codecs. They also support gzip compression.
 func NewRaidoServiceHandler(svc RaidoServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
 	raidoServiceMethods := service.File_service_proto.Services().ByName("RaidoService").Methods()
+	raidoServiceProxyListHandler := connect.NewUnaryHandler(
+		RaidoServiceProxyListProcedure,
+		svc.ProxyList,
+		connect.WithSchema(raidoServiceMethods.ByName("ProxyList")),
+		connect.WithHandlerOptions(opts...),
+	)
 	raidoServiceProxyStartHandler := connect.NewUnaryHandler(
 		RaidoServiceProxyStartProcedure,
 		svc.ProxyStart,
@@ -262,6 +467,18 @@ func NewRaidoServiceHandler(svc RaidoServiceHandler, opts ...connect.HandlerOpti
 		connect.WithSchema(raidoServiceMethods.ByName("ProxyStop")),
 		connect.WithHandlerOptions(opts...),
 	)
+	raidoServiceHTTPProxyStartHandler := connect.NewUnaryHandler(
+		RaidoServiceHTTPProxyStartProcedure,
+		svc.HTTPProxyStart,
+		connect.WithSchema(raidoServiceMethods.ByName("HTTPProxyStart")),
+		connect.WithHandlerOptions(opts...),
+	)
+	raidoServiceHTTPProxyStopHandler := connect.NewUnaryHandler(
+		RaidoServiceHTTPProxyStopProcedure,
+		svc.HTTPProxyStop,
+		connect.WithSchema(raidoServiceMethods.ByName("HTTPProxyStop")),
+		connect.WithHandlerOptions(opts...),
+	)
 	raidoServiceAgentListHandler := connect.NewUnaryHandler(
 		RaidoServiceAgentListProcedure,
 		svc.AgentList,
@@ -316,12 +533,72 @@ func NewRaidoServiceHandler(svc RaidoServiceHandler, opts ...connect.HandlerOpti
 		connect.WithSchema(raidoServiceMethods.ByName("TunnelRemoveRoute")),
 		connect.WithHandlerOptions(opts...),
 	)
+	raidoServiceForwardListHandler := connect.NewUnaryHandler(
+		RaidoServiceForwardListProcedure,
+		svc.ForwardList,
+		connect.WithSchema(raidoServiceMethods.ByName("ForwardList")),
+		connect.WithHandlerOptions(opts...),
+	)
+	raidoServiceForwardAddHandler := connect.NewUnaryHandler(
+		RaidoServiceForwardAddProcedure,
+		svc.ForwardAdd,
+		connect.WithSchema(raidoServiceMethods.ByName("ForwardAdd")),
+		connect.WithHandlerOptions(opts...),
+	)
+	raidoServiceForwardRemoveHandler := connect.NewUnaryHandler(
+		RaidoServiceForwardRemoveProcedure,
+		svc.ForwardRemove,
+		connect.WithSchema(raidoServiceMethods.ByName("ForwardRemove")),
+		connect.WithHandlerOptions(opts...),
+	)
+	raidoServiceReverseForwardListHandler := connect.NewUnaryHandler(
+		RaidoServiceReverseForwardListProcedure,
+		svc.ReverseForwardList,
+		connect.WithSchema(raidoServiceMethods.ByName("ReverseForwardList")),
+		connect.WithHandlerOptions(opts...),
+	)
+	raidoServiceReverseForwardAddHandler := connect.NewUnaryHandler(
+		RaidoServiceReverseForwardAddProcedure,
+		svc.ReverseForwardAdd,
+		connect.WithSchema(raidoServiceMethods.ByName("ReverseForwardAdd")),
+		connect.WithHandlerOptions(opts...),
+	)
+	raidoServiceReverseForwardRemoveHandler := connect.NewUnaryHandler(
+		RaidoServiceReverseForwardRemoveProcedure,
+		svc.ReverseForwardRemove,
+		connect.WithSchema(raidoServiceMethods.ByName("ReverseForwardRemove")),
+		connect.WithHandlerOptions(opts...),
+	)
+	raidoServiceSocksListHandler := connect.NewUnaryHandler(
+		RaidoServiceSocksListProcedure,
+		svc.SocksList,
+		connect.WithSchema(raidoServiceMethods.ByName("SocksList")),
+		connect.WithHandlerOptions(opts...),
+	)
+	raidoServiceSocksStartHandler := connect.NewUnaryHandler(
+		RaidoServiceSocksStartProcedure,
+		svc.SocksStart,
+		connect.WithSchema(raidoServiceMethods.ByName("SocksStart")),
+		connect.WithHandlerOptions(opts...),
+	)
+	raidoServiceSocksStopHandler := connect.NewUnaryHandler(
+		RaidoServiceSocksStopProcedure,
+		svc.SocksStop,
+		connect.WithSchema(raidoServiceMethods.ByName("SocksStop")),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/service.RaidoService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
+		case RaidoServiceProxyListProcedure:
+			raidoServiceProxyListHandler.ServeHTTP(w, r)
 		case RaidoServiceProxyStartProcedure:
 			raidoServiceProxyStartHandler.ServeHTTP(w, r)
 		case RaidoServiceProxyStopProcedure:
 			raidoServiceProxyStopHandler.ServeHTTP(w, r)
+		case RaidoServiceHTTPProxyStartProcedure:
+			raidoServiceHTTPProxyStartHandler.ServeHTTP(w, r)
+		case RaidoServiceHTTPProxyStopProcedure:
+			raidoServiceHTTPProxyStopHandler.ServeHTTP(w, r)
 		case RaidoServiceAgentListProcedure:
 			raidoServiceAgentListHandler.ServeHTTP(w, r)
 		case RaidoServiceAgentRemoveProcedure:
@@ -340,6 +617,24 @@ func NewRaidoServiceHandler(svc RaidoServiceHandler, opts ...connect.HandlerOpti
 			raidoServiceTunnelAddRouteHandler.ServeHTTP(w, r)
 		case RaidoServiceTunnelRemoveRouteProcedure:
 			raidoServiceTunnelRemoveRouteHandler.ServeHTTP(w, r)
+		case RaidoServiceForwardListProcedure:
+			raidoServiceForwardListHandler.ServeHTTP(w, r)
+		case RaidoServiceForwardAddProcedure:
+			raidoServiceForwardAddHandler.ServeHTTP(w, r)
+		case RaidoServiceForwardRemoveProcedure:
+			raidoServiceForwardRemoveHandler.ServeHTTP(w, r)
+		case RaidoServiceReverseForwardListProcedure:
+			raidoServiceReverseForwardListHandler.ServeHTTP(w, r)
+		case RaidoServiceReverseForwardAddProcedure:
+			raidoServiceReverseForwardAddHandler.ServeHTTP(w, r)
+		case RaidoServiceReverseForwardRemoveProcedure:
+			raidoServiceReverseForwardRemoveHandler.ServeHTTP(w, r)
+		case RaidoServiceSocksListProcedure:
+			raidoServiceSocksListHandler.ServeHTTP(w, r)
+		case RaidoServiceSocksStartProcedure:
+			raidoServiceSocksStartHandler.ServeHTTP(w, r)
+		case RaidoServiceSocksStopProcedure:
+			raidoServiceSocksStopHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -349,14 +644,26 @@ func NewRaidoServiceHandler(svc RaidoServiceHandler, opts ...connect.HandlerOpti
 // UnimplementedRaidoServiceHandler returns CodeUnimplemented from all methods.
 type UnimplementedRaidoServiceHandler struct{}
 
+func (UnimplementedRaidoServiceHandler) ProxyList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.ProxyListResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.ProxyList is not implemented"))
+}
+
 func (UnimplementedRaidoServiceHandler) ProxyStart(context.Context, *connect.Request[service.ProxyStartRequest]) (*connect.Response[service.ProxyStartResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.ProxyStart is not implemented"))
 }
 
-func (UnimplementedRaidoServiceHandler) ProxyStop(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.Empty], error) {
+func (UnimplementedRaidoServiceHandler) ProxyStop(context.Context, *connect.Request[service.ProxyStopRequest]) (*connect.Response[service.Empty], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.ProxyStop is not implemented"))
 }
 
+func (UnimplementedRaidoServiceHandler) HTTPProxyStart(context.Context, *connect.Request[service.HTTPProxyStartRequest]) (*connect.Response[service.HTTPProxyStartResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.HTTPProxyStart is not implemented"))
+}
+
+func (UnimplementedRaidoServiceHandler) HTTPProxyStop(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.Empty], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.HTTPProxyStop is not implemented"))
+}
+
 func (UnimplementedRaidoServiceHandler) AgentList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.AgentListResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.AgentList is not implemented"))
 }
@@ -392,3 +699,39 @@ func (UnimplementedRaidoServiceHandler) TunnelAddRoute(context.Context, *connect
 func (UnimplementedRaidoServiceHandler) TunnelRemoveRoute(context.Context, *connect.Request[service.TunnelRemoveRouteRequest]) (*connect.Response[service.Empty], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.TunnelRemoveRoute is not implemented"))
 }
+
+func (UnimplementedRaidoServiceHandler) ForwardList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.ForwardListResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.ForwardList is not implemented"))
+}
+
+func (UnimplementedRaidoServiceHandler) ForwardAdd(context.Context, *connect.Request[service.ForwardAddRequest]) (*connect.Response[service.ForwardAddResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.ForwardAdd is not implemented"))
+}
+
+func (UnimplementedRaidoServiceHandler) ForwardRemove(context.Context, *connect.Request[service.ForwardRemoveRequest]) (*connect.Response[service.Empty], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.ForwardRemove is not implemented"))
+}
+
+func (UnimplementedRaidoServiceHandler) ReverseForwardList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.ReverseForwardListResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.ReverseForwardList is not implemented"))
+}
+
+func (UnimplementedRaidoServiceHandler) ReverseForwardAdd(context.Context, *connect.Request[service.ReverseForwardAddRequest]) (*connect.Response[service.ReverseForwardAddResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.ReverseForwardAdd is not implemented"))
+}
+
+func (UnimplementedRaidoServiceHandler) ReverseForwardRemove(context.Context, *connect.Request[service.ReverseForwardRemoveRequest]) (*connect.Response[service.Empty], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.ReverseForwardRemove is not implemented"))
+}
+
+func (UnimplementedRaidoServiceHandler) SocksList(context.Context, *connect.Request[service.Empty]) (*connect.Response[service.SocksListResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.SocksList is not implemented"))
+}
+
+func (UnimplementedRaidoServiceHandler) SocksStart(context.Context, *connect.Request[service.SocksStartRequest]) (*connect.Response[service.SocksStartResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.SocksStart is not implemented"))
+}
+
+func (UnimplementedRaidoServiceHandler) SocksStop(context.Context, *connect.Request[service.SocksStopRequest]) (*connect.Response[service.Empty], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("service.RaidoService.SocksStop is not implemented"))
+}
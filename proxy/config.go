@@ -1,16 +1,46 @@
 package proxy
 
 import (
+	"math"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
+// ReconnectPolicy controls how an agent reconnects to the proxy. The delay
+// between attempts starts at Interval and grows by Factor up to MaxInterval,
+// each delay is randomized by up to Jitter times its length. The attempt
+// counter and the give-up deadline start over once a connection completes
+// the handshake.
+type ReconnectPolicy struct {
+	Interval    time.Duration
+	MaxInterval time.Duration
+	Factor      float64
+	Jitter      float64
+	// MaxAttempts is the number of attempts after which the agent gives up,
+	// zero retries forever.
+	MaxAttempts int
+	// GiveUpAfter is how long the agent tries to reconnect before it gives
+	// up, zero retries forever.
+	GiveUpAfter time.Duration
+}
+
 var (
-	DefaultBackoff = wait.Backoff{
-		Steps:    5,
-		Duration: 100 * time.Millisecond,
-		Factor:   2.0,
-		Jitter:   0.1,
+	DefaultReconnectPolicy = ReconnectPolicy{
+		Interval:    time.Second,
+		MaxInterval: 30 * time.Second,
+		Factor:      2.0,
+		Jitter:      0.2,
 	}
 )
+
+func (p ReconnectPolicy) backoff() wait.Backoff {
+	return wait.Backoff{
+		Duration: p.Interval,
+		Factor:   p.Factor,
+		Jitter:   p.Jitter,
+		// Step keeps returning the capped delay once Steps runs out.
+		Steps: math.MaxInt32,
+		Cap:   p.MaxInterval,
+	}
+}
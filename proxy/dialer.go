@@ -2,172 +2,441 @@ package proxy
 
 import (
 	"context"
+	"crypto/ed25519"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/user"
 	"runtime"
+	"sync/atomic"
 	"time"
 
+	"github.com/fr13n8/raido/config"
 	"github.com/fr13n8/raido/proxy/protocol"
 	"github.com/fr13n8/raido/proxy/relay"
 	"github.com/fr13n8/raido/proxy/transport"
 	"golang.org/x/sync/errgroup"
-	"k8s.io/apimachinery/pkg/util/wait"
 
-	"github.com/quic-go/quic-go"
 	"github.com/rs/zerolog/log"
 )
 
 type Dialer struct {
-	address  string
-	streamCh chan transport.Stream
-	tr       transport.Transport
+	// endpoints are the addresses of the proxy, tried as strategy says.
+	endpoints []Endpoint
+	strategy  EndpointStrategy
+	// next is the index of the endpoint following the one tried last.
+	next int
+	// preferred is the index of the endpoint that connected last.
+	preferred    int
+	capabilities protocol.Capability
+	allowed      []*net.IPNet
+	// udpIdleTimeout closes dialed UDP sockets without traffic.
+	udpIdleTimeout time.Duration
+	// relayAddress is the address downstream agents connect to, the relay
+	// listener is disabled when it is empty.
+	relayAddress string
+	// identity is the key the agent proves its identity to the proxy with.
+	identity ed25519.PrivateKey
+	// reconnect controls how Run reconnects to the proxy.
+	reconnect ReconnectPolicy
 }
 
-func NewDialer(ctx context.Context, tr transport.Transport, address string) *Dialer {
-	return &Dialer{
-		streamCh: make(chan transport.Stream, runtime.NumCPU()),
-		tr:       tr,
-		address:  address,
+// session is a connection to the proxy. Streams are served with the session
+// they were accepted on, they outlive it and must not use a later one.
+type session struct {
+	conn transport.StreamConn
+	// mux carries the datagram flows of the connection, it is nil when the
+	// connection does not support datagrams.
+	mux *transport.DatagramMux
+	// handshaked is set once the proxy completed the handshake.
+	handshaked atomic.Bool
+	// caps holds the capabilities negotiated in the handshake.
+	caps atomic.Uint32
+}
+
+func (s *session) capabilities() protocol.Capability {
+	return protocol.Capability(s.caps.Load())
+}
+
+type DialerOption func(*Dialer)
+
+// WithCapabilities limits the optional protocol features the agent offers to the proxy.
+func WithCapabilities(caps protocol.Capability) DialerOption {
+	return func(d *Dialer) {
+		d.capabilities = caps & protocol.SupportedCapabilities
+	}
+}
+
+// WithAllowedNetworks restricts the destinations the proxy may connect to.
+// Connections to other addresses are denied. An empty list allows everything.
+func WithAllowedNetworks(networks ...*net.IPNet) DialerOption {
+	return func(d *Dialer) {
+		d.allowed = networks
+	}
+}
+
+// WithUDPIdleTimeout sets the time after which a UDP socket dialed for the
+// proxy is closed when no datagrams flow through it. Zero or a negative
+// timeout selects config.UDPIdleTimeout.
+func WithUDPIdleTimeout(timeout time.Duration) DialerOption {
+	return func(d *Dialer) {
+		if timeout <= 0 {
+			timeout = config.UDPIdleTimeout
+		}
+		d.udpIdleTimeout = timeout
+	}
+}
+
+// WithRelayListener makes the agent accept downstream agents on address and
+// relay their connections to the proxy. Downstream agents must use the tcp
+// transport.
+func WithRelayListener(address string) DialerOption {
+	return func(d *Dialer) {
+		d.relayAddress = address
+	}
+}
+
+// WithIdentity sets the key the proxy derives the agent ID from. Without it
+// the agent gets a new ID every time it starts.
+func WithIdentity(key ed25519.PrivateKey) DialerOption {
+	return func(d *Dialer) {
+		d.identity = key
+	}
+}
+
+// WithReconnectPolicy sets how the dialer reconnects to the proxy.
+func WithReconnectPolicy(p ReconnectPolicy) DialerOption {
+	return func(d *Dialer) {
+		d.reconnect = p
+	}
+}
+
+func NewDialer(ctx context.Context, tr transport.Transport, address string, opts ...DialerOption) *Dialer {
+	d := &Dialer{
+		endpoints:      []Endpoint{{Address: address, Transport: tr}},
+		capabilities:   protocol.SupportedCapabilities,
+		udpIdleTimeout: config.UDPIdleTimeout,
+		reconnect:      DefaultReconnectPolicy,
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.identity == nil {
+		// A nil reader makes GenerateKey use crypto/rand, which never fails.
+		_, d.identity, _ = ed25519.GenerateKey(nil)
+	}
+
+	return d
 }
 
-func (d *Dialer) dialAndServer(ctx context.Context) error {
-	log.Info().Msgf("attempting connection to %s", d.address)
-	conn, err := d.tr.Dial(ctx, d.address)
+// errConnectionLost wraps the error that ended an established connection.
+var errConnectionLost = errors.New("connection lost")
+
+// dialAndServer connects to the proxy and serves the connection until it
+// dies or ctx is done. Errors of connections that completed the handshake
+// wrap errConnectionLost.
+func (d *Dialer) dialAndServer(ctx context.Context, ep Endpoint) error {
+	conn, err := ep.Transport.Dial(ctx, ep.Address)
 	if err != nil {
 		return fmt.Errorf("could not dial address: %w", err)
 	}
 
-	log.Info().Msgf("starting dialing to %s", d.address)
+	log.Info().Str("state", "connected").Msgf("connected to %s", ep.Address)
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	var g errgroup.Group
 
+	sess := &session{conn: conn}
+
+	if d.relayAddress != "" && d.capabilities.Has(protocol.CapRelay) {
+		go d.serveRelayListener(ctx, conn)
+	}
+	if dc, ok := conn.(transport.DatagramConn); ok && dc.SupportsDatagrams() {
+		sess.mux = transport.NewDatagramMux(dc)
+		g.Go(func() error {
+			if err := sess.mux.Serve(ctx); err != nil {
+				log.Debug().Err(err).Msg("datagram multiplexer stopped")
+			}
+			return nil
+		})
+	}
+
 	g.Go(func() error {
 		<-ctx.Done()
-		close(d.streamCh)
-
+		// Only an agent shutting down says goodbye, a lost connection is
+		// left for the proxy to notice.
+		if parent.Err() != nil && sess.capabilities().Has(protocol.CapDisconnect) {
+			if err := disconnect(conn, "client closing down"); err != nil {
+				log.Debug().Err(err).Msg("could not announce disconnect")
+			}
+		}
 		return conn.CloseWithError(protocol.ApplicationOK, "client closing down")
 	})
 
+	var connErr error
 	g.Go(func() error {
+		// Stop everything above once the connection is gone.
+		defer cancel()
 		for {
-			select {
-			case <-ctx.Done():
-				log.Info().Msg("context cancelled")
+			stream, err := conn.AcceptStream(ctx)
+			if err != nil {
+				connErr = err
 				return nil
-			default:
-				stream, err := conn.AcceptStream(ctx)
-				if err != nil {
-					var appErr *quic.ApplicationError
-					if errors.As(err, &appErr) ||
-						errors.Is(err, context.Canceled) ||
-						errors.Is(err, context.DeadlineExceeded) {
-						log.Info().Msg("connection closed")
-						break
-					}
-
-					log.Error().Err(err).Msg("failed to accept stream")
-					continue
-				}
-				d.streamCh <- stream
 			}
+			go d.handleStream(ctx, sess, stream)
 		}
 	})
 
-	g.Go(func() error {
-		d.processConnection(ctx)
+	if err := g.Wait(); err != nil {
+		log.Debug().Err(err).Msg("could not close connection")
+	}
+	if sess.handshaked.Load() {
+		return fmt.Errorf("%w: %w", errConnectionLost, connErr)
+	}
+	return fmt.Errorf("connection closed before the handshake: %w", connErr)
+}
 
-		log.Info().Msg("connection processing stopped")
-		return nil
-	})
+// disconnect tells the proxy that conn is about to be closed on purpose and
+// waits until the proxy acknowledged it by closing the stream.
+func disconnect(conn transport.StreamConn, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	stream, err := conn.GetStream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
 
-	return g.Wait()
+	stop := transport.AbortOnDone(ctx, stream)
+	defer stop()
+	if err := protocol.Send(stream, protocol.DisconnectCmd, protocol.Disconnect{Reason: reason}); err != nil {
+		return err
+	}
+	_, err = io.Copy(io.Discard, stream)
+	return err
 }
 
+// Run connects to the proxy and reconnects according to the dialer's
+// reconnect policy whenever the connection cannot be established or dies. It
+// returns nil when ctx is done and an error when the policy gives up.
 func (d *Dialer) Run(ctx context.Context) error {
-	return wait.ExponentialBackoffWithContext(ctx, DefaultBackoff, func(context.Context) (done bool, err error) {
-		if err := d.dialAndServer(ctx); err != nil {
-			log.Error().Err(err).Msg("could not dial and serve")
-			if errors.Is(err, context.Canceled) {
-				return false, nil
-			}
+	p := d.reconnect
+	backoff := p.backoff()
+	attempt := 0
+	since := time.Now()
 
-			return false, nil
+	for {
+		attempt++
+		err := d.connect(ctx, attempt)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, errConnectionLost) {
+			log.Warn().Err(err).Str("state", "disconnected").Msg("connection to proxy lost")
+			// Start over, the last attempt succeeded.
+			attempt = 0
+			backoff = p.backoff()
+			since = time.Now()
+		} else {
+			log.Error().Err(err).Str("state", "disconnected").Int("attempt", attempt).Msg("could not connect to proxy")
 		}
 
-		return true, nil
-	})
-}
+		if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+		if p.GiveUpAfter > 0 && time.Since(since) >= p.GiveUpAfter {
+			return fmt.Errorf("giving up, not connected for %s: %w", time.Since(since).Round(time.Second), err)
+		}
 
-func (d *Dialer) processConnection(ctx context.Context) {
-	for stream := range d.streamCh {
-		go d.handleStream(ctx, stream)
+		delay := backoff.Step()
+		log.Info().Str("state", "waiting").Int("next_attempt", attempt+1).Stringer("delay", delay.Round(time.Millisecond)).Msg("reconnecting")
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
 	}
 }
 
-func (d *Dialer) handleStream(ctx context.Context, stream transport.Stream) {
-	decoder := protocol.NewDecoder[protocol.Data](stream)
-	dec, err := decoder.Decode()
+func (d *Dialer) handleStream(ctx context.Context, sess *session, stream transport.Stream) {
+	dec, err := protocol.ReadData(stream)
 	if err != nil {
 		log.Error().Err(err).Msg("could not decode data")
 		return
 	}
 
 	switch dec.Command {
-	case protocol.GetRoutesReqCmd:
-		d.handleGetRoutesRequest(stream)
+	case protocol.HandshakeCmd:
+		d.handleHandshake(ctx, sess, stream, dec)
 	case protocol.EstablishConnectionCmd:
-		d.handleConnectionRequest(ctx, stream, dec)
+		d.handleConnectionRequest(ctx, sess, stream, dec)
+	case protocol.EstablishDatagramFlowCmd:
+		d.handleDatagramFlowRequest(ctx, sess, stream, dec)
+	case protocol.EchoCmd:
+		d.handleEcho(ctx, sess, stream, dec)
+	case protocol.ResolveCmd:
+		d.handleResolve(ctx, sess, stream, dec)
+	case protocol.ReverseListenCmd:
+		d.handleReverseListen(ctx, sess, stream, dec)
 	default:
-		log.Error().Msg("unknown command")
+		log.Error().Stringer("command", dec.Command).Msg("unknown command")
 	}
 }
 
-func (d *Dialer) handleGetRoutesRequest(stream transport.Stream) {
-	addrs, err := GetNetRoutes()
+func (d *Dialer) handleHandshake(ctx context.Context, sess *session, stream transport.Stream, dec protocol.Data) {
+	resp := protocol.HandshakeResp{
+		Version: protocol.Version,
+		Name:    GetUserAndHostname(),
+		Build:   GetBuildInfo(),
+	}
+
+	var req protocol.HandshakeReq
+	err := req.UnmarshalBinary(dec.Body)
+	if err == nil {
+		resp.Version, err = protocol.NegotiateVersion(req.Version)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("refusing handshake")
+		resp.Error = err.Error()
+		if err := protocol.Send(stream, protocol.HandshakeRespCmd, resp); err != nil {
+			log.Error().Err(err).Msg("could not encode handshake response")
+		}
+		return
+	}
+
+	resp.Capabilities = d.capabilities & req.Capabilities
+	resp.PublicKey = d.identity.Public().(ed25519.PublicKey)
+	resp.Signature = ed25519.Sign(d.identity, protocol.IdentityProof(req.Nonce))
+	resp.Routes, err = GetNetRoutes()
 	if err != nil {
 		log.Error().Err(err).Msg("could not get network routes")
+	}
+	resp.Host = GetHostInfo()
+
+	// Set before answering, the proxy may drop the connection as soon as it
+	// has the response.
+	sess.caps.Store(uint32(resp.Capabilities))
+	sess.handshaked.Store(true)
+	if err := protocol.Send(stream, protocol.HandshakeRespCmd, resp); err != nil {
+		log.Error().Err(err).Msg("could not encode handshake response")
 		return
 	}
 
-	encoder := protocol.NewEncoder[protocol.GetRoutesResp](stream)
-	if err := encoder.Encode(protocol.GetRoutesResp{
-		Name:   GetUserAndHostname(),
-		Routes: addrs,
-	}); err != nil {
-		log.Error().Err(err).Msg("could not encode network routes response")
+	if resp.Capabilities.Has(protocol.CapRouteUpdates) {
+		go watchRoutes(ctx, sess.conn, resp.Routes)
 	}
+	log.Info().
+		Uint16("version", resp.Version).
+		Stringer("capabilities", resp.Capabilities).
+		Msg("handshake completed")
 }
 
-func (d *Dialer) handleConnectionRequest(ctx context.Context, stream transport.Stream, dec protocol.Data) {
-	connRequest, err := protocol.Decode(dec.Body)
-	if err != nil {
+func (d *Dialer) handleConnectionRequest(ctx context.Context, sess *session, stream transport.Stream, dec protocol.Data) {
+	var connRequest protocol.IPAddressWithPortProtocol
+	if err := connRequest.UnmarshalBinary(dec.Body); err != nil {
 		log.Error().Err(err).Msg("could not decode connection request")
 		return
 	}
 
-	network := map[bool]string{true: "tcp", false: "udp"}[connRequest.Protocol == protocol.TransportTCP]
-	version := map[bool]string{true: "4", false: "6"}[connRequest.Network == protocol.Networkv4]
+	targetConn, reason := d.dialTarget(ctx, connRequest)
+	if err := protocol.Send(stream, protocol.ConnectResponseCmd, protocol.ConnectResponse{
+		Established: targetConn != nil,
+		Reason:      reason,
+	}); err != nil {
+		log.Error().Err(err).Msg("could not encode connection response")
+	}
+	if targetConn == nil {
+		return
+	}
 
-	encoder := protocol.NewEncoder[protocol.ConnectResponse](stream)
+	if connRequest.Protocol == protocol.TransportUDP {
+		// Keep datagram boundaries intact on the stream.
+		go relay.Pipe(relay.NewIdleConn(targetConn, d.udpIdleTimeout), relay.NewUDPStream(stream, sess.capabilities()))
+		return
+	}
+	go relay.Pipe(targetConn, stream)
+}
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-	targetConn, err := (&net.Dialer{}).DialContext(ctx, network+version, net.JoinHostPort(connRequest.IP.String(), fmt.Sprintf("%d", connRequest.Port)))
-	if err != nil {
-		log.Error().Err(err).Msg("could not dial target")
-		if err := encoder.Encode(protocol.ConnectResponse{Established: false}); err != nil {
-			log.Error().Err(err).Msg("could not encode connection response")
-		}
+func (d *Dialer) handleDatagramFlowRequest(ctx context.Context, sess *session, stream transport.Stream, dec protocol.Data) {
+	var req protocol.DatagramFlowRequest
+	if err := req.UnmarshalBinary(dec.Body); err != nil {
+		log.Error().Err(err).Msg("could not decode datagram flow request")
 		return
 	}
 
-	if err := encoder.Encode(protocol.ConnectResponse{Established: true}); err != nil {
+	resp := protocol.ConnectResponse{Reason: protocol.ReasonUnknown}
+	var flow *transport.DatagramFlow
+	targetConn, reason := d.dialTarget(ctx, req.Addr)
+	switch {
+	case targetConn == nil:
+		resp.Reason = reason
+	case sess.mux == nil || !sess.capabilities().Has(protocol.CapDatagrams):
+		log.Error().Msg("datagram flow requested but datagrams are not supported")
+		targetConn.Close()
+	default:
+		var err error
+		if flow, err = sess.mux.Accept(req.ID); err != nil {
+			log.Error().Err(err).Msg("could not accept datagram flow")
+			targetConn.Close()
+		} else {
+			resp = protocol.ConnectResponse{Established: true}
+		}
+	}
+
+	if err := protocol.Send(stream, protocol.ConnectResponseCmd, resp); err != nil {
 		log.Error().Err(err).Msg("could not encode connection response")
 	}
-	go relay.Pipe(targetConn, stream)
+	if !resp.Established {
+		return
+	}
+
+	go func() {
+		if err := relay.PipeDatagramFlow(relay.NewIdleConn(targetConn, d.udpIdleTimeout), flow, stream); err != nil {
+			log.Error().Err(err).Msg("could not pipe datagram flow")
+		}
+	}()
+}
+
+// dialTarget connects to the requested address. On failure it returns a nil
+// connection and the reason reported back to the proxy.
+func (d *Dialer) dialTarget(ctx context.Context, addr protocol.IPAddressWithPortProtocol) (net.Conn, protocol.Reason) {
+	network := map[bool]string{true: "tcp", false: "udp"}[addr.Protocol == protocol.TransportTCP]
+	version := map[bool]string{true: "4", false: "6"}[addr.Network == protocol.Networkv4]
+	address := net.JoinHostPort(addr.IP.String(), fmt.Sprintf("%d", addr.Port))
+
+	if !d.isAllowed(addr.IP) {
+		log.Warn().Str("address", address).Msg("connection denied by ACL")
+		return nil, protocol.ReasonDenied
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.DialTimeout)
+	defer cancel()
+	targetConn, err := (&net.Dialer{}).DialContext(ctx, network+version, address)
+	if err != nil {
+		reason := relay.ConnectReason(err)
+		log.Error().Err(err).Stringer("reason", reason).Msg("could not dial target")
+		return nil, reason
+	}
+
+	return targetConn, protocol.ReasonNone
+}
+
+func (d *Dialer) isAllowed(ip net.IP) bool {
+	if len(d.allowed) == 0 {
+		return true
+	}
+
+	for _, n := range d.allowed {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func GetNetRoutes() ([]string, error) {
@@ -191,6 +460,55 @@ func GetNetRoutes() ([]string, error) {
 	return addrs, nil
 }
 
+// startTime is when the agent process started, reported as its uptime.
+var startTime = time.Now()
+
+// GetHostInfo describes this process and the network interfaces of the host,
+// loopback interfaces excluded.
+func GetHostInfo() protocol.HostInfo {
+	info := protocol.HostInfo{
+		PID:    uint32(os.Getpid()),
+		Uptime: uint32(time.Since(startTime).Seconds()),
+	}
+
+	netifaces, err := net.Interfaces()
+	if err != nil {
+		log.Error().Err(err).Msg("could not get network interfaces")
+		return info
+	}
+
+	for _, iface := range netifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		i := protocol.Interface{
+			Name: iface.Name,
+			MAC:  iface.HardwareAddr.String(),
+			MTU:  uint32(iface.MTU),
+		}
+		addresses, err := iface.Addrs()
+		if err != nil {
+			log.Error().Err(err).Msgf("could not get addresses of %s", iface.Name)
+		}
+		for _, addr := range addresses {
+			i.Addrs = append(i.Addrs, addr.String())
+		}
+		info.Interfaces = append(info.Interfaces, i)
+	}
+
+	return info
+}
+
+func GetBuildInfo() protocol.BuildInfo {
+	return protocol.BuildInfo{
+		Version:   config.Version,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+}
+
 func GetUserAndHostname() string {
 	hostname, _ := os.Hostname()
 	userinfo, err := user.Current()
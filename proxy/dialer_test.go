@@ -0,0 +1,304 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fr13n8/raido/agent"
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/rs/zerolog"
+)
+
+// failingTransport refuses every dial.
+type failingTransport struct {
+	transport.Transport
+	dials int
+}
+
+func (t *failingTransport) Name() string {
+	return "failing"
+}
+
+func (t *failingTransport) Dial(ctx context.Context, addr string) (transport.StreamConn, error) {
+	t.dials++
+	return nil, errors.New("connection refused")
+}
+
+func TestRunGivesUp(t *testing.T) {
+	policy := ReconnectPolicy{Interval: time.Millisecond, MaxInterval: 2 * time.Millisecond, Factor: 2}
+
+	tests := []struct {
+		name   string
+		policy func(p ReconnectPolicy) ReconnectPolicy
+		check  func(t *testing.T, dials int)
+	}{
+		{
+			name: "MaxAttempts",
+			policy: func(p ReconnectPolicy) ReconnectPolicy {
+				p.MaxAttempts = 3
+				return p
+			},
+			check: func(t *testing.T, dials int) {
+				if dials != 3 {
+					t.Errorf("got %d dials, want 3", dials)
+				}
+			},
+		},
+		{
+			name: "GiveUpAfter",
+			policy: func(p ReconnectPolicy) ReconnectPolicy {
+				p.GiveUpAfter = 50 * time.Millisecond
+				return p
+			},
+			check: func(t *testing.T, dials int) {
+				if dials < 2 {
+					t.Errorf("got %d dials, want the dialer to retry until the deadline", dials)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &failingTransport{}
+			d := NewDialer(context.Background(), tr, "127.0.0.1:1", WithReconnectPolicy(tt.policy(policy)))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := d.Run(ctx); err == nil || ctx.Err() != nil {
+				t.Fatalf("Run returned %v, want it to give up", err)
+			}
+			tt.check(t, tr.dials)
+		})
+	}
+}
+
+func TestRunRetriesForever(t *testing.T) {
+	tr := &failingTransport{}
+	d := NewDialer(context.Background(), tr, "127.0.0.1:1",
+		WithReconnectPolicy(ReconnectPolicy{Interval: time.Millisecond, MaxInterval: time.Millisecond}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := d.Run(ctx); err != nil {
+		t.Errorf("Run returned %v, want nil once the context is done", err)
+	}
+	if tr.dials < 5 {
+		t.Errorf("got %d dials, want the dialer to keep retrying", tr.dials)
+	}
+}
+
+// recordingTransport records the dialed addresses. Only the address up is
+// reachable, its connections complete the handshake and are lost right away.
+type recordingTransport struct {
+	transport.Transport
+	up    string
+	dials *[]string
+}
+
+func (t recordingTransport) Name() string {
+	return "recording"
+}
+
+func (t recordingTransport) Dial(ctx context.Context, addr string) (transport.StreamConn, error) {
+	*t.dials = append(*t.dials, addr)
+	if addr != t.up {
+		return nil, errors.New("connection refused")
+	}
+
+	conn := lostConn{streams: make(chan transport.Stream, 1)}
+	proxySide, agentSide := net.Pipe()
+	conn.streams <- agentSide
+	go func() {
+		defer close(conn.streams)
+		protocol.Send(proxySide, protocol.HandshakeCmd, protocol.HandshakeReq{Version: protocol.Version})
+		var resp protocol.HandshakeResp
+		protocol.Receive(proxySide, protocol.HandshakeRespCmd, &resp)
+	}()
+	return conn, nil
+}
+
+type lostConn struct {
+	transport.StreamConn
+	streams chan transport.Stream
+}
+
+func (c lostConn) AcceptStream(ctx context.Context) (transport.Stream, error) {
+	if s, ok := <-c.streams; ok {
+		return s, nil
+	}
+	return nil, io.EOF
+}
+
+func (c lostConn) CloseWithError(code uint64, reason string) error {
+	return nil
+}
+
+func TestConnectStrategy(t *testing.T) {
+	tests := []struct {
+		strategy EndpointStrategy
+		want     string
+	}{
+		{StrategyOrder, "a b a b"},
+		{StrategyRoundRobin, "a b c a b"},
+		{StrategySticky, "a b b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy.String(), func(t *testing.T) {
+			var dials []string
+			tr := recordingTransport{up: "b", dials: &dials}
+			d := NewDialer(context.Background(), tr, "a", WithEndpointStrategy(tt.strategy),
+				WithFallbackEndpoints(Endpoint{Address: "b", Transport: tr}, Endpoint{Address: "c", Transport: tr}))
+
+			for attempt := range 2 {
+				if err := d.connect(context.Background(), attempt+1); !errors.Is(err, errConnectionLost) {
+					t.Fatalf("got %v, want the connection to b to be lost", err)
+				}
+			}
+			if got := strings.Join(dials, " "); got != tt.want {
+				t.Errorf("dialed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// flakyTransport hands out connections that complete the handshake, open a
+// reverse forward and are lost before the agent answers it.
+type flakyTransport struct {
+	transport.Transport
+	dials *int
+}
+
+func (t flakyTransport) Name() string {
+	return "flaky"
+}
+
+func (t flakyTransport) Dial(ctx context.Context, addr string) (transport.StreamConn, error) {
+	*t.dials++
+	conn := lostConn{streams: make(chan transport.Stream)}
+	go func() {
+		defer close(conn.streams)
+
+		proxySide, agentSide := net.Pipe()
+		conn.streams <- agentSide
+		protocol.Send(proxySide, protocol.HandshakeCmd, protocol.HandshakeReq{Version: protocol.Version})
+		var resp protocol.HandshakeResp
+		protocol.Receive(proxySide, protocol.HandshakeRespCmd, &resp)
+
+		proxySide, agentSide = net.Pipe()
+		conn.streams <- agentSide
+		protocol.Send(proxySide, protocol.ReverseListenCmd, protocol.ReverseListenRequest{ID: 1, Address: "127.0.0.1:0"})
+		proxySide.Close()
+	}()
+	return conn, nil
+}
+
+// TestReconnect lets streams of lost connections outlive them, run it with
+// -race.
+func TestReconnect(t *testing.T) {
+	// Logging synchronizes the goroutines and hides races.
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+
+	var dials int
+	d := NewDialer(context.Background(), flakyTransport{dials: &dials}, "proxy",
+		WithReconnectPolicy(ReconnectPolicy{Interval: time.Millisecond, MaxInterval: time.Millisecond}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := d.Run(ctx); err != nil {
+		t.Errorf("Run returned %v, want nil once the context is done", err)
+	}
+	if dials < 2 {
+		t.Errorf("got %d dials, want the dialer to reconnect", dials)
+	}
+}
+
+func TestUDPIdleTimeoutDefault(t *testing.T) {
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		d := NewDialer(context.Background(), nil, "", WithUDPIdleTimeout(timeout))
+		if d.udpIdleTimeout != config.UDPIdleTimeout {
+			t.Errorf("WithUDPIdleTimeout(%s) set %s, want the default %s", timeout, d.udpIdleTimeout, config.UDPIdleTimeout)
+		}
+	}
+}
+
+// disconnectingConn hands the streams opened by the agent to the server s as
+// if they were opened by the agent a.
+type disconnectingConn struct {
+	transport.StreamConn
+	s      *Server
+	a      **agent.Agent
+	closed chan struct{}
+}
+
+func (c disconnectingConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	agentSide, proxySide := net.Pipe()
+	go c.s.handleAgentStream(ctx, *c.a, proxySide)
+	return agentSide, nil
+}
+
+func (c disconnectingConn) CloseWithError(code uint64, reason string) error {
+	close(c.closed)
+	return nil
+}
+
+func TestDisconnect(t *testing.T) {
+	s := &Server{agentManager: agent.NewAgentManager()}
+	var a *agent.Agent
+	conn := disconnectingConn{s: s, a: &a, closed: make(chan struct{})}
+	a = agent.New("agent", "user@host", conn, nil)
+	s.agentManager.AddAgent(a)
+
+	if err := disconnect(conn, "client closing down"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-conn.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection of the disconnected agent not closed")
+	}
+	if s.agentManager.GetAgent(a.ID) != nil {
+		t.Error("agent still registered after disconnect")
+	}
+}
+
+// datagramConn supports datagrams but never carries any.
+type datagramConn struct {
+	transport.DatagramConn
+}
+
+func (datagramConn) SupportsDatagrams() bool {
+	return true
+}
+
+func TestDatagramFlowNegotiated(t *testing.T) {
+	d := NewDialer(context.Background(), nil, "")
+	addr := protocol.IPAddressWithPortProtocol{IP: net.IPv4(127, 0, 0, 1), Port: 9, Protocol: protocol.TransportUDP}
+	body, _ := protocol.DatagramFlowRequest{ID: 1, Addr: addr}.MarshalBinary()
+
+	for _, caps := range []protocol.Capability{protocol.SupportedCapabilities, protocol.SupportedCapabilities &^ protocol.CapDatagrams} {
+		sess := &session{mux: transport.NewDatagramMux(datagramConn{})}
+		sess.caps.Store(uint32(caps))
+
+		proxySide, agentSide := net.Pipe()
+		go d.handleDatagramFlowRequest(context.Background(), sess, agentSide, protocol.Data{Command: protocol.EstablishDatagramFlowCmd, Body: body})
+
+		var resp protocol.ConnectResponse
+		if err := protocol.Receive(proxySide, protocol.ConnectResponseCmd, &resp); err != nil {
+			t.Fatal(err)
+		}
+		if want := caps.Has(protocol.CapDatagrams); resp.Established != want {
+			t.Errorf("capabilities %s: established = %t, want %t", caps, resp.Established, want)
+		}
+		proxySide.Close()
+	}
+}
@@ -0,0 +1,201 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/relay"
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// Protocol numbers expected by icmp.ParseMessage.
+const (
+	protocolICMP     = 1
+	protocolIPv6ICMP = 58
+)
+
+func (d *Dialer) handleEcho(ctx context.Context, sess *session, stream transport.Stream, dec protocol.Data) {
+	var req protocol.EchoRequest
+	if err := req.UnmarshalBinary(dec.Body); err != nil {
+		log.Error().Err(err).Msg("could not decode echo request")
+		return
+	}
+
+	var resp protocol.EchoReply
+	switch {
+	case !sess.capabilities().Has(protocol.CapICMP), !d.isAllowed(req.IP):
+		log.Warn().Stringer("address", req.IP).Msg("echo request denied")
+		resp.Reason = protocol.ReasonDenied
+	default:
+		ctx, cancel := context.WithTimeout(ctx, config.EchoTimeout)
+		defer cancel()
+
+		data, err := ping(ctx, req)
+		if err != nil {
+			resp.Reason = relay.ConnectReason(err)
+			log.Debug().Err(err).Stringer("reason", resp.Reason).Msgf("no echo reply from %s", req.IP)
+		}
+		resp.Data = data
+	}
+
+	if err := protocol.Send(stream, protocol.EchoReplyCmd, resp); err != nil {
+		log.Error().Err(err).Msg("could not encode echo reply")
+	}
+}
+
+// ping sends a single ICMP echo request and returns the payload of the
+// matching reply. It prefers unprivileged ping sockets and falls back to raw
+// sockets, which require elevated privileges.
+func ping(ctx context.Context, req protocol.EchoRequest) ([]byte, error) {
+	v4 := req.IP.To4() != nil
+	conn, privileged, err := listenICMP(v4)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	proto, reqType, replyType := protocolICMP, icmp.Type(ipv4.ICMPTypeEcho), icmp.Type(ipv4.ICMPTypeEchoReply)
+	if !v4 {
+		proto, reqType, replyType = protocolIPv6ICMP, ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	msg := icmp.Message{
+		Type: reqType,
+		Body: &icmp.Echo{ID: int(req.ID), Seq: int(req.Seq), Data: req.Data},
+	}
+	b, err := msg.Marshal(nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal echo request: %w", err)
+	}
+
+	var dst net.Addr = &net.UDPAddr{IP: req.IP}
+	if privileged {
+		dst = &net.IPAddr{IP: req.IP}
+	}
+	if _, err := conn.WriteTo(b, dst); err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := make([]byte, 1<<16)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		reply, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			continue
+		}
+
+		switch body := reply.Body.(type) {
+		case *icmp.Echo:
+			// Ping sockets rewrite the identifier, the kernel already
+			// filters replies by it.
+			if reply.Type != replyType || body.Seq != int(req.Seq) || !peerIP(peer).Equal(req.IP) {
+				continue
+			}
+			if privileged && body.ID != int(req.ID) {
+				continue
+			}
+			return body.Data, nil
+		case *icmp.DstUnreach:
+			// Raw sockets see every error, only consider the ones quoting
+			// our request.
+			if !quotesEcho(body.Data, v4, req) {
+				continue
+			}
+			return nil, unreachableError(reply, v4)
+		}
+	}
+}
+
+func listenICMP(v4 bool) (*icmp.PacketConn, bool, error) {
+	network, rawNetwork, address := "udp4", "ip4:icmp", "0.0.0.0"
+	if !v4 {
+		network, rawNetwork, address = "udp6", "ip6:ipv6-icmp", "::"
+	}
+
+	conn, err := icmp.ListenPacket(network, address)
+	if err == nil {
+		return conn, false, nil
+	}
+
+	raw, rawErr := icmp.ListenPacket(rawNetwork, address)
+	if rawErr != nil {
+		return nil, false, fmt.Errorf("could not open ICMP socket: %w (unprivileged: %s)", rawErr, err)
+	}
+
+	return raw, true, nil
+}
+
+func peerIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.IPAddr:
+		return a.IP
+	default:
+		return nil
+	}
+}
+
+// quotesEcho reports whether the packet quoted in an ICMP error is the echo
+// request described by req.
+func quotesEcho(quoted []byte, v4 bool, req protocol.EchoRequest) bool {
+	var dst net.IP
+	var payload []byte
+	if v4 {
+		h, err := ipv4.ParseHeader(quoted)
+		if err != nil || h.Protocol != protocolICMP || len(quoted) < h.Len {
+			return false
+		}
+		dst, payload = h.Dst, quoted[h.Len:]
+	} else {
+		h, err := ipv6.ParseHeader(quoted)
+		if err != nil || h.NextHeader != protocolIPv6ICMP || len(quoted) < ipv6.HeaderLen {
+			return false
+		}
+		dst, payload = h.Dst, quoted[ipv6.HeaderLen:]
+	}
+
+	// type (1) | code (1) | checksum (2) | id (2) | seq (2)
+	if len(payload) < 8 || !dst.Equal(req.IP) {
+		return false
+	}
+	return uint16(payload[6])<<8|uint16(payload[7]) == req.Seq
+}
+
+// unreachableError converts a destination unreachable message into the errno
+// the kernel would have returned for a connection attempt.
+func unreachableError(m *icmp.Message, v4 bool) error {
+	if v4 {
+		switch m.Code {
+		case 0, 6, 11: // net unreachable, net unknown, net unreachable for TOS
+			return syscall.ENETUNREACH
+		default:
+			return syscall.EHOSTUNREACH
+		}
+	}
+
+	switch m.Code {
+	case 0: // no route to destination
+		return syscall.ENETUNREACH
+	default:
+		return syscall.EHOSTUNREACH
+	}
+}
@@ -0,0 +1,100 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// quotedEcho returns an IP packet carrying an ICMP echo request from src to
+// dst, as quoted in an ICMP error.
+func quotedEcho(t *testing.T, src, dst net.IP, seq int) []byte {
+	t.Helper()
+	v4 := dst.To4() != nil
+	var typ icmp.Type = ipv4.ICMPTypeEcho
+	if !v4 {
+		typ = ipv6.ICMPTypeEchoRequest
+	}
+	msg, err := (&icmp.Message{Type: typ, Body: &icmp.Echo{ID: 1, Seq: seq, Data: []byte("ping")}}).Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v4 {
+		h := ipv4.Header{Version: ipv4.Version, Len: ipv4.HeaderLen, TotalLen: ipv4.HeaderLen + len(msg), TTL: 64, Protocol: protocolICMP, Src: src, Dst: dst}
+		b, err := h.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return append(b, msg...)
+	}
+
+	// version 6 | traffic class | flow label, payload length, next header,
+	// hop limit, source, destination
+	b := make([]byte, ipv6.HeaderLen, ipv6.HeaderLen+len(msg))
+	b[0] = 6 << 4
+	b[4], b[5] = byte(len(msg)>>8), byte(len(msg))
+	b[6], b[7] = protocolIPv6ICMP, 64
+	copy(b[8:24], src.To16())
+	copy(b[24:40], dst.To16())
+	return append(b, msg...)
+}
+
+func TestQuotesEcho(t *testing.T) {
+	src4, dst4 := net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")
+	src6, dst6 := net.ParseIP("fd00::1"), net.ParseIP("fd00::2")
+
+	tests := []struct {
+		name   string
+		quoted []byte
+		v4     bool
+		req    protocol.EchoRequest
+		want   bool
+	}{
+		{"IPv4 request", quotedEcho(t, src4, dst4, 7), true, protocol.EchoRequest{IP: dst4, Seq: 7}, true},
+		{"IPv4 other sequence", quotedEcho(t, src4, dst4, 8), true, protocol.EchoRequest{IP: dst4, Seq: 7}, false},
+		{"IPv4 other destination", quotedEcho(t, src4, src4, 7), true, protocol.EchoRequest{IP: dst4, Seq: 7}, false},
+		{"IPv4 truncated", quotedEcho(t, src4, dst4, 7)[:ipv4.HeaderLen+6], true, protocol.EchoRequest{IP: dst4, Seq: 7}, false},
+		{"IPv6 request", quotedEcho(t, src6, dst6, 7), false, protocol.EchoRequest{IP: dst6, Seq: 7}, true},
+		{"IPv6 other sequence", quotedEcho(t, src6, dst6, 8), false, protocol.EchoRequest{IP: dst6, Seq: 7}, false},
+		{"IPv6 other destination", quotedEcho(t, src6, src6, 7), false, protocol.EchoRequest{IP: dst6, Seq: 7}, false},
+		{"IPv6 truncated", quotedEcho(t, src6, dst6, 7)[:ipv6.HeaderLen+6], false, protocol.EchoRequest{IP: dst6, Seq: 7}, false},
+		{"garbage", []byte{0x45, 0}, true, protocol.EchoRequest{IP: dst4, Seq: 7}, false},
+	}
+	for _, tt := range tests {
+		if got := quotesEcho(tt.quoted, tt.v4, tt.req); got != tt.want {
+			t.Errorf("%s: quotesEcho = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+
+	// A quoted UDP datagram is never an echo request.
+	udp := quotedEcho(t, src4, dst4, 7)
+	udp[9] = 17
+	if quotesEcho(udp, true, protocol.EchoRequest{IP: dst4, Seq: 7}) {
+		t.Error("quotesEcho matched a UDP datagram")
+	}
+}
+
+func TestEchoNegotiated(t *testing.T) {
+	d := NewDialer(context.Background(), nil, "")
+	sess := &session{}
+	sess.caps.Store(uint32(protocol.SupportedCapabilities &^ protocol.CapICMP))
+	body, _ := protocol.EchoRequest{IP: net.ParseIP("127.0.0.1").To4(), ID: 1, Seq: 1}.MarshalBinary()
+
+	proxySide, agentSide := net.Pipe()
+	defer proxySide.Close()
+	go d.handleEcho(context.Background(), sess, agentSide, protocol.Data{Command: protocol.EchoCmd, Body: body})
+
+	var resp protocol.EchoReply
+	if err := protocol.Receive(proxySide, protocol.EchoReplyCmd, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Reason != protocol.ReasonDenied {
+		t.Errorf("got reason %s, want %s without CapICMP", resp.Reason, protocol.ReasonDenied)
+	}
+}
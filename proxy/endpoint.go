@@ -0,0 +1,109 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/rs/zerolog/log"
+)
+
+// Endpoint is an address of the proxy and the transport used to reach it.
+type Endpoint struct {
+	Address   string
+	Transport transport.Transport
+}
+
+// EndpointStrategy decides which endpoint the agent connects to first.
+type EndpointStrategy int
+
+const (
+	// StrategyOrder prefers the endpoints in the order they were given: every
+	// attempt starts with the first one and moves on to the next when it
+	// cannot be reached.
+	StrategyOrder EndpointStrategy = iota
+	// StrategyRoundRobin starts every attempt with the endpoint following the
+	// one used last, so a lost connection fails over to the next endpoint.
+	StrategyRoundRobin
+	// StrategySticky starts every attempt with the endpoint that connected
+	// last and moves on to the ones following it. It suits endpoints that are
+	// transports of the same proxy, such as QUIC, then TLS over TCP, then a
+	// WebSocket: once one gets through, reconnects do not wait for the blocked
+	// ones again.
+	StrategySticky
+)
+
+func (s EndpointStrategy) String() string {
+	switch s {
+	case StrategyOrder:
+		return "order"
+	case StrategyRoundRobin:
+		return "round-robin"
+	case StrategySticky:
+		return "sticky"
+	default:
+		return fmt.Sprintf("EndpointStrategy(%d)", int(s))
+	}
+}
+
+// ParseEndpointStrategy parses a strategy name as produced by
+// EndpointStrategy.String.
+func ParseEndpointStrategy(s string) (EndpointStrategy, error) {
+	for _, strategy := range []EndpointStrategy{StrategyOrder, StrategyRoundRobin, StrategySticky} {
+		if strategy.String() == s {
+			return strategy, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown endpoint strategy \"%s\"", s)
+}
+
+// WithFallbackEndpoints adds endpoints the agent fails over to when the
+// proxy cannot be reached on the address given to NewDialer.
+func WithFallbackEndpoints(endpoints ...Endpoint) DialerOption {
+	return func(d *Dialer) {
+		d.endpoints = append(d.endpoints, endpoints...)
+	}
+}
+
+// WithEndpointStrategy sets the order in which the endpoints are tried.
+func WithEndpointStrategy(s EndpointStrategy) DialerOption {
+	return func(d *Dialer) {
+		d.strategy = s
+	}
+}
+
+// connect makes one attempt to connect to the proxy, trying every endpoint
+// once, and serves the first connection established until it dies.
+func (d *Dialer) connect(ctx context.Context, attempt int) error {
+	start := 0
+	switch d.strategy {
+	case StrategyRoundRobin:
+		start = d.next
+	case StrategySticky:
+		start = d.preferred
+	}
+
+	var errs []error
+	for i := range d.endpoints {
+		idx := (start + i) % len(d.endpoints)
+		ep := d.endpoints[idx]
+		d.next = (idx + 1) % len(d.endpoints)
+
+		log.Info().Str("state", "connecting").Str("transport", ep.Transport.Name()).Int("attempt", attempt).Msgf("connecting to %s", ep.Address)
+		err := d.dialAndServer(ctx, ep)
+		if errors.Is(err, errConnectionLost) {
+			d.preferred = idx
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if len(d.endpoints) > 1 {
+			log.Warn().Err(err).Str("transport", ep.Transport.Name()).Msgf("could not connect to %s, failing over", ep.Address)
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", ep.Address, err))
+	}
+
+	return errors.Join(errs...)
+}
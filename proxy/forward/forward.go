@@ -0,0 +1,314 @@
+// Package forward implements port forwarding through an agent without a TUN
+// device. A local forward listens on the proxy host and relays every
+// connection to a remote address dialed by the agent, like ssh -L. A reverse
+// forward listens on the agent and relays connections to a target dialed by
+// the proxy, like ssh -R.
+package forward
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/relay"
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/lithammer/shortuuid/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Forward is a local listener whose connections are relayed through an agent.
+type Forward struct {
+	ID      string
+	Network string
+	Local   string
+	Remote  string
+
+	conn   transport.StreamConn
+	caps   protocol.Capability
+	target protocol.IPAddressWithPortProtocol
+	cancel context.CancelFunc
+	closer func() error
+	active atomic.Int64
+}
+
+// ParseTarget parses a remote host:port into the address sent to the agent.
+// The host must be an IP address, it is dialed by the agent as is.
+func ParseTarget(network, address string) (protocol.IPAddressWithPortProtocol, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return protocol.IPAddressWithPortProtocol{}, fmt.Errorf("invalid address \"%s\": %w", address, err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return protocol.IPAddressWithPortProtocol{}, fmt.Errorf("invalid address \"%s\": host must be an IP address", address)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return protocol.IPAddressWithPortProtocol{}, fmt.Errorf("invalid port \"%s\": %w", portStr, err)
+	}
+
+	target := protocol.IPAddressWithPortProtocol{
+		IP:      ip,
+		Port:    uint16(port),
+		Network: protocol.Networkv4,
+	}
+	if ip.To4() == nil {
+		target.Network = protocol.Networkv6
+	}
+	switch network {
+	case "tcp":
+		target.Protocol = protocol.TransportTCP
+	case "udp":
+		target.Protocol = protocol.TransportUDP
+	default:
+		return protocol.IPAddressWithPortProtocol{}, fmt.Errorf("unsupported protocol \"%s\"", network)
+	}
+
+	return target, nil
+}
+
+// Listen starts a local forward on local that relays connections to remote
+// through the agent connected over conn. caps are the capabilities negotiated
+// with the agent. network is "tcp" or "udp".
+func Listen(ctx context.Context, conn transport.StreamConn, caps protocol.Capability, network, local, remote string) (*Forward, error) {
+	target, err := ParseTarget(network, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	f := &Forward{
+		ID:      shortuuid.New(),
+		Network: network,
+		Remote:  remote,
+		conn:    conn,
+		caps:    caps,
+		target:  target,
+		cancel:  cancel,
+	}
+
+	switch network {
+	case "tcp":
+		ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", local)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("could not listen on %s: %w", local, err)
+		}
+		f.Local, f.closer = ln.Addr().String(), ln.Close
+		go f.serveTCP(ctx, ln)
+	case "udp":
+		pc, err := (&net.ListenConfig{}).ListenPacket(ctx, "udp", local)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("could not listen on %s: %w", local, err)
+		}
+		f.Local, f.closer = pc.LocalAddr().String(), pc.Close
+		go f.serveUDP(ctx, pc)
+	}
+
+	log.Info().Str("id", f.ID).Msgf("forwarding %s %s to %s", network, f.Local, remote)
+	return f, nil
+}
+
+// Connections returns the number of connections currently relayed.
+func (f *Forward) Connections() int64 {
+	return f.active.Load()
+}
+
+// Close stops the listener and every relayed connection.
+func (f *Forward) Close() error {
+	f.cancel()
+	return f.closer()
+}
+
+func (f *Forward) serveTCP(ctx context.Context, ln net.Listener) {
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error().Err(err).Str("id", f.ID).Msg("could not accept connection")
+			}
+			return
+		}
+
+		go f.handleTCP(ctx, c)
+	}
+}
+
+func (f *Forward) handleTCP(ctx context.Context, c net.Conn) {
+	defer c.Close()
+	f.active.Add(1)
+	defer f.active.Add(-1)
+
+	stream, err := f.open(ctx)
+	if err != nil {
+		log.Error().Err(err).Str("id", f.ID).Msgf("could not forward connection from %s", c.RemoteAddr())
+		return
+	}
+	// Streams carry a single request and are never reused.
+	defer stream.Close()
+
+	// Close both sides when the forward is removed.
+	stop := context.AfterFunc(ctx, func() {
+		c.Close()
+	})
+	defer stop()
+	abort := transport.AbortOnDone(ctx, stream)
+	defer abort()
+
+	if err := relay.Pipe(stream, c); err != nil {
+		log.Error().Err(err).Str("id", f.ID).Msg("could not pipe data between stream and connection")
+	}
+}
+
+// udpQueueSize is the number of datagrams queued per client, more are
+// dropped. Datagrams queue up while the flow is set up.
+const udpQueueSize = 64
+
+// udpFlow carries the datagrams of one client address.
+type udpFlow struct {
+	queue chan []byte
+}
+
+// serveUDP relays the datagrams of every client address as a separate flow,
+// set up without holding up the others.
+func (f *Forward) serveUDP(ctx context.Context, pc net.PacketConn) {
+	// Flows end with the listener.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu    sync.Mutex
+		flows = make(map[string]*udpFlow)
+	)
+
+	// relayFlow sets up the flow of the client addr and relays its datagrams
+	// until it is idle or the listener is closed.
+	relayFlow := func(key string, addr net.Addr, flow *udpFlow) {
+		f.active.Add(1)
+		defer func() {
+			mu.Lock()
+			if flows[key] == flow {
+				delete(flows, key)
+			}
+			mu.Unlock()
+			f.active.Add(-1)
+		}()
+
+		stream, err := f.open(ctx)
+		if err != nil {
+			log.Error().Err(err).Str("id", f.ID).Msgf("could not forward datagrams from %s", addr)
+			return
+		}
+		conn := relay.NewIdleConn(relay.NewUDPStream(stream, f.caps), config.UDPIdleTimeout)
+		defer conn.Close()
+
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+
+			buf := make([]byte, relay.MaxDatagramSize)
+			for {
+				n, err := conn.Read(buf)
+				if err != nil {
+					return
+				}
+				if _, err := pc.WriteTo(buf[:n], addr); err != nil {
+					return
+				}
+			}
+		}()
+
+		for {
+			select {
+			case <-closed:
+				return
+			case <-ctx.Done():
+				return
+			case payload := <-flow.queue:
+				if _, err := conn.Write(payload); err != nil {
+					log.Debug().Err(err).Str("id", f.ID).Msgf("could not forward datagram from %s", addr)
+				}
+			}
+		}
+	}
+
+	buf := make([]byte, relay.MaxDatagramSize)
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error().Err(err).Str("id", f.ID).Msg("could not read datagram")
+			}
+			return
+		}
+
+		key := addr.String()
+		mu.Lock()
+		flow, ok := flows[key]
+		if !ok {
+			flow = &udpFlow{queue: make(chan []byte, udpQueueSize)}
+			flows[key] = flow
+			go relayFlow(key, addr, flow)
+		}
+		mu.Unlock()
+
+		select {
+		case flow.queue <- bytes.Clone(buf[:n]):
+		default:
+			log.Debug().Str("id", f.ID).Msgf("dropping datagram from %s, the queue is full", addr)
+		}
+	}
+}
+
+// open asks the agent to connect to the forward's target and returns the
+// stream relaying the connection.
+func (f *Forward) open(ctx context.Context) (transport.Stream, error) {
+	stream, resp, err := Open(ctx, f.conn, f.target)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Established {
+		return nil, fmt.Errorf("agent could not connect to %s: %s", f.Remote, resp.Reason)
+	}
+
+	return stream, nil
+}
+
+// Open asks the agent connected over conn to connect to target and returns
+// the stream relaying the connection. It gives up when the agent does not
+// answer within config.ConnectTimeout or ctx is done. If the agent could not
+// connect the stream is nil and resp holds the reason.
+func Open(ctx context.Context, conn transport.StreamConn, target protocol.IPAddressWithPortProtocol) (transport.Stream, protocol.ConnectResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.ConnectTimeout)
+	defer cancel()
+
+	stream, err := conn.GetStream(ctx)
+	if err != nil {
+		return nil, protocol.ConnectResponse{}, fmt.Errorf("could not open stream: %w", err)
+	}
+
+	// Give up when the agent does not answer in time.
+	stop := transport.AbortOnDone(ctx, stream)
+	resp, err := protocol.EstablishConnection(stream, target)
+	if !stop() && err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		stream.Close()
+		return nil, protocol.ConnectResponse{}, fmt.Errorf("could not establish connection: %w", err)
+	}
+	if !resp.Established {
+		stream.Close()
+		return nil, resp, nil
+	}
+
+	return stream, resp, nil
+}
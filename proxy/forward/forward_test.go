@@ -0,0 +1,250 @@
+package forward
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/relay"
+	"github.com/fr13n8/raido/proxy/transport"
+)
+
+// agentConn answers every stream like an agent would, dialing the requested
+// address on the local host.
+type agentConn struct {
+	transport.StreamConn
+}
+
+func (c agentConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	proxySide, agentSide := net.Pipe()
+	go func() {
+		defer agentSide.Close()
+
+		var addr protocol.IPAddressWithPortProtocol
+		dec, err := protocol.ReadData(agentSide)
+		if err != nil || addr.UnmarshalBinary(dec.Body) != nil {
+			return
+		}
+		network := map[uint8]string{protocol.TransportTCP: "tcp", protocol.TransportUDP: "udp"}[addr.Protocol]
+		target, err := net.Dial(network, net.JoinHostPort(addr.IP.String(), fmt.Sprint(addr.Port)))
+		if err != nil {
+			protocol.Send(agentSide, protocol.ConnectResponseCmd, protocol.ConnectResponse{Reason: protocol.ReasonRefused})
+			return
+		}
+		defer target.Close()
+		protocol.Send(agentSide, protocol.ConnectResponseCmd, protocol.ConnectResponse{Established: true})
+
+		var stream io.ReadWriteCloser = agentSide
+		if network == "udp" {
+			stream = relay.NewDatagramStream(agentSide)
+		}
+		relay.Pipe(target, stream)
+	}()
+	return proxySide, nil
+}
+
+func TestForwardTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(c, c)
+		}
+	}()
+
+	f, err := Listen(context.Background(), agentConn{}, protocol.SupportedCapabilities, "tcp", "127.0.0.1:0", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	c, err := net.Dial("tcp", f.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := c.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(c, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "hello" {
+		t.Errorf("got %q, want %q", buf, "hello")
+	}
+}
+
+func TestForwardUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			pc.WriteTo(buf[:n], addr)
+		}
+	}()
+
+	f, err := Listen(context.Background(), agentConn{}, protocol.SupportedCapabilities, "udp", "127.0.0.1:0", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	c, err := net.Dial("udp", f.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+
+	for _, msg := range []string{"one", "two"} {
+		if _, err := c.Write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 1500)
+		n, err := c.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf[:n]) != msg {
+			t.Errorf("got %q, want %q", buf[:n], msg)
+		}
+	}
+}
+
+// stallingConn never answers the first stream, like an agent that went
+// silent, and answers the others like agentConn. stalled is closed once the
+// first stream is opened.
+type stallingConn struct {
+	agentConn
+	streams *atomic.Int32
+	stalled chan struct{}
+}
+
+func (c stallingConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	if c.streams.Add(1) == 1 {
+		proxySide, _ := net.Pipe()
+		close(c.stalled)
+		return proxySide, nil
+	}
+	return c.agentConn.GetStream(ctx)
+}
+
+func TestForwardUDPStalledFlow(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			pc.WriteTo(buf[:n], addr)
+		}
+	}()
+
+	conn := stallingConn{streams: new(atomic.Int32), stalled: make(chan struct{})}
+	f, err := Listen(context.Background(), conn, protocol.SupportedCapabilities, "udp", "127.0.0.1:0", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	stalled, err := net.Dial("udp", f.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stalled.Close()
+	if _, err := stalled.Write([]byte("stalled")); err != nil {
+		t.Fatal(err)
+	}
+	<-conn.stalled
+
+	// The second client is served while the first flow waits for the agent.
+	c, err := net.Dial("udp", f.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := c.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1500)
+	n, err := c.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:n]) != "hello" {
+		t.Errorf("got %q, want %q", buf[:n], "hello")
+	}
+}
+
+func TestForwardRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	f, err := Listen(context.Background(), agentConn{}, protocol.SupportedCapabilities, "tcp", "127.0.0.1:0", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	c, err := net.Dial("tcp", f.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := c.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("got %v, want EOF", err)
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	if _, err := ParseTarget("tcp", "db.internal:5432"); err == nil {
+		t.Error("expected an error for a hostname")
+	}
+	if _, err := ParseTarget("sctp", "10.2.0.4:5432"); err == nil {
+		t.Error("expected an error for an unsupported protocol")
+	}
+
+	target, err := ParseTarget("udp", "[fd00::4]:53")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.Network != protocol.Networkv6 || target.Protocol != protocol.TransportUDP || target.Port != 53 {
+		t.Errorf("unexpected target %+v", target)
+	}
+}
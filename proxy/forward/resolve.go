@@ -0,0 +1,114 @@
+package forward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net"
+
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/transport"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// LookupIP looks up host with the resolver of the agent connected over conn.
+// IPv4 addresses are preferred, IPv6 is only queried if the host has no IPv4
+// address. The agent must support protocol.CapDNS.
+func LookupIP(ctx context.Context, conn transport.StreamConn, host string) (net.IP, error) {
+	name, err := dnsmessage.NewName(host + ".")
+	if err != nil {
+		return nil, fmt.Errorf("invalid host name \"%s\": %w", host, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.ResolveTimeout)
+	defer cancel()
+
+	for _, t := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		ip, err := query(ctx, conn, name, t)
+		if err != nil {
+			return nil, err
+		}
+		if ip != nil {
+			return ip, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no address for %s", host)
+}
+
+// query sends a single question to the agent and returns the first address
+// of the answer, or nil if there is none.
+func query(ctx context.Context, conn transport.StreamConn, name dnsmessage.Name, t dnsmessage.Type) (net.IP, error) {
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: uint16(rand.N(math.MaxUint16)), RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: t, Class: dnsmessage.ClassINET}},
+	}
+	q, err := msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := conn.GetStream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not open stream: %w", err)
+	}
+	// The stream is done after a single request and response.
+	defer stream.Close()
+
+	// Give up on the stream when the lookup times out.
+	stop := transport.AbortOnDone(ctx, stream)
+	defer stop()
+
+	resp, err := protocol.Resolve(stream, protocol.ResolveRequest{MaxSize: math.MaxUint16, Query: q})
+	if err != nil {
+		return nil, err
+	}
+
+	var p dnsmessage.Parser
+	h, err := p.Start(resp)
+	if err != nil {
+		return nil, err
+	}
+	switch h.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, errors.New("no such host")
+	default:
+		return nil, fmt.Errorf("agent could not resolve %s: %s", name, h.RCode)
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, err
+	}
+
+	for {
+		ah, err := p.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch ah.Type {
+		case dnsmessage.TypeA:
+			r, err := p.AResource()
+			if err != nil {
+				return nil, err
+			}
+			return net.IP(r.A[:]), nil
+		case dnsmessage.TypeAAAA:
+			r, err := p.AAAAResource()
+			if err != nil {
+				return nil, err
+			}
+			return net.IP(r.AAAA[:]), nil
+		default:
+			if err := p.SkipAnswer(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package forward
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/relay"
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/lithammer/shortuuid/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ReverseForward is a listener opened on the agent whose connections are
+// carried back to the proxy and relayed to a target it dials, like ssh -R.
+type ReverseForward struct {
+	ID string
+	// WireID identifies the forward in the streams opened by the agent.
+	WireID      uint32
+	AgentListen string
+	Target      string
+
+	ctl    transport.Stream
+	done   chan struct{}
+	active atomic.Int64
+}
+
+// ListenReverse asks the agent connected over conn to listen for TCP
+// connections on agentListen. Connections accepted by the agent arrive as
+// streams tagged with wireID and must be passed to Serve.
+func ListenReverse(ctx context.Context, conn transport.StreamConn, wireID uint32, agentListen, target string) (*ReverseForward, error) {
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		return nil, fmt.Errorf("invalid target \"%s\": %w", target, err)
+	}
+
+	stream, err := conn.GetStream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not open stream: %w", err)
+	}
+
+	resp, err := protocol.ReverseListen(stream, protocol.ReverseListenRequest{
+		ID:      wireID,
+		Address: agentListen,
+	})
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+	if resp.Error != "" {
+		stream.Close()
+		return nil, fmt.Errorf("agent could not listen on %s: %s", agentListen, resp.Error)
+	}
+
+	f := &ReverseForward{
+		ID:          shortuuid.New(),
+		WireID:      wireID,
+		AgentListen: resp.Address,
+		Target:      target,
+		ctl:         stream,
+		done:        make(chan struct{}),
+	}
+
+	// The agent closes the control stream when its listener goes away.
+	go func() {
+		defer close(f.done)
+		io.Copy(io.Discard, stream)
+	}()
+
+	log.Info().Str("id", f.ID).Msgf("forwarding agent %s to %s", f.AgentListen, target)
+	return f, nil
+}
+
+// Serve relays a connection accepted by the agent to the forward's target.
+// The stream carried the ReverseConnect request described by req.
+func (f *ReverseForward) Serve(ctx context.Context, stream transport.Stream, req protocol.ReverseConnectRequest) {
+	f.active.Add(1)
+	defer f.active.Add(-1)
+
+	c, err := (&net.Dialer{Timeout: config.DialTimeout}).DialContext(ctx, "tcp", f.Target)
+	if err != nil {
+		reason := relay.ConnectReason(err)
+		log.Error().Err(err).Str("id", f.ID).Stringer("reason", reason).Msgf("could not forward connection from %s", req.RemoteAddr)
+		if err := protocol.Send(stream, protocol.ConnectResponseCmd, protocol.ConnectResponse{Reason: reason}); err != nil {
+			log.Error().Err(err).Msg("could not encode connection response")
+		}
+		return
+	}
+	defer c.Close()
+
+	if err := protocol.Send(stream, protocol.ConnectResponseCmd, protocol.ConnectResponse{Established: true}); err != nil {
+		log.Error().Err(err).Msg("could not encode connection response")
+		return
+	}
+
+	// Close both sides when the forward is removed.
+	stop := context.AfterFunc(ctx, func() {
+		c.Close()
+	})
+	defer stop()
+	abort := transport.AbortOnDone(ctx, stream)
+	defer abort()
+
+	if err := relay.Pipe(stream, c); err != nil {
+		log.Error().Err(err).Str("id", f.ID).Msg("could not pipe data between stream and connection")
+	}
+}
+
+// Done is closed when the agent stopped listening.
+func (f *ReverseForward) Done() <-chan struct{} {
+	return f.done
+}
+
+// Connections returns the number of connections currently relayed.
+func (f *ReverseForward) Connections() int64 {
+	return f.active.Load()
+}
+
+// Close asks the agent to stop listening. Relayed connections are not
+// interrupted.
+func (f *ReverseForward) Close() error {
+	return f.ctl.Close()
+}
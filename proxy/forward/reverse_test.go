@@ -0,0 +1,119 @@
+package forward
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/transport"
+)
+
+// listenConn answers reverse listen requests with resp.
+type listenConn struct {
+	transport.StreamConn
+	resp protocol.ReverseListenResp
+	reqs chan protocol.ReverseListenRequest
+}
+
+func (c listenConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	proxySide, agentSide := net.Pipe()
+	go func() {
+		var req protocol.ReverseListenRequest
+		if err := protocol.Receive(agentSide, protocol.ReverseListenCmd, &req); err != nil {
+			agentSide.Close()
+			return
+		}
+		c.reqs <- req
+		protocol.Send(agentSide, protocol.ReverseListenRespCmd, c.resp)
+		// Stop listening when the proxy closes the control stream.
+		io.Copy(io.Discard, agentSide)
+		agentSide.Close()
+	}()
+	return proxySide, nil
+}
+
+func TestReverseForward(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(c, c)
+		}
+	}()
+
+	conn := listenConn{
+		resp: protocol.ReverseListenResp{Address: "0.0.0.0:8080"},
+		reqs: make(chan protocol.ReverseListenRequest, 1),
+	}
+	f, err := ListenReverse(context.Background(), conn, 7, "0.0.0.0:8080", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := <-conn.reqs; req.ID != 7 || req.Address != "0.0.0.0:8080" {
+		t.Errorf("unexpected request %+v", req)
+	}
+	if f.AgentListen != "0.0.0.0:8080" {
+		t.Errorf("got listen address %q, want %q", f.AgentListen, "0.0.0.0:8080")
+	}
+
+	// Hand a connection to the proxy like the agent does.
+	proxySide, agentSide := net.Pipe()
+	defer agentSide.Close()
+	go func() {
+		var req protocol.ReverseConnectRequest
+		if err := protocol.Receive(proxySide, protocol.ReverseConnectCmd, &req); err != nil {
+			proxySide.Close()
+			return
+		}
+		f.Serve(context.Background(), proxySide, req)
+	}()
+
+	agentSide.SetDeadline(time.Now().Add(5 * time.Second))
+	resp, err := protocol.ReverseConnect(agentSide, protocol.ReverseConnectRequest{ID: 7, RemoteAddr: "10.2.0.4:41234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Established {
+		t.Fatalf("connection not established: %s", resp.Reason)
+	}
+
+	if _, err := agentSide.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(agentSide, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "hello" {
+		t.Errorf("got %q, want %q", buf, "hello")
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-f.Done():
+	case <-time.After(5 * time.Second):
+		t.Error("forward not done after close")
+	}
+}
+
+func TestReverseForwardListenError(t *testing.T) {
+	conn := listenConn{
+		resp: protocol.ReverseListenResp{Error: "address already in use"},
+		reqs: make(chan protocol.ReverseListenRequest, 1),
+	}
+	if _, err := ListenReverse(context.Background(), conn, 1, "0.0.0.0:22", "127.0.0.1:22"); err == nil {
+		t.Error("expected an error when the agent cannot listen")
+	}
+}
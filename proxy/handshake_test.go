@@ -0,0 +1,190 @@
+package proxy
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/transport"
+)
+
+// agentConn answers the handshake with the dialer d. tamper corrupts the
+// signature of the answer.
+type agentConn struct {
+	transport.StreamConn
+	d      *Dialer
+	tamper bool
+}
+
+func (c agentConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	proxySide, agentSide := net.Pipe()
+	go func() {
+		defer agentSide.Close()
+
+		dec, err := protocol.ReadData(agentSide)
+		if err != nil {
+			return
+		}
+		if c.tamper {
+			var req protocol.HandshakeReq
+			req.UnmarshalBinary(dec.Body)
+			req.Nonce = append(req.Nonce, 0)
+			dec.Body, _ = req.MarshalBinary()
+		}
+		c.d.handleHandshake(ctx, &session{}, agentSide, dec)
+	}()
+	return proxySide, nil
+}
+
+func TestHandshakeIdentity(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	d := NewDialer(context.Background(), nil, "", WithIdentity(key))
+	s := &Server{}
+
+	resp, err := s.handshake(context.Background(), agentConn{d: d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(resp.PublicKey)) {
+		t.Errorf("got public key %x, want the agent's key", resp.PublicKey)
+	}
+
+	if _, err := s.handshake(context.Background(), agentConn{d: d, tamper: true}); err == nil {
+		t.Error("handshake with an invalid signature succeeded")
+	}
+}
+
+// legacyAgentConn answers the handshake with version, leaving out the fields
+// added after version 3.
+type legacyAgentConn struct {
+	transport.StreamConn
+	version uint16
+}
+
+func (c legacyAgentConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	proxySide, agentSide := net.Pipe()
+	go func() {
+		defer agentSide.Close()
+
+		if _, err := protocol.ReadData(agentSide); err != nil {
+			return
+		}
+		body, _ := protocol.HandshakeResp{Version: c.version, Name: "user@host"}.MarshalBinary()
+		// Strip the empty key, signature, PID, uptime and interface count.
+		body = body[:len(body)-14]
+		protocol.WriteData(agentSide, protocol.Data{Command: protocol.HandshakeRespCmd, Body: body})
+	}()
+	return proxySide, nil
+}
+
+func TestHandshakeLegacyAgent(t *testing.T) {
+	s := &Server{}
+
+	resp, err := s.handshake(context.Background(), legacyAgentConn{version: protocol.VersionIdentity - 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Version != protocol.VersionIdentity-1 || resp.Name != "user@host" || resp.PublicKey != nil {
+		t.Errorf("got %+v", resp)
+	}
+
+	if _, err := s.handshake(context.Background(), legacyAgentConn{version: protocol.VersionIdentity}); err == nil {
+		t.Error("handshake without identity succeeded at VersionIdentity")
+	}
+}
+
+// handshake sends req to the dialer d and returns its answer and the session
+// it set up.
+func handshake(t *testing.T, d *Dialer, req protocol.HandshakeReq) (protocol.HandshakeResp, *session) {
+	t.Helper()
+	body, err := req.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sess := &session{}
+	proxySide, agentSide := net.Pipe()
+	defer proxySide.Close()
+	go d.handleHandshake(context.Background(), sess, agentSide, protocol.Data{Command: protocol.HandshakeCmd, Body: body})
+
+	var resp protocol.HandshakeResp
+	if err := protocol.Receive(proxySide, protocol.HandshakeRespCmd, &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp, sess
+}
+
+func TestHandshakeNegotiation(t *testing.T) {
+	d := NewDialer(context.Background(), nil, "", WithCapabilities(protocol.CapICMP|protocol.CapDNS))
+
+	tests := []struct {
+		name     string
+		req      protocol.HandshakeReq
+		version  uint16
+		caps     protocol.Capability
+		accepted bool
+	}{
+		{
+			name:     "newer proxy",
+			req:      protocol.HandshakeReq{Version: protocol.Version + 1, Capabilities: protocol.CapICMP | protocol.CapRelay},
+			version:  protocol.Version,
+			caps:     protocol.CapICMP,
+			accepted: true,
+		},
+		{
+			name:     "oldest supported proxy",
+			req:      protocol.HandshakeReq{Version: protocol.MinVersion, Capabilities: protocol.SupportedCapabilities},
+			version:  protocol.MinVersion,
+			caps:     protocol.CapICMP | protocol.CapDNS,
+			accepted: true,
+		},
+		{
+			name:     "no common capabilities",
+			req:      protocol.HandshakeReq{Version: protocol.Version, Capabilities: protocol.CapRelay},
+			version:  protocol.Version,
+			accepted: true,
+		},
+		{
+			name: "proxy too old",
+			req:  protocol.HandshakeReq{Version: protocol.MinVersion - 1, Capabilities: protocol.SupportedCapabilities},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, sess := handshake(t, d, tt.req)
+			if accepted := resp.Error == ""; accepted != tt.accepted {
+				t.Fatalf("accepted = %t, want %t (error %q)", accepted, tt.accepted, resp.Error)
+			}
+			if sess.handshaked.Load() != tt.accepted {
+				t.Errorf("session handshaked = %t, want %t", sess.handshaked.Load(), tt.accepted)
+			}
+			if !tt.accepted {
+				return
+			}
+			if resp.Version != tt.version || resp.Capabilities != tt.caps || sess.capabilities() != tt.caps {
+				t.Errorf("got version %d, capabilities %s and %s in the session, want %d, %s", resp.Version, resp.Capabilities, sess.capabilities(), tt.version, tt.caps)
+			}
+		})
+	}
+}
+
+func TestHandshakeProxySide(t *testing.T) {
+	s := &Server{}
+
+	d := NewDialer(context.Background(), nil, "", WithCapabilities(protocol.CapICMP|protocol.CapDNS))
+	resp, err := s.handshake(context.Background(), agentConn{d: d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Version != protocol.Version || resp.Capabilities != protocol.CapICMP|protocol.CapDNS {
+		t.Errorf("got version %d and capabilities %s", resp.Version, resp.Capabilities)
+	}
+
+	_, err = s.handshake(context.Background(), legacyAgentConn{version: protocol.MinVersion - 1})
+	if err == nil || !strings.Contains(err.Error(), "unsupported protocol version") {
+		t.Errorf("got error %v for an agent of version %d", err, protocol.MinVersion-1)
+	}
+}
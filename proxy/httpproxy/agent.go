@@ -0,0 +1,120 @@
+package httpproxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/fr13n8/raido/proxy/forward"
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/transport"
+)
+
+// Agent is an agent requests can be relayed through.
+type Agent struct {
+	ID           string
+	Conn         transport.StreamConn
+	Capabilities protocol.Capability
+	// Routes are the networks the agent advertised, in CIDR notation.
+	Routes []string
+}
+
+// ConnectError is returned when the agent could not connect to the target.
+type ConnectError struct {
+	Agent  string
+	Target string
+	Reason protocol.Reason
+}
+
+func (e *ConnectError) Error() string {
+	return fmt.Sprintf("agent %s could not connect to %s: %s", e.Agent, e.Target, e.Reason)
+}
+
+// selectAgent picks the agent to reach host through. IP addresses go to the
+// agent with the most specific route containing them, ties go to the lowest
+// agent ID. Host names are resolved by the agents that support it in order of
+// their IDs and the first answer wins. The address is then matched against
+// the routes of every agent like an IP address, the resolving agent is used
+// if none matches.
+func selectAgent(ctx context.Context, agents []Agent, host string) (Agent, net.IP, error) {
+	agents = slices.SortedFunc(slices.Values(agents), func(a, b Agent) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	if ip := net.ParseIP(host); ip != nil {
+		if a, ok := longestMatch(agents, ip); ok {
+			return a, ip, nil
+		}
+		return Agent{}, nil, fmt.Errorf("no agent has a route to %s", ip)
+	}
+
+	var errs []error
+	for _, a := range agents {
+		if !a.Capabilities.Has(protocol.CapDNS) {
+			continue
+		}
+
+		ip, err := forward.LookupIP(ctx, a.Conn, host)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("agent %s: %w", a.ID, err))
+			continue
+		}
+		if match, ok := longestMatch(agents, ip); ok {
+			return match, ip, nil
+		}
+		return a, ip, nil
+	}
+
+	if len(errs) == 0 {
+		return Agent{}, nil, fmt.Errorf("no agent can resolve %s", host)
+	}
+	return Agent{}, nil, fmt.Errorf("could not resolve %s: %w", host, errs[0])
+}
+
+// longestMatch returns the agent with the longest route prefix containing ip,
+// the first of them in agents if several have the same prefix length.
+func longestMatch(agents []Agent, ip net.IP) (Agent, bool) {
+	var (
+		best   Agent
+		bestOk bool
+		bestSz = -1
+	)
+	for _, a := range agents {
+		for _, route := range a.Routes {
+			_, network, err := net.ParseCIDR(route)
+			if err != nil || !network.Contains(ip) {
+				continue
+			}
+			if size, _ := network.Mask.Size(); size > bestSz {
+				best, bestOk, bestSz = a, true, size
+			}
+		}
+	}
+	return best, bestOk
+}
+
+// dial asks the agent to connect to ip and port and returns the stream as a
+// net.Conn.
+func (a Agent) dial(ctx context.Context, ip net.IP, port uint16) (net.Conn, error) {
+	target := protocol.IPAddressWithPortProtocol{
+		IP:       ip,
+		Port:     port,
+		Network:  protocol.Networkv4,
+		Protocol: protocol.TransportTCP,
+	}
+	if ip.To4() == nil {
+		target.Network = protocol.Networkv6
+	}
+
+	stream, resp, err := forward.Open(ctx, a.Conn, target)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Established {
+		return nil, &ConnectError{Agent: a.ID, Target: net.JoinHostPort(ip.String(), fmt.Sprint(port)), Reason: resp.Reason}
+	}
+
+	return transport.NetConn(stream, &net.TCPAddr{}, &net.TCPAddr{IP: ip, Port: int(port)}), nil
+}
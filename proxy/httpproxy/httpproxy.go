@@ -0,0 +1,181 @@
+// Package httpproxy implements an HTTP proxy whose connections are relayed
+// through agents. CONNECT tunnels and plain HTTP requests are supported, the
+// agent of every request is picked by the routes it advertises, so tools
+// configured with HTTP_PROXY reach the networks of all connected agents.
+package httpproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/relay"
+	"github.com/rs/zerolog/log"
+)
+
+// Server is an HTTP proxy listener relaying through agents.
+type Server struct {
+	Address string
+
+	agents    func() []Agent
+	srv       *http.Server
+	transport *http.Transport
+	active    atomic.Int64
+}
+
+// Listen starts an HTTP proxy on address. agents returns the agents requests
+// may be relayed through, it is called for every request.
+func Listen(ctx context.Context, address string, agents func() []Agent) (*Server, error) {
+	ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen on %s: %w", address, err)
+	}
+
+	s := &Server{
+		Address: ln.Addr().String(),
+		agents:  agents,
+	}
+	s.transport = &http.Transport{
+		DialContext:         s.dial,
+		ForceAttemptHTTP2:   false,
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	s.srv = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 30 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	// Stop with the service.
+	context.AfterFunc(ctx, func() {
+		s.Close()
+	})
+
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("HTTP proxy stopped")
+		}
+	}()
+
+	log.Info().Msgf("HTTP proxy listening on %s", s.Address)
+	return s, nil
+}
+
+// Connections returns the number of requests and tunnels currently relayed.
+func (s *Server) Connections() int64 {
+	return s.active.Load()
+}
+
+// Close stops the listener and every relayed connection.
+func (s *Server) Close() error {
+	s.transport.CloseIdleConnections()
+	return s.srv.Close()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.active.Add(1)
+	defer s.active.Add(-1)
+
+	if r.Method == http.MethodConnect {
+		s.handleConnect(w, r)
+		return
+	}
+
+	if !r.URL.IsAbs() || r.URL.Scheme != "http" {
+		http.Error(w, "only absolute http:// URLs can be proxied, use CONNECT for https", http.StatusBadRequest)
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL = pr.In.URL
+			pr.Out.Host = pr.In.Host
+		},
+		Transport:    s.transport,
+		ErrorHandler: s.handleError,
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
+	stream, err := s.dial(r.Context(), "tcp", r.Host)
+	if err != nil {
+		s.handleError(w, r, err)
+		return
+	}
+	defer stream.Close()
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be hijacked", http.StatusInternalServerError)
+		return
+	}
+	c, rw, err := hj.Hijack()
+	if err != nil {
+		log.Error().Err(err).Msg("could not hijack HTTP proxy connection")
+		return
+	}
+	defer c.Close()
+
+	if _, err := c.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		return
+	}
+	// Forward what the client sent before it got the answer.
+	if n := rw.Reader.Buffered(); n > 0 {
+		buf, _ := rw.Reader.Peek(n)
+		if _, err := stream.Write(buf); err != nil {
+			return
+		}
+	}
+
+	if err := relay.Pipe(stream, c); err != nil {
+		log.Error().Err(err).Msg("could not pipe data between stream and HTTP proxy connection")
+	}
+}
+
+// handleError answers a request that could not be relayed.
+func (s *Server) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Debug().Err(err).Msgf("could not proxy %s %s", r.Method, r.Host)
+
+	status := http.StatusBadGateway
+	var connErr *ConnectError
+	switch {
+	case errors.As(err, &connErr) && connErr.Reason == protocol.ReasonDenied:
+		status = http.StatusForbidden
+	case errors.As(err, &connErr) && connErr.Reason == protocol.ReasonTimeout:
+		status = http.StatusGatewayTimeout
+	case relay.IsTimeout(err):
+		status = http.StatusGatewayTimeout
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// dial opens a TCP connection to address through the agent selected for it.
+func (s *Server) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		// URLs may omit the default port.
+		host, portStr = address, "80"
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port \"%s\": %w", portStr, err)
+	}
+
+	a, ip, err := selectAgent(ctx, s.agents(), host)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.dial(ctx, ip, uint16(port))
+}
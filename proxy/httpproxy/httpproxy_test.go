@@ -0,0 +1,269 @@
+package httpproxy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/relay"
+	"github.com/fr13n8/raido/proxy/transport"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// agentConn answers every stream like an agent would, dialing the requested
+// address on the local host.
+type agentConn struct {
+	transport.StreamConn
+}
+
+func (c agentConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	proxySide, agentSide := net.Pipe()
+	go func() {
+		defer agentSide.Close()
+
+		var addr protocol.IPAddressWithPortProtocol
+		dec, err := protocol.ReadData(agentSide)
+		if err != nil || addr.UnmarshalBinary(dec.Body) != nil {
+			return
+		}
+		target, err := net.Dial("tcp", net.JoinHostPort(addr.IP.String(), fmt.Sprint(addr.Port)))
+		if err != nil {
+			protocol.Send(agentSide, protocol.ConnectResponseCmd, protocol.ConnectResponse{Reason: protocol.ReasonRefused})
+			return
+		}
+		defer target.Close()
+		protocol.Send(agentSide, protocol.ConnectResponseCmd, protocol.ConnectResponse{Established: true})
+
+		relay.Pipe(target, agentSide)
+	}()
+	return proxySide, nil
+}
+
+func listen(t *testing.T, agents ...Agent) *Server {
+	t.Helper()
+	s, err := Listen(context.Background(), "127.0.0.1:0", func() []Agent { return agents })
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestPlainHTTP(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "hello %s", r.URL.Path)
+	}))
+	defer origin.Close()
+
+	s := listen(t, Agent{ID: "a", Conn: agentConn{}, Routes: []string{"127.0.0.0/8"}})
+	proxyURL, _ := url.Parse("http://" + s.Address)
+	client := &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)},
+		Timeout:   5 * time.Second,
+	}
+
+	resp, err := client.Get(origin.URL + "/registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "hello /registry" {
+		t.Errorf("got %d %q, want 200 %q", resp.StatusCode, body, "hello /registry")
+	}
+
+	resp, err = client.Get("http://10.99.0.1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("got %d for an address without route, want %d", resp.StatusCode, http.StatusBadGateway)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(c, c)
+		}
+	}()
+
+	s := listen(t, Agent{ID: "a", Conn: agentConn{}, Routes: []string{"127.0.0.1/32"}})
+
+	c, err := net.Dial("tcp", s.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+
+	fmt.Fprintf(c, "CONNECT %s HTTP/1.1\r\nHost: %[1]s\r\n\r\n", ln.Addr())
+	br := bufio.NewReader(c)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	if _, err := c.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "hello" {
+		t.Errorf("got %q, want %q", buf, "hello")
+	}
+}
+
+// silentConn opens streams to an agent that never answers.
+type silentConn struct {
+	transport.StreamConn
+}
+
+func (c silentConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	proxySide, _ := net.Pipe()
+	return proxySide, nil
+}
+
+func TestDialSilentAgent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := Agent{ID: "silent", Conn: silentConn{}}.dial(ctx, net.IPv4(10, 0, 0, 1), 80)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("dial through a silent agent succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("dial through a silent agent did not give up when the request ended")
+	}
+}
+
+// resolvingConn answers every A query with ip.
+type resolvingConn struct {
+	transport.StreamConn
+	ip net.IP
+}
+
+func (c resolvingConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	proxySide, agentSide := net.Pipe()
+	go func() {
+		defer agentSide.Close()
+
+		var req protocol.ResolveRequest
+		if err := protocol.Receive(agentSide, protocol.ResolveCmd, &req); err != nil {
+			return
+		}
+		var msg dnsmessage.Message
+		if err := msg.Unpack(req.Query); err != nil {
+			return
+		}
+		msg.Response = true
+		if q := msg.Questions[0]; q.Type == dnsmessage.TypeA {
+			msg.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class},
+				Body:   &dnsmessage.AResource{A: [4]byte(c.ip.To4())},
+			}}
+		}
+		resp, _ := msg.Pack()
+		protocol.Send(agentSide, protocol.ResolveReplyCmd, protocol.ResolveReply{Response: resp})
+	}()
+	return proxySide, nil
+}
+
+func TestSelectAgent(t *testing.T) {
+	agents := []Agent{
+		{ID: "c", Capabilities: protocol.CapDNS, Conn: resolvingConn{ip: net.IPv4(10, 3, 0, 1)}},
+		{ID: "b", Capabilities: protocol.CapDNS, Conn: resolvingConn{ip: net.IPv4(10, 2, 0, 1)}, Routes: []string{"10.0.0.0/8"}},
+		{ID: "a", Conn: resolvingConn{ip: net.IPv4(10, 1, 0, 1)}, Routes: []string{"10.2.0.0/16"}},
+		{ID: "d", Routes: []string{"10.2.0.0/16"}},
+	}
+
+	tests := []struct {
+		host   string
+		wantID string
+		wantIP string
+	}{
+		// b resolves first, a has the more specific route and the lowest ID.
+		{"example.com", "a", "10.2.0.1"},
+		{"10.2.3.4", "a", "10.2.3.4"},
+		{"10.9.9.9", "b", "10.9.9.9"},
+	}
+	for _, tt := range tests {
+		// The order agents are listed in does not matter.
+		for range 3 {
+			a, ip, err := selectAgent(context.Background(), agents, tt.host)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a.ID != tt.wantID || ip.String() != tt.wantIP {
+				t.Errorf("selectAgent(%s) = %s, %s, want %s, %s", tt.host, a.ID, ip, tt.wantID, tt.wantIP)
+			}
+			agents = append(agents[1:], agents[0])
+		}
+	}
+
+	// Without a route, the agent that resolved the name is used.
+	unrouted := []Agent{
+		{ID: "f", Capabilities: protocol.CapDNS, Conn: resolvingConn{ip: net.IPv4(172, 16, 0, 2)}},
+		{ID: "e", Capabilities: protocol.CapDNS, Conn: resolvingConn{ip: net.IPv4(172, 16, 0, 1)}},
+	}
+	a, ip, err := selectAgent(context.Background(), unrouted, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ID != "e" || ip.String() != "172.16.0.1" {
+		t.Errorf("selectAgent(example.com) = %s, %s, want e, 172.16.0.1", a.ID, ip)
+	}
+}
+
+func TestLongestMatch(t *testing.T) {
+	agents := []Agent{
+		{ID: "wide", Routes: []string{"10.0.0.5/8"}},
+		{ID: "narrow", Routes: []string{"192.168.1.2/24", "10.2.0.7/16"}},
+	}
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"10.1.2.3", "wide"},
+		{"10.2.9.9", "narrow"},
+		{"192.168.1.200", "narrow"},
+		{"172.16.0.1", ""},
+	}
+	for _, tt := range tests {
+		a, ok := longestMatch(agents, net.ParseIP(tt.ip))
+		if a.ID != tt.want || ok != (tt.want != "") {
+			t.Errorf("longestMatch(%s) = %q, %v, want %q", tt.ip, a.ID, ok, tt.want)
+		}
+	}
+}
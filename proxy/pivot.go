@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/relay"
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/rs/zerolog/log"
+)
+
+// serveRelayListener accepts downstream agents on the relay listener and
+// carries their connections to the proxy over conn until ctx is done. The
+// bytes are relayed as is, the downstream agent's TLS session ends at the
+// proxy.
+func (d *Dialer) serveRelayListener(ctx context.Context, conn transport.StreamConn) {
+	ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", d.relayAddress)
+	if err != nil {
+		log.Error().Err(err).Msgf("could not open relay listener on %s", d.relayAddress)
+		return
+	}
+	stop := context.AfterFunc(ctx, func() {
+		ln.Close()
+	})
+	defer stop()
+	defer ln.Close()
+
+	log.Info().Msgf("relay listener for downstream agents on %s", ln.Addr())
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error().Err(err).Msg("could not accept downstream agent")
+			}
+			log.Info().Msg("relay listener closed")
+			return
+		}
+
+		go relayAgent(ctx, conn, c)
+	}
+}
+
+// relayAgent carries the connection of a downstream agent to the proxy.
+func relayAgent(ctx context.Context, conn transport.StreamConn, c net.Conn) {
+	defer c.Close()
+
+	stream, err := conn.GetStream(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("could not open stream to proxy")
+		return
+	}
+	defer stream.Close()
+
+	resp, err := protocol.RelayConnect(stream, protocol.RelayConnectRequest{
+		RemoteAddr: c.RemoteAddr().String(),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("could not hand downstream agent to proxy")
+		return
+	}
+	if !resp.Established {
+		log.Warn().Stringer("reason", resp.Reason).Msgf("proxy refused downstream agent from %s", c.RemoteAddr())
+		return
+	}
+
+	log.Info().Msgf("relaying downstream agent from %s", c.RemoteAddr())
+	if err := relay.Pipe(stream, c); err != nil {
+		log.Error().Err(err).Msg("could not pipe data between stream and downstream agent")
+	}
+	log.Info().Msgf("downstream agent from %s disconnected", c.RemoteAddr())
+}
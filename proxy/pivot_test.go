@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/transport"
+)
+
+// proxyConn answers relay requests like the proxy would, echoing the relayed
+// bytes back if accept is set.
+type proxyConn struct {
+	transport.StreamConn
+	accept bool
+	reqs   chan protocol.RelayConnectRequest
+}
+
+func (c proxyConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	agentSide, proxySide := net.Pipe()
+	go func() {
+		defer proxySide.Close()
+
+		var req protocol.RelayConnectRequest
+		if err := protocol.Receive(proxySide, protocol.RelayConnectCmd, &req); err != nil {
+			return
+		}
+		c.reqs <- req
+		if !c.accept {
+			protocol.Send(proxySide, protocol.ConnectResponseCmd, protocol.ConnectResponse{Reason: protocol.ReasonDenied})
+			return
+		}
+		protocol.Send(proxySide, protocol.ConnectResponseCmd, protocol.ConnectResponse{Established: true})
+		io.Copy(proxySide, proxySide)
+	}()
+	return agentSide, nil
+}
+
+func TestRelayAgent(t *testing.T) {
+	for _, accept := range []bool{true, false} {
+		conn := proxyConn{accept: accept, reqs: make(chan protocol.RelayConnectRequest, 1)}
+		downstream, c := net.Pipe()
+		go relayAgent(context.Background(), conn, c)
+
+		req := <-conn.reqs
+		if req.RemoteAddr != c.RemoteAddr().String() {
+			t.Errorf("got remote address %q, want %q", req.RemoteAddr, c.RemoteAddr())
+		}
+
+		downstream.SetDeadline(time.Now().Add(5 * time.Second))
+		if !accept {
+			if _, err := downstream.Read(make([]byte, 1)); err != io.EOF {
+				t.Errorf("got %v, want EOF for a refused agent", err)
+			}
+			continue
+		}
+
+		if _, err := downstream.Write([]byte("hello")); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 5)
+		if _, err := io.ReadFull(downstream, buf); err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != "hello" {
+			t.Errorf("got %q, want %q", buf, "hello")
+		}
+		downstream.Close()
+	}
+}
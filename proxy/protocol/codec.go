@@ -0,0 +1,222 @@
+package protocol
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Frame header layout: command (1 byte) followed by the body length (4 bytes, big endian).
+const (
+	frameHeaderSize = 5
+	// MaxBodySize limits the body of a single frame to protect the reader from
+	// allocating arbitrary amounts of memory.
+	MaxBodySize = 1 << 20
+)
+
+var ErrBodyTooLarge = errors.New("frame body too large")
+
+// WriteData writes d as a single frame.
+func WriteData(w io.Writer, d Data) error {
+	if len(d.Body) > MaxBodySize {
+		return ErrBodyTooLarge
+	}
+
+	frame := make([]byte, frameHeaderSize, frameHeaderSize+len(d.Body))
+	frame[0] = byte(d.Command)
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(d.Body)))
+	frame = append(frame, d.Body...)
+
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadData reads exactly one frame from r. It never reads past the end of the
+// frame, so the stream can be handed over to a relay afterwards.
+func ReadData(r io.Reader) (Data, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Data{}, err
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > MaxBodySize {
+		return Data{}, ErrBodyTooLarge
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return Data{}, fmt.Errorf("could not read frame body: %w", err)
+	}
+
+	return Data{Command: Command(header[0]), Body: body}, nil
+}
+
+// Send marshals m and writes it as the body of a frame with the given command.
+func Send(w io.Writer, cmd Command, m encoding.BinaryMarshaler) error {
+	body, err := m.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("could not marshal %s: %w", cmd, err)
+	}
+
+	return WriteData(w, Data{Command: cmd, Body: body})
+}
+
+// Receive reads the next frame, checks that it carries cmd and unmarshals its body into m.
+func Receive(r io.Reader, cmd Command, m encoding.BinaryUnmarshaler) error {
+	d, err := ReadData(r)
+	if err != nil {
+		return err
+	}
+
+	if d.Command != cmd {
+		return fmt.Errorf("unexpected command %s, expected %s", d.Command, cmd)
+	}
+
+	return m.UnmarshalBinary(d.Body)
+}
+
+// writer appends primitive values to a message body. Lengths that do not fit
+// in 2 bytes are an error, the first one is sticky and returned by err.
+//
+// Strings are encoded as a 2 byte length followed by the UTF-8 bytes and lists
+// as a 2 byte element count followed by the elements.
+type writer struct {
+	buf []byte
+	e   error
+}
+
+// ErrFieldTooLong is returned when a field or list of a message is longer
+// than its 2 byte length can describe.
+var ErrFieldTooLong = errors.New("message field too long")
+
+func (w *writer) uint8(v uint8) {
+	w.buf = append(w.buf, v)
+}
+
+func (w *writer) uint16(v uint16) {
+	w.buf = binary.BigEndian.AppendUint16(w.buf, v)
+}
+
+func (w *writer) uint32(v uint32) {
+	w.buf = binary.BigEndian.AppendUint32(w.buf, v)
+}
+
+// length writes the length n of a field or list. It returns false, leaving
+// the error in w, if n does not fit.
+func (w *writer) length(n int) bool {
+	if n > math.MaxUint16 {
+		if w.e == nil {
+			w.e = fmt.Errorf("%w: %d, maximum is %d", ErrFieldTooLong, n, math.MaxUint16)
+		}
+		return false
+	}
+	w.uint16(uint16(n))
+	return true
+}
+
+func (w *writer) bytes(v []byte) {
+	if w.length(len(v)) {
+		w.buf = append(w.buf, v...)
+	}
+}
+
+func (w *writer) string(v string) {
+	if w.length(len(v)) {
+		w.buf = append(w.buf, v...)
+	}
+}
+
+func (w *writer) strings(v []string) {
+	if w.length(len(v)) {
+		for _, s := range v {
+			w.string(s)
+		}
+	}
+}
+
+func (w *writer) err() error {
+	return w.e
+}
+
+// reader consumes primitive values written by writer. The first error is
+// sticky and returned by err.
+type reader struct {
+	buf []byte
+	e   error
+}
+
+var errShortBody = errors.New("message body too short")
+
+func (r *reader) next(n int) []byte {
+	if r.e != nil {
+		return nil
+	}
+	if len(r.buf) < n {
+		r.e = errShortBody
+		return nil
+	}
+
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *reader) uint8() uint8 {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *reader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *reader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *reader) bytes() []byte {
+	n := r.uint16()
+	if b := r.next(int(n)); b != nil {
+		return append([]byte(nil), b...)
+	}
+	return nil
+}
+
+func (r *reader) string() string {
+	return string(r.bytes())
+}
+
+func (r *reader) strings() []string {
+	n := r.uint16()
+	var v []string
+	for range n {
+		s := r.string()
+		if r.e != nil {
+			return nil
+		}
+		v = append(v, s)
+	}
+	return v
+}
+
+// more reports whether the body has bytes left, fields appended to a message
+// in a later version are only decoded when it does.
+func (r *reader) more() bool {
+	return r.e == nil && len(r.buf) > 0
+}
+
+func (r *reader) err() error {
+	return r.e
+}
@@ -0,0 +1,345 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDataRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data Data
+	}{
+		{
+			name: "Empty body",
+			data: Data{Command: HandshakeCmd, Body: []byte{}},
+		},
+		{
+			name: "With body",
+			data: Data{Command: EstablishConnectionCmd, Body: []byte{1, 2, 3, 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteData(&buf, tt.data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Trailing bytes must be left untouched for the relay.
+			buf.WriteString("payload")
+
+			got, err := ReadData(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.data) {
+				t.Errorf("got %+v, want %+v", got, tt.data)
+			}
+			if buf.String() != "payload" {
+				t.Errorf("ReadData consumed trailing data, left %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestReadDataErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr error
+	}{
+		{
+			name:    "Empty input",
+			input:   nil,
+			wantErr: io.EOF,
+		},
+		{
+			name:    "Truncated header",
+			input:   []byte{byte(HandshakeCmd), 0, 0},
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "Truncated body",
+			input:   []byte{byte(HandshakeCmd), 0, 0, 0, 4, 1, 2},
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "Body too large",
+			input:   []byte{byte(HandshakeCmd), 0xff, 0xff, 0xff, 0xff},
+			wantErr: ErrBodyTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadData(bytes.NewReader(tt.input))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  Command
+		in   interface {
+			MarshalBinary() ([]byte, error)
+		}
+		out interface {
+			UnmarshalBinary([]byte) error
+		}
+	}{
+		{
+			name: "HandshakeReq",
+			cmd:  HandshakeCmd,
+			in:   HandshakeReq{Version: Version, Capabilities: CapDatagrams | CapICMP, Nonce: []byte{1, 2, 3, 4}},
+			out:  &HandshakeReq{},
+		},
+		{
+			name: "HandshakeResp",
+			cmd:  HandshakeRespCmd,
+			in: HandshakeResp{
+				Version:      Version,
+				Capabilities: CapReverseForward,
+				Name:         "user@host",
+				Routes:       []string{"10.1.0.3/16", "fd:1::3/64"},
+				Build:        BuildInfo{Version: "v1.0.0", GoVersion: "go1.26", OS: "linux", Arch: "amd64"},
+				PublicKey:    []byte{5, 6, 7},
+				Signature:    []byte{8, 9},
+				Host: HostInfo{
+					PID:    4242,
+					Uptime: 3600,
+					Interfaces: []Interface{
+						{Name: "eth0", MAC: "02:42:ac:11:00:02", MTU: 1500, Addrs: []string{"10.1.0.3/16"}},
+						{Name: "wg0", MTU: 1420},
+					},
+				},
+			},
+			out: &HandshakeResp{},
+		},
+		{
+			name: "EstablishConnection",
+			cmd:  EstablishConnectionCmd,
+			in: IPAddressWithPortProtocol{
+				IP:       net.ParseIP("fd:2::4"),
+				Port:     8080,
+				Protocol: TransportUDP,
+				Network:  Networkv6,
+			},
+			out: &IPAddressWithPortProtocol{},
+		},
+		{
+			name: "ConnectResponse",
+			cmd:  ConnectResponseCmd,
+			in:   ConnectResponse{Established: false, Reason: ReasonHostUnreachable},
+			out:  &ConnectResponse{},
+		},
+		{
+			name: "EstablishDatagramFlow",
+			cmd:  EstablishDatagramFlowCmd,
+			in: DatagramFlowRequest{
+				ID: 3,
+				Addr: IPAddressWithPortProtocol{
+					IP:       net.ParseIP("10.1.0.3"),
+					Port:     53,
+					Protocol: TransportUDP,
+					Network:  Networkv4,
+				},
+			},
+			out: &DatagramFlowRequest{},
+		},
+		{
+			name: "Echo",
+			cmd:  EchoCmd,
+			in:   EchoRequest{IP: net.ParseIP("10.1.0.3").To4(), ID: 7, Seq: 42, Data: []byte("ping")},
+			out:  &EchoRequest{},
+		},
+		{
+			name: "EchoReply",
+			cmd:  EchoReplyCmd,
+			in:   EchoReply{Reason: ReasonNone, Data: []byte("ping")},
+			out:  &EchoReply{},
+		},
+		{
+			name: "Echo IPv6",
+			cmd:  EchoCmd,
+			in:   EchoRequest{IP: net.ParseIP("fd00::3"), ID: 0xffff, Seq: 1, Data: []byte{0}},
+			out:  &EchoRequest{},
+		},
+		{
+			name: "EchoReply unreachable",
+			cmd:  EchoReplyCmd,
+			in:   EchoReply{Reason: ReasonHostUnreachable},
+			out:  &EchoReply{},
+		},
+		{
+			name: "Resolve",
+			cmd:  ResolveCmd,
+			in:   ResolveRequest{MaxSize: 4096, Query: []byte{0x12, 0x34, 0x01, 0x00}},
+			out:  &ResolveRequest{},
+		},
+		{
+			name: "ResolveReply",
+			cmd:  ResolveReplyCmd,
+			in:   ResolveReply{Response: []byte{0x12, 0x34, 0x81, 0x80}},
+			out:  &ResolveReply{},
+		},
+		{
+			name: "ReverseListen",
+			cmd:  ReverseListenCmd,
+			in:   ReverseListenRequest{ID: 2, Address: "0.0.0.0:8080"},
+			out:  &ReverseListenRequest{},
+		},
+		{
+			name: "ReverseListenResp",
+			cmd:  ReverseListenRespCmd,
+			in:   ReverseListenResp{Address: "[::]:8080", Error: ""},
+			out:  &ReverseListenResp{},
+		},
+		{
+			name: "ReverseConnect",
+			cmd:  ReverseConnectCmd,
+			in:   ReverseConnectRequest{ID: 2, RemoteAddr: "10.2.0.7:51234"},
+			out:  &ReverseConnectRequest{},
+		},
+		{
+			name: "RelayConnect",
+			cmd:  RelayConnectCmd,
+			in:   RelayConnectRequest{RemoteAddr: "10.3.0.9:40112"},
+			out:  &RelayConnectRequest{},
+		},
+		{
+			name: "RoutesUpdate",
+			cmd:  RoutesUpdateCmd,
+			in:   RoutesUpdate{Routes: []string{"10.4.0.2/24", "fd:4::2/64"}},
+			out:  &RoutesUpdate{},
+		},
+		{
+			name: "Disconnect",
+			cmd:  DisconnectCmd,
+			in:   Disconnect{Reason: "client closing down"},
+			out:  &Disconnect{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Send(&buf, tt.cmd, tt.in); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := Receive(&buf, tt.cmd, tt.out); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := reflect.ValueOf(tt.out).Elem().Interface()
+			if !reflect.DeepEqual(got, tt.in) {
+				t.Errorf("got %+v, want %+v", got, tt.in)
+			}
+		})
+	}
+}
+
+func TestReceiveUnexpectedCommand(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Send(&buf, ConnectResponseCmd, ConnectResponse{Established: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var resp HandshakeResp
+	if err := Receive(&buf, HandshakeRespCmd, &resp); err == nil {
+		t.Error("expected error but got nil")
+	}
+}
+
+func TestDecodeOlderVersions(t *testing.T) {
+	var w writer
+	w.uint16(VersionIdentity - 1)
+	w.uint32(uint32(CapDatagrams))
+
+	var req HandshakeReq
+	if err := req.UnmarshalBinary(w.buf); err != nil {
+		t.Fatalf("could not decode handshake without nonce: %v", err)
+	}
+	if req.Version != VersionIdentity-1 || req.Capabilities != CapDatagrams || req.Nonce != nil {
+		t.Errorf("got %+v", req)
+	}
+
+	w = writer{}
+	w.uint16(MinVersion)
+	w.uint32(0)
+	w.string("")
+	w.string("user@host")
+	w.strings([]string{"10.1.0.3/16"})
+	for range 4 {
+		w.string("")
+	}
+	var hs HandshakeResp
+	if err := hs.UnmarshalBinary(w.buf); err != nil {
+		t.Fatalf("could not decode handshake response of version %d: %v", MinVersion, err)
+	}
+	if hs.Name != "user@host" || hs.PublicKey != nil || hs.Host.Interfaces != nil {
+		t.Errorf("got %+v", hs)
+	}
+
+	w.bytes([]byte{1, 2})
+	w.bytes([]byte{3})
+	if err := hs.UnmarshalBinary(w.buf); err != nil {
+		t.Fatalf("could not decode handshake response without host: %v", err)
+	}
+	if !bytes.Equal(hs.PublicKey, []byte{1, 2}) || !bytes.Equal(hs.Signature, []byte{3}) || hs.Host.PID != 0 {
+		t.Errorf("got %+v", hs)
+	}
+
+	var resp ConnectResponse
+	if err := resp.UnmarshalBinary([]byte{0}); err != nil {
+		t.Fatalf("could not decode connection response without reason: %v", err)
+	}
+	if resp.Established || resp.Reason != ReasonUnknown {
+		t.Errorf("got %+v", resp)
+	}
+}
+
+func TestMarshalFieldTooLong(t *testing.T) {
+	long := strings.Repeat("a", math.MaxUint16+1)
+	tests := []struct {
+		name string
+		in   interface {
+			MarshalBinary() ([]byte, error)
+		}
+	}{
+		{"string", ReverseListenRequest{Address: long}},
+		{"bytes", ResolveReply{Response: []byte(long)}},
+		{"list", RoutesUpdate{Routes: make([]string, math.MaxUint16+1)}},
+		{"string in list", RoutesUpdate{Routes: []string{"10.0.0.1/8", long}}},
+		{"interfaces", HandshakeResp{Host: HostInfo{Interfaces: make([]Interface, math.MaxUint16+1)}}},
+	}
+	for _, tt := range tests {
+		if _, err := tt.in.MarshalBinary(); !errors.Is(err, ErrFieldTooLong) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, ErrFieldTooLong)
+		}
+	}
+
+	// The longest field that fits is sent as is.
+	in := ResolveReply{Response: []byte(long[1:])}
+	var buf bytes.Buffer
+	if err := Send(&buf, ResolveReplyCmd, in); err != nil {
+		t.Fatal(err)
+	}
+	var out ResolveReply
+	if err := Receive(&buf, ResolveReplyCmd, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Response, in.Response) {
+		t.Errorf("got a response of %d bytes, want %d", len(out.Response), len(in.Response))
+	}
+}
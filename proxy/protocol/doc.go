@@ -0,0 +1,127 @@
+// Package protocol implements the control protocol spoken between the raido
+// proxy and its agents on top of a transport stream.
+//
+// # Framing
+//
+// Every control message is sent as a single frame:
+//
+//	+-------------+------------------+--------------------+
+//	| command (1) | body length (4)  | body (body length) |
+//	+-------------+------------------+--------------------+
+//
+// All integers are big endian. Bodies are limited to MaxBodySize bytes. A
+// stream starts with exactly one request frame; once the agent has answered
+// an EstablishConnection request the stream carries raw application data,
+// any other request is answered with a single frame. When CapUDPFraming is
+// negotiated each datagram on a UDP stream is prefixed with its 2 byte
+// length, see relay.DatagramStream.
+//
+// Inside a body, strings and byte slices are encoded as a 2 byte length
+// followed by the bytes and lists of strings as a 2 byte element count
+// followed by the strings. A message with a longer field or list cannot be
+// marshaled.
+//
+// # Messages
+//
+// Fields added to a message in a later protocol version are appended to it
+// and left out by older peers, decoders only read them when the body has
+// bytes left.
+//
+// Handshake (0x01), proxy -> agent, first stream of every connection. The
+// nonce was added in VersionIdentity:
+//
+//	version (2) | capabilities (4) | nonce (bytes)
+//
+// HandshakeResp (0x02), agent -> proxy. The public key is the agent's ed25519
+// identity key, the proxy derives the agent ID from it. The signature covers
+// IdentityProof(nonce) and proves the agent holds the private key. Both were
+// added in VersionIdentity and are required from it on. From VersionHostInfo
+// on the message ends with the agent's PID, its uptime in seconds and the
+// list of its host's network interfaces:
+//
+//	version (2) | capabilities (4) | error (string) | name (string) |
+//	routes (list) | build version (string) | go version (string) |
+//	os (string) | arch (string) | public key (bytes) | signature (bytes) |
+//	pid (4) | uptime (4) | interface count (2) | interfaces
+//
+// Each interface is encoded as:
+//
+//	name (string) | mac (string) | mtu (4) | addresses (list)
+//
+// EstablishConnection (0x03), proxy -> agent, the body is an encoded
+// IPAddressWithPortProtocol:
+//
+//	network << 6 | protocol << 4 (1) | port (2) | ip (4 or 16)
+//
+// ConnectResponse (0x04), agent -> proxy, reason is one of the Reason
+// constants and is zero when the connection was established. Some agents of
+// version 2 leave it out, a failed connection then has an unknown reason:
+//
+//	established (1) | reason (1)
+//
+// Echo (0x05), proxy -> agent, requires CapICMP. The IP is 4 or 16 bytes
+// long and data is the payload of the ICMP echo request:
+//
+//	ip (bytes) | id (2) | seq (2) | data (bytes)
+//
+// EchoReply (0x06), agent -> proxy, reason is zero when the target replied:
+//
+//	reason (1) | data (bytes)
+//
+// EstablishDatagramFlow (0x07), proxy -> agent, requires CapDatagrams. Asks
+// for a UDP connection whose datagrams travel as connection datagrams
+// prefixed with the 4 byte flow ID. It is answered with ConnectResponse and
+// the stream stays open as the flow's control channel: datagrams too large for
+// the connection are sent on it length-prefixed, and closing it ends the flow.
+//
+//	flow id (4) | encoded IPAddressWithPortProtocol
+//
+// Resolve (0x08), proxy -> agent, requires CapDNS. The query is a DNS message
+// in wire format, answered by the agent's system resolver. Max size limits
+// the response, zero for the UDP limit of the query:
+//
+//	max size (2) | query (bytes)
+//
+// ResolveReply (0x09), agent -> proxy, a DNS message in wire format:
+//
+//	response (bytes)
+//
+// ReverseListen (0x0a), proxy -> agent, requires CapReverseForward. Asks the
+// agent to accept TCP connections on address for the reverse forward id. The
+// stream stays open for the lifetime of the listener, closing it on either
+// side stops the listener.
+//
+//	id (4) | address (string)
+//
+// ReverseListenResp (0x0b), agent -> proxy, address is the bound address and
+// error is empty on success:
+//
+//	address (string) | error (string)
+//
+// ReverseConnect (0x0c), agent -> proxy, on a stream opened by the agent for
+// every connection accepted by the listener id. It is answered with
+// ConnectResponse once the proxy connected to the forward's target, after
+// which the stream carries raw application data:
+//
+//	id (4) | remote address (string)
+//
+// RelayConnect (0x0d), agent -> proxy, requires CapRelay. Sent on a stream
+// opened by the agent for every downstream agent connecting to its relay
+// listener. It is answered with ConnectResponse, after which the stream
+// carries the raw bytes of the downstream agent's connection:
+//
+//	remote address (string)
+//
+// RoutesUpdate (0x0e), agent -> proxy, requires CapRouteUpdates. Sent on a
+// stream opened by the agent whenever its network addresses change. The
+// routes replace those of the handshake, the message is not answered:
+//
+//	routes (list)
+//
+// Disconnect (0x0f), agent -> proxy, requires CapDisconnect. Sent on a stream
+// opened by the agent right before it closes the connection on purpose. The
+// proxy removes the agent at once instead of waiting for it to reconnect and
+// closes the stream to acknowledge it:
+//
+//	reason (string)
+package protocol
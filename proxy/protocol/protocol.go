@@ -1,7 +1,6 @@
 package protocol
 
 import (
-	"encoding/gob"
 	"fmt"
 	"io"
 	"net"
@@ -100,74 +99,640 @@ func Decode(encoded []byte) (*IPAddressWithPortProtocol, error) {
 	}, nil
 }
 
+// MarshalBinary implements encoding.BinaryMarshaler on top of Encode.
+func (ipStruct IPAddressWithPortProtocol) MarshalBinary() ([]byte, error) {
+	return ipStruct.Encode()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler on top of Decode.
+func (ipStruct *IPAddressWithPortProtocol) UnmarshalBinary(data []byte) error {
+	decoded, err := Decode(data)
+	if err != nil {
+		return err
+	}
+
+	*ipStruct = *decoded
+	return nil
+}
+
 // Constants representing QUIC application error codes.
 const (
-	ApplicationOK = 0x0
+	ApplicationOK              = 0x0
+	ApplicationHandshakeFailed = 0x1
 )
 
+// Command identifies the message carried in the body of a frame.
+type Command uint8
+
 // Commands for communication within the VPN protocol.
 const (
-	GetRoutesReqCmd        = "GetRoutesReq"
-	EstablishConnectionCmd = "EstablishConnection"
+	HandshakeCmd Command = iota + 1
+	HandshakeRespCmd
+	EstablishConnectionCmd
+	ConnectResponseCmd
+	EchoCmd
+	EchoReplyCmd
+	EstablishDatagramFlowCmd
+	ResolveCmd
+	ResolveReplyCmd
+	ReverseListenCmd
+	ReverseListenRespCmd
+	ReverseConnectCmd
+	RelayConnectCmd
+	RoutesUpdateCmd
+	DisconnectCmd
 )
 
-// GetRoutesResp holds the name and list of routes for the VPN.
-type GetRoutesResp struct {
-	Name   string
-	Routes []string
+func (c Command) String() string {
+	switch c {
+	case HandshakeCmd:
+		return "Handshake"
+	case HandshakeRespCmd:
+		return "HandshakeResp"
+	case EstablishConnectionCmd:
+		return "EstablishConnection"
+	case ConnectResponseCmd:
+		return "ConnectResponse"
+	case EchoCmd:
+		return "Echo"
+	case EchoReplyCmd:
+		return "EchoReply"
+	case EstablishDatagramFlowCmd:
+		return "EstablishDatagramFlow"
+	case ResolveCmd:
+		return "Resolve"
+	case ResolveReplyCmd:
+		return "ResolveReply"
+	case ReverseListenCmd:
+		return "ReverseListen"
+	case ReverseListenRespCmd:
+		return "ReverseListenResp"
+	case ReverseConnectCmd:
+		return "ReverseConnect"
+	case RelayConnectCmd:
+		return "RelayConnect"
+	case RoutesUpdateCmd:
+		return "RoutesUpdate"
+	case DisconnectCmd:
+		return "Disconnect"
+	default:
+		return fmt.Sprintf("Command(%d)", uint8(c))
+	}
 }
 
-// Data represents the data structure sent over the protocol.
+// Data represents a single frame sent over the protocol.
 type Data struct {
-	Command string
+	Command Command
 	Body    []byte
 }
 
-// ConnectResponse indicates whether a connection was successfully established.
+// HandshakeReq is sent by the proxy as the first message on a new connection.
+// Nonce is a random challenge the agent signs with its identity key, it is
+// empty before VersionIdentity.
+type HandshakeReq struct {
+	Version      uint16
+	Capabilities Capability
+	Nonce        []byte
+}
+
+// NonceSize is the length of the handshake nonce.
+const NonceSize = 32
+
+func (r HandshakeReq) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.uint16(r.Version)
+	w.uint32(uint32(r.Capabilities))
+	w.bytes(r.Nonce)
+	return w.buf, w.err()
+}
+
+func (r *HandshakeReq) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.Version = rd.uint16()
+	r.Capabilities = Capability(rd.uint32())
+	if rd.more() {
+		r.Nonce = rd.bytes()
+	}
+	return rd.err()
+}
+
+// IdentityProof returns the message an agent signs to prove it holds the
+// identity key for the handshake nonce.
+func IdentityProof(nonce []byte) []byte {
+	return append([]byte("raido agent identity\x00"), nonce...)
+}
+
+// HandshakeResp is the agent's answer to HandshakeReq. A non-empty Error means
+// the agent refused the connection. PublicKey is the agent's ed25519 identity
+// key and Signature its signature of IdentityProof(nonce), both are empty
+// before VersionIdentity.
+type HandshakeResp struct {
+	Version      uint16
+	Capabilities Capability
+	Error        string
+	Name         string
+	Routes       []string
+	Build        BuildInfo
+	PublicKey    []byte
+	Signature    []byte
+	Host         HostInfo
+}
+
+// BuildInfo describes the binary running on the other side of the connection.
+type BuildInfo struct {
+	Version   string
+	GoVersion string
+	OS        string
+	Arch      string
+}
+
+// HostInfo describes the agent process and the network interfaces of its
+// host. Uptime is the number of seconds the process has been running. It is
+// empty before VersionHostInfo.
+type HostInfo struct {
+	PID        uint32
+	Uptime     uint32
+	Interfaces []Interface
+}
+
+// Interface is a network interface of the agent's host.
+type Interface struct {
+	Name  string
+	MAC   string
+	MTU   uint32
+	Addrs []string
+}
+
+func (r HandshakeResp) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.uint16(r.Version)
+	w.uint32(uint32(r.Capabilities))
+	w.string(r.Error)
+	w.string(r.Name)
+	w.strings(r.Routes)
+	w.string(r.Build.Version)
+	w.string(r.Build.GoVersion)
+	w.string(r.Build.OS)
+	w.string(r.Build.Arch)
+	w.bytes(r.PublicKey)
+	w.bytes(r.Signature)
+	w.uint32(r.Host.PID)
+	w.uint32(r.Host.Uptime)
+	if w.length(len(r.Host.Interfaces)) {
+		for _, iface := range r.Host.Interfaces {
+			w.string(iface.Name)
+			w.string(iface.MAC)
+			w.uint32(iface.MTU)
+			w.strings(iface.Addrs)
+		}
+	}
+	return w.buf, w.err()
+}
+
+func (r *HandshakeResp) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.Version = rd.uint16()
+	r.Capabilities = Capability(rd.uint32())
+	r.Error = rd.string()
+	r.Name = rd.string()
+	r.Routes = rd.strings()
+	r.Build.Version = rd.string()
+	r.Build.GoVersion = rd.string()
+	r.Build.OS = rd.string()
+	r.Build.Arch = rd.string()
+	if rd.more() {
+		r.PublicKey = rd.bytes()
+		r.Signature = rd.bytes()
+	}
+	r.Host = HostInfo{}
+	if !rd.more() {
+		return rd.err()
+	}
+	r.Host.PID = rd.uint32()
+	r.Host.Uptime = rd.uint32()
+	for range rd.uint16() {
+		iface := Interface{
+			Name: rd.string(),
+			MAC:  rd.string(),
+			MTU:  rd.uint32(),
+		}
+		iface.Addrs = rd.strings()
+		if rd.err() != nil {
+			break
+		}
+		r.Host.Interfaces = append(r.Host.Interfaces, iface)
+	}
+	return rd.err()
+}
+
+// Reason tells why the agent could not establish a connection.
+type Reason uint8
+
+const (
+	ReasonNone Reason = iota
+	ReasonUnknown
+	ReasonRefused
+	ReasonTimeout
+	ReasonHostUnreachable
+	ReasonNetUnreachable
+	ReasonDenied
+)
+
+func (r Reason) String() string {
+	switch r {
+	case ReasonNone:
+		return "none"
+	case ReasonRefused:
+		return "connection refused"
+	case ReasonTimeout:
+		return "timeout"
+	case ReasonHostUnreachable:
+		return "host unreachable"
+	case ReasonNetUnreachable:
+		return "network unreachable"
+	case ReasonDenied:
+		return "denied by agent ACL"
+	default:
+		return "unknown"
+	}
+}
+
+// ConnectResponse indicates whether a connection was successfully established
+// and, if not, why. Some agents leave the reason out, it is then decoded as
+// ReasonUnknown for a failed connection.
 type ConnectResponse struct {
 	Established bool
+	Reason      Reason
+}
+
+func (r ConnectResponse) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.uint8(map[bool]uint8{true: 1, false: 0}[r.Established])
+	w.uint8(uint8(r.Reason))
+	return w.buf, w.err()
+}
+
+func (r *ConnectResponse) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.Established = rd.uint8() == 1
+	switch {
+	case rd.more():
+		r.Reason = Reason(rd.uint8())
+	case !r.Established:
+		r.Reason = ReasonUnknown
+	}
+	return rd.err()
+}
+
+// EstablishConnection asks the agent on the other side of rw to connect to
+// addr and waits for its answer.
+func EstablishConnection(rw io.ReadWriter, addr IPAddressWithPortProtocol) (ConnectResponse, error) {
+	if err := Send(rw, EstablishConnectionCmd, addr); err != nil {
+		return ConnectResponse{}, fmt.Errorf("could not send connection establishment data: %w", err)
+	}
+
+	var resp ConnectResponse
+	if err := Receive(rw, ConnectResponseCmd, &resp); err != nil {
+		return ConnectResponse{}, fmt.Errorf("could not decode connection establishment response: %w", err)
+	}
+
+	return resp, nil
 }
 
-// Decoder wraps the gob decoder for a specific type.
-type Decoder[T any] struct {
-	dec *gob.Decoder
-	r   io.Closer
+// DatagramFlowRequest asks the agent to connect to a UDP address and to
+// exchange its datagrams over the connection's datagrams with the given flow
+// ID. The stream that carried the request stays open for the lifetime of the
+// flow and carries the datagrams that are too large for the connection.
+type DatagramFlowRequest struct {
+	ID   uint32
+	Addr IPAddressWithPortProtocol
 }
 
-// NewDecoder initializes a new Decoder for type T.
-func NewDecoder[T any](rd io.ReadCloser) Decoder[T] {
-	return Decoder[T]{dec: gob.NewDecoder(rd), r: rd}
+func (r DatagramFlowRequest) MarshalBinary() ([]byte, error) {
+	addr, err := r.Addr.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	var w writer
+	w.uint32(r.ID)
+	w.buf = append(w.buf, addr...)
+	return w.buf, w.err()
+}
+
+func (r *DatagramFlowRequest) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.ID = rd.uint32()
+	if err := rd.err(); err != nil {
+		return err
+	}
+	return r.Addr.UnmarshalBinary(rd.buf)
 }
 
-// Decode decodes the next value from the stream.
-func (d Decoder[T]) Decode() (T, error) {
-	var t T
-	err := d.dec.Decode(&t)
-	return t, err
+// EstablishDatagramFlow is the datagram counterpart of EstablishConnection.
+func EstablishDatagramFlow(rw io.ReadWriter, req DatagramFlowRequest) (ConnectResponse, error) {
+	if err := Send(rw, EstablishDatagramFlowCmd, req); err != nil {
+		return ConnectResponse{}, fmt.Errorf("could not send datagram flow establishment data: %w", err)
+	}
+
+	var resp ConnectResponse
+	if err := Receive(rw, ConnectResponseCmd, &resp); err != nil {
+		return ConnectResponse{}, fmt.Errorf("could not decode datagram flow establishment response: %w", err)
+	}
+
+	return resp, nil
+}
+
+// EchoRequest asks the agent to send an ICMP echo request to IP and wait for
+// the reply.
+type EchoRequest struct {
+	IP   net.IP
+	ID   uint16
+	Seq  uint16
+	Data []byte
+}
+
+func (r EchoRequest) MarshalBinary() ([]byte, error) {
+	ip := r.IP.To4()
+	if ip == nil {
+		ip = r.IP.To16()
+	}
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address")
+	}
+
+	var w writer
+	w.bytes(ip)
+	w.uint16(r.ID)
+	w.uint16(r.Seq)
+	w.bytes(r.Data)
+	return w.buf, w.err()
+}
+
+func (r *EchoRequest) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.IP = net.IP(rd.bytes())
+	r.ID = rd.uint16()
+	r.Seq = rd.uint16()
+	r.Data = rd.bytes()
+	if err := rd.err(); err != nil {
+		return err
+	}
+	if len(r.IP) != net.IPv4len && len(r.IP) != net.IPv6len {
+		return fmt.Errorf("invalid IP address length %d", len(r.IP))
+	}
+	return nil
+}
+
+// EchoReply is the agent's answer to EchoRequest. Reason is ReasonNone when
+// the target replied, in which case Data holds the echoed payload.
+type EchoReply struct {
+	Reason Reason
+	Data   []byte
+}
+
+func (r EchoReply) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.uint8(uint8(r.Reason))
+	w.bytes(r.Data)
+	return w.buf, w.err()
+}
+
+func (r *EchoReply) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.Reason = Reason(rd.uint8())
+	r.Data = rd.bytes()
+	return rd.err()
+}
+
+// Echo asks the agent on the other side of rw to ping req.IP and waits for
+// its answer.
+func Echo(rw io.ReadWriter, req EchoRequest) (EchoReply, error) {
+	if err := Send(rw, EchoCmd, req); err != nil {
+		return EchoReply{}, fmt.Errorf("could not send echo request: %w", err)
+	}
+
+	var resp EchoReply
+	if err := Receive(rw, EchoReplyCmd, &resp); err != nil {
+		return EchoReply{}, fmt.Errorf("could not decode echo reply: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ResolveRequest carries a DNS query for the agent's system resolver.
+// MaxSize limits the size of the response, zero selects the UDP limit
+// announced in the query.
+type ResolveRequest struct {
+	MaxSize uint16
+	Query   []byte
+}
+
+func (r ResolveRequest) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.uint16(r.MaxSize)
+	w.bytes(r.Query)
+	return w.buf, w.err()
+}
+
+func (r *ResolveRequest) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.MaxSize = rd.uint16()
+	r.Query = rd.bytes()
+	return rd.err()
+}
+
+// ResolveReply carries the DNS response built by the agent for a
+// ResolveRequest.
+type ResolveReply struct {
+	Response []byte
+}
+
+func (r ResolveReply) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.bytes(r.Response)
+	return w.buf, w.err()
+}
+
+func (r *ResolveReply) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.Response = rd.bytes()
+	return rd.err()
+}
+
+// Resolve sends a DNS query to the agent on the other side of rw and waits for
+// the DNS response.
+func Resolve(rw io.ReadWriter, req ResolveRequest) ([]byte, error) {
+	if err := Send(rw, ResolveCmd, req); err != nil {
+		return nil, fmt.Errorf("could not send resolve request: %w", err)
+	}
+
+	var resp ResolveReply
+	if err := Receive(rw, ResolveReplyCmd, &resp); err != nil {
+		return nil, fmt.Errorf("could not decode resolve reply: %w", err)
+	}
+
+	return resp.Response, nil
+}
+
+// ReverseListenRequest asks the agent to listen for TCP connections on
+// Address and to carry each of them back to the proxy tagged with ID.
+type ReverseListenRequest struct {
+	ID      uint32
+	Address string
+}
+
+func (r ReverseListenRequest) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.uint32(r.ID)
+	w.string(r.Address)
+	return w.buf, w.err()
+}
+
+func (r *ReverseListenRequest) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.ID = rd.uint32()
+	r.Address = rd.string()
+	return rd.err()
+}
+
+// ReverseListenResp is the agent's answer to ReverseListenRequest. Address is
+// the address the listener is bound to, Error is set if it could not listen.
+type ReverseListenResp struct {
+	Address string
+	Error   string
+}
+
+func (r ReverseListenResp) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.string(r.Address)
+	w.string(r.Error)
+	return w.buf, w.err()
+}
+
+func (r *ReverseListenResp) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.Address = rd.string()
+	r.Error = rd.string()
+	return rd.err()
+}
+
+// ReverseListen asks the agent on the other side of rw to open a reverse
+// forward listener and waits for its answer.
+func ReverseListen(rw io.ReadWriter, req ReverseListenRequest) (ReverseListenResp, error) {
+	if err := Send(rw, ReverseListenCmd, req); err != nil {
+		return ReverseListenResp{}, fmt.Errorf("could not send reverse listen request: %w", err)
+	}
+
+	var resp ReverseListenResp
+	if err := Receive(rw, ReverseListenRespCmd, &resp); err != nil {
+		return ReverseListenResp{}, fmt.Errorf("could not decode reverse listen response: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ReverseConnectRequest is sent by the agent for every connection accepted
+// by the reverse forward listener ID. RemoteAddr is the address of the peer.
+type ReverseConnectRequest struct {
+	ID         uint32
+	RemoteAddr string
+}
+
+func (r ReverseConnectRequest) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.uint32(r.ID)
+	w.string(r.RemoteAddr)
+	return w.buf, w.err()
+}
+
+func (r *ReverseConnectRequest) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.ID = rd.uint32()
+	r.RemoteAddr = rd.string()
+	return rd.err()
+}
+
+// ReverseConnect hands a connection accepted by a reverse forward listener to
+// the proxy on the other side of rw and waits until it reached the target.
+func ReverseConnect(rw io.ReadWriter, req ReverseConnectRequest) (ConnectResponse, error) {
+	if err := Send(rw, ReverseConnectCmd, req); err != nil {
+		return ConnectResponse{}, fmt.Errorf("could not send reverse connect request: %w", err)
+	}
+
+	var resp ConnectResponse
+	if err := Receive(rw, ConnectResponseCmd, &resp); err != nil {
+		return ConnectResponse{}, fmt.Errorf("could not decode connection establishment response: %w", err)
+	}
+
+	return resp, nil
+}
+
+// RelayConnectRequest is sent by the agent for every downstream agent that
+// connects to its relay listener. RemoteAddr is the downstream agent's address.
+type RelayConnectRequest struct {
+	RemoteAddr string
+}
+
+func (r RelayConnectRequest) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.string(r.RemoteAddr)
+	return w.buf, w.err()
+}
+
+func (r *RelayConnectRequest) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.RemoteAddr = rd.string()
+	return rd.err()
+}
+
+// RelayConnect hands the connection of a downstream agent to the proxy on the
+// other side of rw and waits until the proxy accepted it.
+func RelayConnect(rw io.ReadWriter, req RelayConnectRequest) (ConnectResponse, error) {
+	if err := Send(rw, RelayConnectCmd, req); err != nil {
+		return ConnectResponse{}, fmt.Errorf("could not send relay connect request: %w", err)
+	}
+
+	var resp ConnectResponse
+	if err := Receive(rw, ConnectResponseCmd, &resp); err != nil {
+		return ConnectResponse{}, fmt.Errorf("could not decode connection establishment response: %w", err)
+	}
+
+	return resp, nil
+}
+
+// RoutesUpdate is sent by the agent when the networks it is attached to
+// changed. Routes replaces the routes sent in HandshakeResp.
+type RoutesUpdate struct {
+	Routes []string
 }
 
-// Close closes the underlying reader.
-func (d Decoder[T]) Close() error {
-	return d.r.Close()
+func (r RoutesUpdate) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.strings(r.Routes)
+	return w.buf, w.err()
 }
 
-// Encoder wraps the gob encoder for a specific type.
-type Encoder[T any] struct {
-	enc *gob.Encoder
-	w   io.Closer
+func (r *RoutesUpdate) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.Routes = rd.strings()
+	return rd.err()
 }
 
-// NewEncoder initializes a new Encoder for type T.
-func NewEncoder[T any](wr io.WriteCloser) Encoder[T] {
-	return Encoder[T]{enc: gob.NewEncoder(wr), w: wr}
+// Disconnect is sent by the agent before it closes its connection on purpose,
+// so the proxy does not wait for it to reconnect.
+type Disconnect struct {
+	Reason string
 }
 
-// Encode encodes the provided value to the stream.
-func (e Encoder[T]) Encode(t T) error {
-	return e.enc.Encode(&t)
+func (r Disconnect) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.string(r.Reason)
+	return w.buf, w.err()
 }
 
-// Close closes the underlying writer.
-func (e Encoder[T]) Close() error {
-	return e.w.Close()
+func (r *Disconnect) UnmarshalBinary(data []byte) error {
+	rd := reader{buf: data}
+	r.Reason = rd.string()
+	return rd.err()
 }
@@ -0,0 +1,111 @@
+package protocol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Protocol versions spoken by this build. Peers negotiate the lower of the two
+// advertised versions and refuse the connection if it drops below MinVersion.
+const (
+	Version    uint16 = 5
+	MinVersion uint16 = 2
+)
+
+const (
+	// VersionIdentity is the first version whose handshake carries the nonce
+	// and the agent's identity proof.
+	VersionIdentity uint16 = 4
+	// VersionHostInfo is the first version whose handshake response describes
+	// the agent's host.
+	VersionHostInfo uint16 = 5
+)
+
+// Capability is a bit set of optional protocol features. A feature is only
+// used on a connection when both the proxy and the agent advertise it.
+type Capability uint32
+
+const (
+	CapDatagrams Capability = 1 << iota
+	CapReverseForward
+	CapICMP
+	CapDNS
+	CapRelay
+	CapRouteUpdates
+	CapUDPFraming
+	CapDisconnect
+)
+
+// SupportedCapabilities holds every capability implemented by this build.
+const SupportedCapabilities = CapDatagrams | CapReverseForward | CapICMP | CapDNS | CapRelay | CapRouteUpdates | CapUDPFraming | CapDisconnect
+
+var capabilityNames = []struct {
+	cap  Capability
+	name string
+}{
+	{CapDatagrams, "datagrams"},
+	{CapReverseForward, "reverse-forward"},
+	{CapICMP, "icmp"},
+	{CapDNS, "dns"},
+	{CapRelay, "relay"},
+	{CapRouteUpdates, "route-updates"},
+	{CapUDPFraming, "udp-framing"},
+	{CapDisconnect, "disconnect"},
+}
+
+// Has reports whether all capabilities in o are set in c.
+func (c Capability) Has(o Capability) bool {
+	return c&o == o
+}
+
+func (c Capability) String() string {
+	var names []string
+	for _, n := range capabilityNames {
+		if c.Has(n.cap) {
+			names = append(names, n.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseCapabilities parses a comma separated list of capability names as
+// produced by Capability.String.
+func ParseCapabilities(s string) (Capability, error) {
+	var c Capability
+	for name := range strings.SplitSeq(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "none" {
+			continue
+		}
+		if name == "all" {
+			c |= SupportedCapabilities
+			continue
+		}
+
+		found := false
+		for _, n := range capabilityNames {
+			if n.name == name {
+				c |= n.cap
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown capability \"%s\"", name)
+		}
+	}
+	return c, nil
+}
+
+// NegotiateVersion returns the version both peers speak, or an error if the
+// peer is too old for this build.
+func NegotiateVersion(peer uint16) (uint16, error) {
+	v := min(peer, Version)
+	if v < MinVersion {
+		return 0, fmt.Errorf("unsupported protocol version %d, minimum is %d", peer, MinVersion)
+	}
+	return v, nil
+}
@@ -0,0 +1,46 @@
+package protocol
+
+import "testing"
+
+func TestNegotiateVersion(t *testing.T) {
+	tests := []struct {
+		peer    uint16
+		want    uint16
+		wantErr bool
+	}{
+		{Version + 1, Version, false},
+		{Version, Version, false},
+		{MinVersion, MinVersion, false},
+		{MinVersion - 1, 0, true},
+		{0, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := NegotiateVersion(tt.peer)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NegotiateVersion(%d) = %d, %v, want %d, error %t", tt.peer, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	c, err := ParseCapabilities("icmp, dns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != CapICMP|CapDNS || c.String() != "icmp,dns" {
+		t.Errorf("got %s", c)
+	}
+	if !c.Has(CapDNS) || c.Has(CapDNS|CapRelay) {
+		t.Errorf("Has on %s is wrong", c)
+	}
+
+	if c, _ := ParseCapabilities("all"); c != SupportedCapabilities {
+		t.Errorf("all parsed as %s", c)
+	}
+	if c, _ := ParseCapabilities("none"); c != 0 || c.String() != "none" {
+		t.Errorf("none parsed as %s", c)
+	}
+	if _, err := ParseCapabilities("teleport"); err == nil {
+		t.Error("parsed an unknown capability")
+	}
+}
@@ -0,0 +1,96 @@
+package relay
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+)
+
+// MaxDatagramSize is the largest payload a DatagramStream can carry.
+const MaxDatagramSize = math.MaxUint16
+
+var ErrDatagramTooLarge = errors.New("datagram too large")
+
+// DatagramStream preserves message boundaries on top of a byte stream. Every
+// Write is sent as a single datagram prefixed with its 2 byte big endian
+// length and every Read returns exactly one datagram.
+type DatagramStream struct {
+	rw  io.ReadWriteCloser
+	rmu sync.Mutex
+	wmu sync.Mutex
+	hdr [2]byte
+}
+
+func NewDatagramStream(rw io.ReadWriteCloser) *DatagramStream {
+	return &DatagramStream{rw: rw}
+}
+
+// NewUDPStream returns the stream of a UDP connection. Datagrams keep their
+// boundaries when both peers negotiated protocol.CapUDPFraming, older peers
+// relay them as a plain byte stream.
+func NewUDPStream(rw io.ReadWriteCloser, caps protocol.Capability) io.ReadWriteCloser {
+	if caps.Has(protocol.CapUDPFraming) {
+		return NewDatagramStream(rw)
+	}
+	return rw
+}
+
+// Read reads the next datagram into p. Like a UDP socket, the part of the
+// datagram that does not fit into p is discarded.
+func (s *DatagramStream) Read(p []byte) (int, error) {
+	s.rmu.Lock()
+	defer s.rmu.Unlock()
+
+	if _, err := io.ReadFull(s.rw, s.hdr[:]); err != nil {
+		return 0, err
+	}
+	size := int(binary.BigEndian.Uint16(s.hdr[:]))
+
+	n, err := io.ReadFull(s.rw, p[:min(size, len(p))])
+	if err != nil {
+		return n, unexpectedEOF(err)
+	}
+	if size > n {
+		if _, err := io.CopyN(io.Discard, s.rw, int64(size-n)); err != nil {
+			return n, unexpectedEOF(err)
+		}
+	}
+
+	return n, nil
+}
+
+// Write sends p as a single datagram.
+func (s *DatagramStream) Write(p []byte) (int, error) {
+	if len(p) > MaxDatagramSize {
+		return 0, fmt.Errorf("%w: %d bytes", ErrDatagramTooLarge, len(p))
+	}
+
+	buf := make([]byte, 2, 2+len(p))
+	binary.BigEndian.PutUint16(buf, uint16(len(p)))
+	buf = append(buf, p...)
+
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+
+	if _, err := s.rw.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *DatagramStream) Close() error {
+	return s.rw.Close()
+}
+
+// unexpectedEOF reports a stream that ends in the middle of a datagram.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package relay
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+)
+
+type bufferStream struct {
+	bytes.Buffer
+}
+
+func (b *bufferStream) Close() error {
+	return nil
+}
+
+func TestDatagramStreamRoundTrip(t *testing.T) {
+	datagrams := [][]byte{
+		[]byte("first"),
+		{},
+		bytes.Repeat([]byte("x"), MaxDatagramSize),
+		[]byte("last"),
+	}
+
+	var buf bufferStream
+	s := NewDatagramStream(&buf)
+	for _, d := range datagrams {
+		if _, err := s.Write(d); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	p := make([]byte, MaxDatagramSize)
+	for _, want := range datagrams {
+		n, err := s.Read(p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(p[:n], want) {
+			t.Errorf("got datagram of %d bytes, want %d bytes", n, len(want))
+		}
+	}
+
+	if _, err := s.Read(p); !errors.Is(err, io.EOF) {
+		t.Errorf("got error %v, want %v", err, io.EOF)
+	}
+}
+
+func TestDatagramStreamTruncate(t *testing.T) {
+	var buf bufferStream
+	s := NewDatagramStream(&buf)
+	s.Write([]byte("truncated datagram"))
+	s.Write([]byte("next"))
+
+	p := make([]byte, 9)
+	n, err := s.Read(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(p[:n]) != "truncated" {
+		t.Errorf("got %q, want %q", p[:n], "truncated")
+	}
+
+	n, err = s.Read(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(p[:n]) != "next" {
+		t.Errorf("got %q, want %q", p[:n], "next")
+	}
+}
+
+func TestDatagramStreamErrors(t *testing.T) {
+	s := NewDatagramStream(&bufferStream{})
+	if _, err := s.Write(make([]byte, MaxDatagramSize+1)); !errors.Is(err, ErrDatagramTooLarge) {
+		t.Errorf("got error %v, want %v", err, ErrDatagramTooLarge)
+	}
+
+	var buf bufferStream
+	buf.Write([]byte{0, 10, 1, 2, 3})
+	if _, err := NewDatagramStream(&buf).Read(make([]byte, 16)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got error %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestNewUDPStream(t *testing.T) {
+	var buf bufferStream
+	if _, ok := NewUDPStream(&buf, protocol.CapUDPFraming).(*DatagramStream); !ok {
+		t.Error("datagrams are not framed with CapUDPFraming")
+	}
+	if s := NewUDPStream(&buf, protocol.SupportedCapabilities&^protocol.CapUDPFraming); s != io.ReadWriteCloser(&buf) {
+		t.Error("datagrams are framed without CapUDPFraming")
+	}
+}
@@ -1,11 +1,15 @@
 package relay
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net"
+	"os"
 	"strings"
 	"syscall"
+
+	"github.com/fr13n8/raido/proxy/protocol"
 )
 
 var (
@@ -65,3 +69,50 @@ func IsHostResponded(err error) bool {
 	}
 	return false
 }
+
+// IsConnectionRefused returns true if the remote host actively refused the connection.
+func IsConnectionRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// IsHostUnreachable returns true if the error indicates that the remote host
+// could not be reached, e.g. because nobody answered ARP/NDP requests.
+func IsHostUnreachable(err error) bool {
+	return errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.EHOSTDOWN)
+}
+
+// IsNetworkUnreachable returns true if there is no route to the remote network.
+func IsNetworkUnreachable(err error) bool {
+	return errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.EADDRNOTAVAIL)
+}
+
+// IsTimeout returns true if the error was caused by a timeout.
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, syscall.ETIMEDOUT) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// ConnectReason maps a dial error to the reason reported to the other side
+// of the tunnel.
+func ConnectReason(err error) protocol.Reason {
+	switch {
+	case IsConnectionRefused(err):
+		return protocol.ReasonRefused
+	case IsHostUnreachable(err):
+		return protocol.ReasonHostUnreachable
+	case IsNetworkUnreachable(err):
+		return protocol.ReasonNetUnreachable
+	case IsTimeout(err):
+		return protocol.ReasonTimeout
+	default:
+		return protocol.ReasonUnknown
+	}
+}
@@ -1,9 +1,11 @@
 package relay
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net"
+	"os"
 	"syscall"
 	"testing"
 )
@@ -160,3 +162,69 @@ func TestIsHostResponded(t *testing.T) {
 		})
 	}
 }
+
+func TestDialErrorClassification(t *testing.T) {
+	opErr := func(err error) error {
+		return &net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: err}}
+	}
+
+	tests := []struct {
+		name            string
+		err             error
+		refused         bool
+		hostUnreachable bool
+		netUnreachable  bool
+		timeout         bool
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+		},
+		{
+			name:    "connection refused",
+			err:     opErr(syscall.ECONNREFUSED),
+			refused: true,
+		},
+		{
+			name:            "host unreachable",
+			err:             opErr(syscall.EHOSTUNREACH),
+			hostUnreachable: true,
+		},
+		{
+			name:           "network unreachable",
+			err:            opErr(syscall.ENETUNREACH),
+			netUnreachable: true,
+		},
+		{
+			name:    "connect timeout",
+			err:     opErr(syscall.ETIMEDOUT),
+			timeout: true,
+		},
+		{
+			name:    "context deadline",
+			err:     &net.OpError{Op: "dial", Net: "tcp", Err: context.DeadlineExceeded},
+			timeout: true,
+		},
+		{
+			name: "other error",
+			err:  errors.New("some other error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsConnectionRefused(tt.err); got != tt.refused {
+				t.Errorf("IsConnectionRefused() = %v, want %v", got, tt.refused)
+			}
+			if got := IsHostUnreachable(tt.err); got != tt.hostUnreachable {
+				t.Errorf("IsHostUnreachable() = %v, want %v", got, tt.hostUnreachable)
+			}
+			if got := IsNetworkUnreachable(tt.err); got != tt.netUnreachable {
+				t.Errorf("IsNetworkUnreachable() = %v, want %v", got, tt.netUnreachable)
+			}
+			if got := IsTimeout(tt.err); got != tt.timeout {
+				t.Errorf("IsTimeout() = %v, want %v", got, tt.timeout)
+			}
+		})
+	}
+}
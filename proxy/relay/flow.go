@@ -0,0 +1,65 @@
+package relay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/fr13n8/raido/proxy/transport"
+)
+
+// PipeDatagramFlow relays datagrams between conn and a datagram flow. The
+// flow's control stream carries the datagrams that are too large for the
+// connection, and closing it on either side ends the flow.
+func PipeDatagramFlow(conn io.ReadWriteCloser, flow *transport.DatagramFlow, stream io.ReadWriteCloser) error {
+	fallback := NewDatagramStream(stream)
+	closeAll := sync.OnceFunc(func() {
+		conn.Close()
+		flow.Close()
+		fallback.Close()
+	})
+
+	errChan := make(chan error, 3)
+	var wg sync.WaitGroup
+	relay := func(dst io.Writer, src io.Reader, dir string) {
+		defer wg.Done()
+		defer closeAll()
+
+		if _, err := Copy(dst, src); err != nil && !IsOKNetworkError(err) {
+			errChan <- fmt.Errorf("%s: %w", dir, err)
+		}
+	}
+
+	wg.Add(3)
+	go relay(&flowWriter{flow: flow, fallback: fallback}, conn, "conn->flow")
+	go relay(conn, flow, "flow->conn")
+	go relay(conn, fallback, "stream->conn")
+	wg.Wait()
+	close(errChan)
+
+	var errs []error
+	for err := range errChan {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("errors during datagram flow copy: %v", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// flowWriter sends datagrams on the flow and falls back to the stream for
+// datagrams the connection cannot carry.
+type flowWriter struct {
+	flow     *transport.DatagramFlow
+	fallback *DatagramStream
+}
+
+func (w *flowWriter) Write(p []byte) (int, error) {
+	n, err := w.flow.Write(p)
+	if errors.Is(err, transport.ErrDatagramTooLarge) {
+		return w.fallback.Write(p)
+	}
+	return n, err
+}
@@ -0,0 +1,43 @@
+package relay
+
+import (
+	"io"
+	"time"
+)
+
+// IdleConn closes the wrapped connection once no data was read or written
+// for the idle timeout.
+type IdleConn struct {
+	io.ReadWriteCloser
+	timeout time.Duration
+	timer   *time.Timer
+}
+
+func NewIdleConn(conn io.ReadWriteCloser, timeout time.Duration) *IdleConn {
+	c := &IdleConn{ReadWriteCloser: conn, timeout: timeout}
+	c.timer = time.AfterFunc(timeout, func() {
+		conn.Close()
+	})
+	return c
+}
+
+func (c *IdleConn) Read(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Read(p)
+	if n > 0 {
+		c.timer.Reset(c.timeout)
+	}
+	return n, err
+}
+
+func (c *IdleConn) Write(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Write(p)
+	if n > 0 {
+		c.timer.Reset(c.timeout)
+	}
+	return n, err
+}
+
+func (c *IdleConn) Close() error {
+	c.timer.Stop()
+	return c.ReadWriteCloser.Close()
+}
@@ -0,0 +1,32 @@
+package relay
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestIdleConnCloses(t *testing.T) {
+	a, b := net.Pipe()
+	defer b.Close()
+
+	c := NewIdleConn(a, 50*time.Millisecond)
+	go b.Write([]byte("x"))
+	if _, err := c.Read(make([]byte, 1)); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Read(make([]byte, 1))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected an error after the idle timeout")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("connection was not closed after the idle timeout")
+	}
+}
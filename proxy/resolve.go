@@ -0,0 +1,309 @@
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// resolverTTL is the TTL of answers, the system resolver does not
+	// report the TTL of the records it returns.
+	resolverTTL = 60
+	// maxUDPResponseSize is the response size allowed for queries without
+	// an EDNS(0) record.
+	maxUDPResponseSize = 512
+)
+
+// handleResolve answers a resolve request. Every request gets a reply, the
+// proxy waits for it.
+func (d *Dialer) handleResolve(ctx context.Context, sess *session, stream transport.Stream, dec protocol.Data) {
+	var req protocol.ResolveRequest
+	var resp []byte
+	var err error
+	if err = req.UnmarshalBinary(dec.Body); err != nil {
+		log.Error().Err(err).Msg("could not decode resolve request")
+		resp, err = errorResponse(nil, dnsmessage.RCodeFormatError)
+	} else {
+		ctx, cancel := context.WithTimeout(ctx, config.ResolveTimeout)
+		resp, err = resolve(ctx, req, sess.capabilities().Has(protocol.CapDNS))
+		cancel()
+		if err != nil {
+			log.Error().Err(err).Msg("could not answer DNS query")
+			resp, err = errorResponse(req.Query, dnsmessage.RCodeServerFailure)
+		}
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("could not build DNS response")
+		return
+	}
+
+	if err := protocol.Send(stream, protocol.ResolveReplyCmd, protocol.ResolveReply{Response: resp}); err != nil {
+		log.Error().Err(err).Msg("could not encode resolve reply")
+	}
+}
+
+// resolve answers a DNS query with the system resolver. Queries are refused
+// when allowed is false.
+func resolve(ctx context.Context, req protocol.ResolveRequest, allowed bool) ([]byte, error) {
+	var p dnsmessage.Parser
+	h, err := p.Start(req.Query)
+	if err != nil {
+		return errorResponse(req.Query, dnsmessage.RCodeFormatError)
+	}
+	questions, err := p.AllQuestions()
+	if err != nil {
+		return buildResponse(h, nil, nil, dnsmessage.RCodeFormatError, maxUDPResponseSize)
+	}
+
+	maxSize := maxUDPResponseSize
+	if req.MaxSize != 0 {
+		maxSize = int(req.MaxSize)
+	} else if err := p.SkipAllAnswers(); err == nil {
+		if err := p.SkipAllAuthorities(); err == nil {
+			if additionals, err := p.AllAdditionals(); err == nil {
+				for _, r := range additionals {
+					if r.Header.Type == dnsmessage.TypeOPT {
+						maxSize = max(maxSize, int(r.Header.Class))
+					}
+				}
+			}
+		}
+	}
+
+	switch {
+	case !allowed:
+		return buildResponse(h, questions, nil, dnsmessage.RCodeRefused, maxSize)
+	case h.OpCode != 0 || len(questions) != 1:
+		return buildResponse(h, questions, nil, dnsmessage.RCodeNotImplemented, maxSize)
+	}
+
+	q := questions[0]
+	answers, err := lookup(ctx, q)
+	rcode := dnsmessage.RCodeSuccess
+	if err != nil {
+		rcode = lookupRCode(err)
+		log.Debug().Err(err).Stringer("rcode", rcode).Msgf("could not resolve %s %s", q.Type, q.Name)
+	}
+
+	return buildResponse(h, questions, answers, rcode, maxSize)
+}
+
+// lookup resolves a single question into answer records.
+func lookup(ctx context.Context, q dnsmessage.Question) ([]dnsmessage.Resource, error) {
+	r := net.DefaultResolver
+	name := q.Name.String()
+	hdr := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: resolverTTL}
+
+	var answers []dnsmessage.Resource
+	add := func(t dnsmessage.Type, body dnsmessage.ResourceBody) {
+		h := hdr
+		h.Type = t
+		answers = append(answers, dnsmessage.Resource{Header: h, Body: body})
+	}
+
+	switch q.Type {
+	case dnsmessage.TypeA, dnsmessage.TypeAAAA:
+		network := map[bool]string{true: "ip4", false: "ip6"}[q.Type == dnsmessage.TypeA]
+		ips, err := r.LookupIP(ctx, network, name)
+		if err != nil {
+			// The name exists but has no address of this family.
+			if isNotFound(err) {
+				if _, herr := r.LookupHost(ctx, name); herr == nil {
+					return nil, nil
+				}
+			}
+			return nil, err
+		}
+		for _, ip := range ips {
+			if ip4 := ip.To4(); ip4 != nil {
+				add(dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte(ip4)})
+			} else {
+				add(dnsmessage.TypeAAAA, &dnsmessage.AAAAResource{AAAA: [16]byte(ip.To16())})
+			}
+		}
+	case dnsmessage.TypeCNAME:
+		cname, err := r.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if cname != name {
+			target, err := dnsmessage.NewName(cname)
+			if err != nil {
+				return nil, err
+			}
+			add(dnsmessage.TypeCNAME, &dnsmessage.CNAMEResource{CNAME: target})
+		}
+	case dnsmessage.TypePTR:
+		ip := reverseAddress(name)
+		if ip == nil {
+			return nil, &net.DNSError{Err: "invalid reverse name", Name: name, IsNotFound: true}
+		}
+		names, err := r.LookupAddr(ctx, ip.String())
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range names {
+			ptr, err := dnsmessage.NewName(fqdn(n))
+			if err != nil {
+				continue
+			}
+			add(dnsmessage.TypePTR, &dnsmessage.PTRResource{PTR: ptr})
+		}
+	case dnsmessage.TypeMX:
+		mxs, err := r.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			host, err := dnsmessage.NewName(fqdn(mx.Host))
+			if err != nil {
+				continue
+			}
+			add(dnsmessage.TypeMX, &dnsmessage.MXResource{Pref: mx.Pref, MX: host})
+		}
+	case dnsmessage.TypeNS:
+		nss, err := r.LookupNS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			host, err := dnsmessage.NewName(fqdn(ns.Host))
+			if err != nil {
+				continue
+			}
+			add(dnsmessage.TypeNS, &dnsmessage.NSResource{NS: host})
+		}
+	case dnsmessage.TypeSRV:
+		_, srvs, err := r.LookupSRV(ctx, "", "", name)
+		if err != nil {
+			return nil, err
+		}
+		for _, srv := range srvs {
+			target, err := dnsmessage.NewName(fqdn(srv.Target))
+			if err != nil {
+				continue
+			}
+			add(dnsmessage.TypeSRV, &dnsmessage.SRVResource{
+				Priority: srv.Priority,
+				Weight:   srv.Weight,
+				Port:     srv.Port,
+				Target:   target,
+			})
+		}
+	case dnsmessage.TypeTXT:
+		txts, err := r.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(txts) > 0 {
+			add(dnsmessage.TypeTXT, &dnsmessage.TXTResource{TXT: txts})
+		}
+	default:
+		return nil, errNotImplemented
+	}
+
+	return answers, nil
+}
+
+var errNotImplemented = errors.New("query type not implemented")
+
+func lookupRCode(err error) dnsmessage.RCode {
+	if errors.Is(err, errNotImplemented) {
+		return dnsmessage.RCodeNotImplemented
+	}
+	if isNotFound(err) {
+		return dnsmessage.RCodeNameError
+	}
+	return dnsmessage.RCodeServerFailure
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// errorResponse encodes a response without questions to query, whose header
+// may not be decodable. It carries the ID of query if query is long enough.
+func errorResponse(query []byte, rcode dnsmessage.RCode) ([]byte, error) {
+	var h dnsmessage.Header
+	if len(query) >= 2 {
+		h.ID = binary.BigEndian.Uint16(query)
+	}
+	return buildResponse(h, nil, nil, rcode, maxUDPResponseSize)
+}
+
+// buildResponse encodes the response to a query. If the answers do not fit
+// in maxSize they are dropped and the response is marked as truncated, so
+// that the client retries over TCP.
+func buildResponse(q dnsmessage.Header, questions []dnsmessage.Question, answers []dnsmessage.Resource, rcode dnsmessage.RCode, maxSize int) ([]byte, error) {
+	h := dnsmessage.Header{
+		ID:                 q.ID,
+		Response:           true,
+		OpCode:             q.OpCode,
+		RecursionDesired:   q.RecursionDesired,
+		RecursionAvailable: true,
+		RCode:              rcode,
+	}
+
+	msg := dnsmessage.Message{Header: h, Questions: questions, Answers: answers}
+	resp, err := msg.AppendPack(make([]byte, 0, maxUDPResponseSize))
+	if err != nil || len(resp) <= maxSize {
+		return resp, err
+	}
+
+	msg.Header.Truncated = true
+	msg.Answers = nil
+	return msg.AppendPack(resp[:0])
+}
+
+// reverseAddress returns the address of an in-addr.arpa or ip6.arpa name.
+func reverseAddress(name string) net.IP {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+
+	if v4, ok := strings.CutSuffix(name, ".in-addr.arpa"); ok {
+		labels := strings.Split(v4, ".")
+		if len(labels) != net.IPv4len {
+			return nil
+		}
+		for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+			labels[i], labels[j] = labels[j], labels[i]
+		}
+		return net.ParseIP(strings.Join(labels, ".")).To4()
+	}
+
+	if v6, ok := strings.CutSuffix(name, ".ip6.arpa"); ok {
+		nibbles := strings.Split(v6, ".")
+		if len(nibbles) != 2*net.IPv6len {
+			return nil
+		}
+		var b strings.Builder
+		for i := len(nibbles) - 1; i >= 0; i-- {
+			if len(nibbles[i]) != 1 {
+				return nil
+			}
+			b.WriteString(nibbles[i])
+			if i%4 == 0 && i > 0 {
+				b.WriteByte(':')
+			}
+		}
+		return net.ParseIP(b.String())
+	}
+
+	return nil
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
@@ -0,0 +1,150 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestReverseAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		want net.IP
+	}{
+		{"4.3.2.10.in-addr.arpa.", net.ParseIP("10.2.3.4")},
+		{"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.0.d.f.ip6.arpa.", net.ParseIP("fd02::1")},
+		{"3.2.10.in-addr.arpa.", nil},
+		{"example.com.", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reverseAddress(tt.name); !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRefused(t *testing.T) {
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 0x1234, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName("example.com."),
+			Type:  dnsmessage.TypeA,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	query, err := msg.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := resolve(context.Background(), protocol.ResolveRequest{Query: query}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got dnsmessage.Message
+	if err := got.Unpack(resp); err != nil {
+		t.Fatalf("could not unpack response: %v", err)
+	}
+	if got.ID != msg.ID || !got.Response || got.RCode != dnsmessage.RCodeRefused {
+		t.Errorf("unexpected response header %+v", got.Header)
+	}
+	if len(got.Questions) != 1 || got.Questions[0] != msg.Questions[0] {
+		t.Errorf("unexpected questions %+v", got.Questions)
+	}
+}
+
+func TestBuildResponseTruncates(t *testing.T) {
+	name := dnsmessage.MustNewName("example.com.")
+	var answers []dnsmessage.Resource
+	for i := range 64 {
+		answers = append(answers, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET},
+			Body:   &dnsmessage.AResource{A: [4]byte{10, 0, 0, byte(i)}},
+		})
+	}
+
+	resp, err := buildResponse(dnsmessage.Header{ID: 1}, nil, answers, dnsmessage.RCodeSuccess, maxUDPResponseSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got dnsmessage.Message
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Truncated || len(got.Answers) != 0 {
+		t.Errorf("expected a truncated response without answers, got %+v", got.Header)
+	}
+}
+
+func TestHandleResolveAnswersBadRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+		id   uint16
+	}{
+		{"undecodable request", []byte{0xff}, 0},
+		{"undecodable query", []byte{0, 0, 0, 3, 0x12, 0x34, 0x01}, 0x1234},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxySide, agentSide := net.Pipe()
+			defer proxySide.Close()
+			d := &Dialer{}
+			go d.handleResolve(context.Background(), &session{}, agentSide, protocol.Data{Command: protocol.ResolveCmd, Body: tt.body})
+
+			var reply protocol.ResolveReply
+			if err := protocol.Receive(proxySide, protocol.ResolveReplyCmd, &reply); err != nil {
+				t.Fatal(err)
+			}
+			var got dnsmessage.Message
+			if err := got.Unpack(reply.Response); err != nil {
+				t.Fatalf("could not unpack response: %v", err)
+			}
+			if got.ID != tt.id || got.RCode != dnsmessage.RCodeFormatError {
+				t.Errorf("unexpected response header %+v", got.Header)
+			}
+		})
+	}
+}
+
+func TestHandleResolveNegotiated(t *testing.T) {
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 0x1234, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName("example.com."), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET}},
+	}
+	query, err := msg.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := protocol.ResolveRequest{Query: query}.MarshalBinary()
+
+	// The agent offers DNS but it was not negotiated for the session.
+	d := NewDialer(context.Background(), nil, "")
+	sess := &session{}
+	sess.caps.Store(uint32(protocol.SupportedCapabilities &^ protocol.CapDNS))
+
+	proxySide, agentSide := net.Pipe()
+	defer proxySide.Close()
+	go d.handleResolve(context.Background(), sess, agentSide, protocol.Data{Command: protocol.ResolveCmd, Body: body})
+
+	var reply protocol.ResolveReply
+	if err := protocol.Receive(proxySide, protocol.ResolveReplyCmd, &reply); err != nil {
+		t.Fatal(err)
+	}
+	var got dnsmessage.Message
+	if err := got.Unpack(reply.Response); err != nil {
+		t.Fatalf("could not unpack response: %v", err)
+	}
+	if got.RCode != dnsmessage.RCodeRefused {
+		t.Errorf("got rcode %s, want %s", got.RCode, dnsmessage.RCodeRefused)
+	}
+}
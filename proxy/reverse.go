@@ -0,0 +1,107 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/relay"
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/rs/zerolog/log"
+)
+
+// handleReverseListen opens the listener of a reverse forward. The stream is
+// the listener's control channel, the listener is closed with it.
+func (d *Dialer) handleReverseListen(ctx context.Context, sess *session, stream transport.Stream, dec protocol.Data) {
+	var req protocol.ReverseListenRequest
+	if err := req.UnmarshalBinary(dec.Body); err != nil {
+		log.Error().Err(err).Msg("could not decode reverse listen request")
+		return
+	}
+	// Closing the stream tells the proxy that the listener is gone.
+	defer stream.Close()
+
+	var resp protocol.ReverseListenResp
+	var ln net.Listener
+	if !sess.capabilities().Has(protocol.CapReverseForward) {
+		resp.Error = "reverse forwarding is disabled"
+	} else {
+		var err error
+		if ln, err = (&net.ListenConfig{}).Listen(ctx, "tcp", req.Address); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Address = ln.Addr().String()
+		}
+	}
+
+	if err := protocol.Send(stream, protocol.ReverseListenRespCmd, resp); err != nil {
+		log.Error().Err(err).Msg("could not encode reverse listen response")
+		if ln != nil {
+			ln.Close()
+		}
+		return
+	}
+	if ln == nil {
+		log.Error().Str("address", req.Address).Msgf("could not open reverse forward listener: %s", resp.Error)
+		return
+	}
+
+	log.Info().Uint32("id", req.ID).Msgf("reverse forward listening on %s", resp.Address)
+
+	// The proxy closes the control stream to remove the forward.
+	go func() {
+		io.Copy(io.Discard, stream)
+		ln.Close()
+	}()
+	stop := context.AfterFunc(ctx, func() {
+		ln.Close()
+	})
+	defer stop()
+	defer ln.Close()
+
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error().Err(err).Uint32("id", req.ID).Msg("could not accept reverse forward connection")
+			}
+			log.Info().Uint32("id", req.ID).Msgf("reverse forward on %s closed", resp.Address)
+			return
+		}
+
+		go reverseConnect(ctx, sess.conn, req.ID, c)
+	}
+}
+
+// reverseConnect carries a connection accepted by a reverse forward listener
+// back to the proxy.
+func reverseConnect(ctx context.Context, conn transport.StreamConn, id uint32, c net.Conn) {
+	defer c.Close()
+
+	stream, err := conn.GetStream(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("could not open stream to proxy")
+		return
+	}
+	// Streams carry a single request and are never reused.
+	defer stream.Close()
+
+	resp, err := protocol.ReverseConnect(stream, protocol.ReverseConnectRequest{
+		ID:         id,
+		RemoteAddr: c.RemoteAddr().String(),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("could not hand connection to proxy")
+		return
+	}
+	if !resp.Established {
+		log.Debug().Stringer("reason", resp.Reason).Uint32("id", id).Msg("proxy could not reach reverse forward target")
+		return
+	}
+
+	if err := relay.Pipe(stream, c); err != nil {
+		log.Error().Err(err).Msg("could not pipe data between stream and reverse forward connection")
+	}
+}
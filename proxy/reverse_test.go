@@ -0,0 +1,28 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+)
+
+func TestReverseListenNegotiated(t *testing.T) {
+	d := NewDialer(context.Background(), nil, "")
+	sess := &session{}
+	sess.caps.Store(uint32(protocol.SupportedCapabilities &^ protocol.CapReverseForward))
+	body, _ := protocol.ReverseListenRequest{ID: 1, Address: "127.0.0.1:0"}.MarshalBinary()
+
+	proxySide, agentSide := net.Pipe()
+	defer proxySide.Close()
+	go d.handleReverseListen(context.Background(), sess, agentSide, protocol.Data{Command: protocol.ReverseListenCmd, Body: body})
+
+	var resp protocol.ReverseListenResp
+	if err := protocol.Receive(proxySide, protocol.ReverseListenRespCmd, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error == "" {
+		t.Errorf("listener opened on %s without CapReverseForward", resp.Address)
+	}
+}
@@ -0,0 +1,74 @@
+package proxy
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/rs/zerolog/log"
+)
+
+// watchRoutes sends the agent's routes to the proxy over conn whenever they
+// differ from the routes sent last, starting with sent from the handshake.
+func watchRoutes(ctx context.Context, conn transport.StreamConn, sent []string) {
+	changes, err := routeChanges(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("could not watch network changes, routes will not be updated")
+		return
+	}
+	slices.Sort(sent)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changes:
+		}
+
+		// Let a burst of changes settle, e.g. a DHCP lease adding an address
+		// and its routes.
+		timer := time.NewTimer(config.RouteUpdateDelay)
+	settle:
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-changes:
+			case <-timer.C:
+				break settle
+			}
+		}
+
+		routes, err := GetNetRoutes()
+		if err != nil {
+			log.Error().Err(err).Msg("could not get network routes")
+			continue
+		}
+		slices.Sort(routes)
+		if slices.Equal(routes, sent) {
+			continue
+		}
+
+		if err := sendRoutes(ctx, conn, routes); err != nil {
+			log.Error().Err(err).Msg("could not send routes update")
+			continue
+		}
+		log.Info().Strs("routes", routes).Msg("sent routes update")
+		sent = routes
+	}
+}
+
+func sendRoutes(ctx context.Context, conn transport.StreamConn, routes []string) error {
+	stream, err := conn.GetStream(ctx)
+	if err != nil {
+		return err
+	}
+	// Streams carry a single request and are never reused.
+	defer stream.Close()
+
+	return protocol.Send(stream, protocol.RoutesUpdateCmd, protocol.RoutesUpdate{Routes: routes})
+}
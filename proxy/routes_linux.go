@@ -0,0 +1,83 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vishvananda/netlink"
+)
+
+// routeChanges notifies about changes of the host's links, addresses and
+// routes until ctx is done.
+func routeChanges(ctx context.Context) (<-chan struct{}, error) {
+	// Stops the subscriptions already made if a later one fails.
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	context.AfterFunc(ctx, func() {
+		close(done)
+	})
+	onError := func(err error) {
+		log.Debug().Err(err).Msg("netlink subscription error")
+	}
+
+	links := make(chan netlink.LinkUpdate)
+	if err := netlink.LinkSubscribeWithOptions(links, done, netlink.LinkSubscribeOptions{ErrorCallback: onError}); err != nil {
+		cancel()
+		return nil, fmt.Errorf("could not subscribe to link changes: %w", err)
+	}
+	addrs := make(chan netlink.AddrUpdate)
+	if err := netlink.AddrSubscribeWithOptions(addrs, done, netlink.AddrSubscribeOptions{ErrorCallback: onError}); err != nil {
+		cancel()
+		go drain(links)
+		return nil, fmt.Errorf("could not subscribe to address changes: %w", err)
+	}
+	routes := make(chan netlink.RouteUpdate)
+	if err := netlink.RouteSubscribeWithOptions(routes, done, netlink.RouteSubscribeOptions{ErrorCallback: onError}); err != nil {
+		cancel()
+		go drain(links)
+		go drain(addrs)
+		return nil, fmt.Errorf("could not subscribe to route changes: %w", err)
+	}
+
+	changes := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+	go func() {
+		defer cancel()
+		// The update channels are closed when done is.
+		for links != nil || addrs != nil || routes != nil {
+			select {
+			case _, ok := <-links:
+				if !ok {
+					links = nil
+					continue
+				}
+			case _, ok := <-addrs:
+				if !ok {
+					addrs = nil
+					continue
+				}
+			case _, ok := <-routes:
+				if !ok {
+					routes = nil
+					continue
+				}
+			}
+			notify()
+		}
+	}()
+
+	return changes, nil
+}
+
+// drain discards the updates of a subscription until it is stopped, netlink
+// blocks on sending them otherwise.
+func drain[T any](ch <-chan T) {
+	for range ch {
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !linux
+
+package proxy
+
+import (
+	"context"
+	"time"
+
+	"github.com/fr13n8/raido/config"
+)
+
+// routeChanges polls for changes every config.RoutePollInterval on systems
+// without change notifications.
+func routeChanges(ctx context.Context) (<-chan struct{}, error) {
+	changes := make(chan struct{}, 1)
+	go func() {
+		ticker := time.NewTicker(config.RoutePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return changes, nil
+}
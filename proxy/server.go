@@ -2,48 +2,122 @@ package proxy
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/fr13n8/raido/agent"
 	"github.com/fr13n8/raido/config"
 	"github.com/fr13n8/raido/proxy/protocol"
 	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/fr13n8/raido/utils/identity"
+	"github.com/lithammer/shortuuid/v4"
 	"github.com/quic-go/quic-go"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/sync/errgroup"
 )
 
 type Server struct {
-	listener     transport.StreamListener
+	// ID identifies the server among the proxy listeners sharing the agent
+	// manager, the agents it accepts carry it.
+	ID string
+	// listeners accept agents, each over its own transport.
+	listeners    []listener
 	agentManager *agent.Manager
-	connCh       chan transport.StreamConn
+	// extra are the transports added with WithListener, listened on once the
+	// options are applied.
+	extra []Endpoint
+	// relay runs the connections of agents relayed by other agents, relaying
+	// is refused when it is nil.
+	relay transport.Upgrader
+	// gracePeriod is how long an agent whose connection was lost is kept,
+	// with its tunnel, waiting for it to reconnect.
+	gracePeriod time.Duration
 }
 
-func NewServer(ctx context.Context, tr transport.Transport, address string) (*Server, error) {
-	listener, err := tr.Listen(ctx, address)
-	if err != nil {
-		return nil, fmt.Errorf("could not listen on address: %w", err)
+// listener is a listener of the server and the name of its transport.
+type listener struct {
+	transport.StreamListener
+	transport string
+}
+
+type ServerOption func(*Server)
+
+// WithListener makes the server accept agents over tr on address as well, so
+// agents can fall back to tr when the server's transport is blocked. Agents
+// of all listeners share the server's agent manager.
+func WithListener(tr transport.Transport, address string) ServerOption {
+	return func(s *Server) {
+		s.extra = append(s.extra, Endpoint{Address: address, Transport: tr})
+	}
+}
+
+// WithRelayTransport sets the transport spoken with agents connecting through
+// the relay listener of another agent. It defaults to the server's transport
+// if that can run over a relayed connection.
+func WithRelayTransport(u transport.Upgrader) ServerOption {
+	return func(s *Server) {
+		s.relay = u
+	}
+}
+
+// WithGracePeriod sets how long an agent whose connection was lost is kept
+// before it is removed. An agent reconnecting within the grace period gets
+// its tunnel back.
+func WithGracePeriod(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.gracePeriod = d
 	}
+}
 
-	return &Server{
-		listener:     listener,
+func NewServer(ctx context.Context, tr transport.Transport, address string, opts ...ServerOption) (*Server, error) {
+	s := &Server{
+		ID:           shortuuid.New(),
 		agentManager: agent.NewAgentManager(),
-		connCh:       make(chan transport.StreamConn),
-	}, nil
+		gracePeriod:  config.AgentGracePeriod,
+	}
+	if u, ok := tr.(transport.Upgrader); ok {
+		s.relay = u
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	for _, ep := range append([]Endpoint{{Address: address, Transport: tr}}, s.extra...) {
+		l, err := ep.Transport.Listen(ctx, ep.Address)
+		if err != nil {
+			s.closeListeners()
+			return nil, fmt.Errorf("could not listen on address %s: %w", ep.Address, err)
+		}
+		s.listeners = append(s.listeners, listener{StreamListener: l, transport: ep.Transport.Name()})
+	}
+
+	return s, nil
+}
+
+func (s *Server) closeListeners() error {
+	var errs []error
+	for _, l := range s.listeners {
+		if err := l.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 func (s *Server) ShutdownGracefully(ctx context.Context) error {
 	log.Info().Msg("shutting down proxy server gracefully...")
-	defer close(s.connCh)
 	var errs []error
 
-	if err := s.agentManager.Cleanup(); err != nil {
+	// Agents of other servers share the manager and stay.
+	if err := s.agentManager.RemoveListenerAgents(s.ID); err != nil {
 		errs = append(errs, err)
 	}
 
-	if err := s.listener.Close(); err != nil {
+	if err := s.closeListeners(); err != nil {
 		errs = append(errs, err)
 	}
 
@@ -55,36 +129,16 @@ func (s *Server) ShutdownGracefully(ctx context.Context) error {
 }
 
 func (s *Server) Listen(ctx context.Context) error {
-	log.Info().Str("addr", fmt.Sprintf("%s/%s", s.listener.Addr().Network(), s.listener.Addr().String())).Msg("proxy server started")
-
 	var g errgroup.Group
 
-	g.Go(func() error {
-		s.processConnection(ctx)
-		return nil
-	})
-
-	g.Go(func() error {
-		defer s.listener.Close()
+	for _, l := range s.listeners {
+		log.Info().Str("addr", fmt.Sprintf("%s/%s", l.Addr().Network(), l.Addr().String())).Str("transport", l.transport).Msg("proxy server started")
 
-		for {
-			if err := ctx.Err(); err != nil {
-				log.Info().Msg("stopping listener")
-				return nil
-			}
-
-			conn, err := s.listener.Accept(ctx)
-			if err != nil {
-				if errors.Is(err, quic.ErrServerClosed) || errors.Is(err, context.Canceled) {
-					log.Info().Msg("listener closed")
-					return nil
-				}
-				log.Error().Err(err).Msg("failed to accept connection")
-				continue
-			}
-			s.connCh <- conn
-		}
-	})
+		g.Go(func() error {
+			s.accept(ctx, l)
+			return nil
+		})
+	}
 
 	g.Go(func() error {
 		<-ctx.Done()
@@ -101,71 +155,258 @@ func (s *Server) Listen(ctx context.Context) error {
 	return g.Wait()
 }
 
-func (s *Server) processConnection(ctx context.Context) {
-	for conn := range s.connCh {
-		go s.startHandshake(ctx, conn)
-	}
-}
-
-func (s *Server) startHandshake(ctx context.Context, conn transport.StreamConn) {
-	stream, err := conn.GetStream(ctx)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to open stream")
-		return
-	}
-	defer conn.PutStream(stream)
+// accept starts the handshake of every agent connecting to l until l is
+// closed or ctx is done.
+func (s *Server) accept(ctx context.Context, l listener) {
+	defer l.Close()
 
-	encoder := protocol.NewEncoder[protocol.Data](stream)
-	decoder := protocol.NewDecoder[protocol.GetRoutesResp](stream)
+	for {
+		if err := ctx.Err(); err != nil {
+			log.Info().Msg("stopping listener")
+			return
+		}
 
-	if err := encoder.Encode(protocol.Data{
-		Command: protocol.GetRoutesReqCmd,
-		Body:    nil,
-	}); err != nil {
-		log.Error().Err(err).Msg("failed to encode data")
-		return
+		conn, err := l.Accept(ctx)
+		if err != nil {
+			if errors.Is(err, quic.ErrServerClosed) || errors.Is(err, context.Canceled) || errors.Is(err, net.ErrClosed) {
+				log.Info().Str("transport", l.transport).Msg("listener closed")
+				return
+			}
+			log.Error().Err(err).Msg("failed to accept connection")
+			continue
+		}
+		go s.startHandshake(ctx, conn, "", l.transport)
 	}
+}
 
-	dec, err := decoder.Decode()
+// startHandshake registers the agent connected over conn using the transport
+// named tr. parent is the ID of the agent relaying the connection, if any.
+func (s *Server) startHandshake(ctx context.Context, conn transport.StreamConn, parent, tr string) {
+	dec, err := s.handshake(ctx, conn)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to decode data")
+		log.Error().Err(err).Msg("agent handshake failed")
+		if err := conn.CloseWithError(protocol.ApplicationHandshakeFailed, err.Error()); err != nil {
+			log.Error().Err(err).Msg("failed to close connection")
+		}
 		return
 	}
 
-	var routes []string
-	for _, route := range dec.Routes {
-		ip, _, err := net.ParseCIDR(route)
-		if err != nil {
-			log.Error().Err(err).Msg("failed to parse route")
-			continue
-		}
-		if !ip.IsLoopback() && !ip.IsLinkLocalUnicast() {
-			routes = append(routes, route)
-		}
+	// Agents without an identity key get a new ID on every connection.
+	id := shortuuid.New()
+	if dec.PublicKey != nil {
+		id = identity.ID(dec.PublicKey)
+	}
+	a := agent.New(id, dec.Name, conn, dec.Routes)
+	a.Parent = parent
+	a.Version = dec.Version
+	a.Build = dec.Build
+	a.Capabilities = dec.Capabilities
+	a.Host = dec.Host
+	a.Connected = time.Now()
+	a.Started = a.Connected.Add(-time.Duration(dec.Host.Uptime) * time.Second)
+	a.Transport = tr
+	a.Listener = s.ID
+	if addr := conn.RemoteAddr(); addr != nil {
+		a.RemoteAddr = addr.String()
 	}
-
-	a := agent.New(dec.Name, conn, routes)
 	s.agentManager.AddAgent(a)
 
+	log.Info().
+		Str("agent_id", a.ID).
+		Str("name", a.Hostname).
+		Str("parent", a.Parent).
+		Str("remote_addr", a.RemoteAddr).
+		Str("transport", a.Transport).
+		Str("listener", a.Listener).
+		Str("agent_version", a.Build.Version).
+		Uint16("protocol_version", a.Version).
+		Stringer("capabilities", a.Capabilities).
+		Msg("agent connected")
+
 	go func() {
 		for {
-			_, err := conn.AcceptStream(ctx)
+			stream, err := conn.AcceptStream(ctx)
 			if err != nil {
 				var appErr *quic.ApplicationError
 				if errors.As(err, &appErr) {
 					if appErr.ErrorCode == protocol.ApplicationOK {
-						log.Info().Str("agent_id", a.ID).Msg("agent closed connection")
-
-						if err := s.agentManager.RemoveAgent(a.ID); err != nil {
+						// Agents announcing the close with Disconnect are
+						// already removed.
+						removed, err := s.agentManager.RemoveAgentInstance(a)
+						if err != nil {
 							log.Error().Err(err).Str("agent_id", a.ID).Msg("failed to remove agent")
 						}
+						if removed {
+							log.Info().Str("agent_id", a.ID).Msg("agent closed connection")
+						}
 
 						return
 					}
 				}
-				log.Error().Err(err).Msg("failed to accept new stream from agent")
+				if s.agentManager.GetAgent(a.ID) != a {
+					// Removed or replaced by a new connection.
+					return
+				}
+				log.Warn().Err(err).Str("agent_id", a.ID).Msgf("lost connection to agent, waiting %s for it to reconnect", s.gracePeriod)
+				time.AfterFunc(s.gracePeriod, func() {
+					removed, err := s.agentManager.RemoveAgentInstance(a)
+					if err != nil {
+						log.Error().Err(err).Str("agent_id", a.ID).Msg("failed to remove agent")
+					}
+					if removed {
+						log.Info().Str("agent_id", a.ID).Msg("agent did not reconnect, removed")
+					}
+				})
 				return
 			}
+
+			go s.handleAgentStream(ctx, a, stream)
 		}
 	}()
 }
+
+// handleAgentStream serves a stream opened by the agent a.
+func (s *Server) handleAgentStream(ctx context.Context, a *agent.Agent, stream transport.Stream) {
+	dec, err := protocol.ReadData(stream)
+	if err != nil {
+		log.Error().Err(err).Str("agent_id", a.ID).Msg("could not decode data")
+		stream.Close()
+		return
+	}
+
+	switch dec.Command {
+	case protocol.RelayConnectCmd:
+		s.handleRelay(ctx, a, stream, dec)
+	case protocol.DisconnectCmd:
+		s.handleDisconnect(a, stream, dec)
+	default:
+		a.HandleStream(ctx, stream, dec)
+	}
+}
+
+// handleDisconnect removes the agent a, which is about to close its
+// connection. Without it the connection's close looks like any other loss on
+// transports that cannot carry a close code.
+func (s *Server) handleDisconnect(a *agent.Agent, stream transport.Stream, dec protocol.Data) {
+	var req protocol.Disconnect
+	if err := req.UnmarshalBinary(dec.Body); err != nil {
+		log.Error().Err(err).Msg("could not decode disconnect")
+		stream.Close()
+		return
+	}
+	// Acknowledge before the connection is closed below.
+	stream.Close()
+
+	log.Info().Str("agent_id", a.ID).Str("reason", req.Reason).Msg("agent closed connection")
+	if _, err := s.agentManager.RemoveAgentInstance(a); err != nil {
+		log.Error().Err(err).Str("agent_id", a.ID).Msg("failed to remove agent")
+	}
+}
+
+// handleRelay registers the downstream agent whose connection is relayed by
+// the agent parent on stream.
+func (s *Server) handleRelay(ctx context.Context, parent *agent.Agent, stream transport.Stream, dec protocol.Data) {
+	var req protocol.RelayConnectRequest
+	if err := req.UnmarshalBinary(dec.Body); err != nil {
+		log.Error().Err(err).Msg("could not decode relay connect request")
+		stream.Close()
+		return
+	}
+
+	if s.relay == nil || !parent.Capabilities.Has(protocol.CapRelay) {
+		log.Warn().Str("agent_id", parent.ID).Msgf("refusing relayed agent from %s", req.RemoteAddr)
+		if err := protocol.Send(stream, protocol.ConnectResponseCmd, protocol.ConnectResponse{Reason: protocol.ReasonDenied}); err != nil {
+			log.Error().Err(err).Msg("could not encode connection response")
+		}
+		stream.Close()
+		return
+	}
+
+	if err := protocol.Send(stream, protocol.ConnectResponseCmd, protocol.ConnectResponse{Established: true}); err != nil {
+		log.Error().Err(err).Msg("could not encode connection response")
+		stream.Close()
+		return
+	}
+
+	remote, err := net.ResolveTCPAddr("tcp", req.RemoteAddr)
+	if err != nil {
+		remote = &net.TCPAddr{}
+	}
+	// The stream now belongs to the downstream agent's connection.
+	conn, err := s.relay.Server(transport.NetConn(stream, &net.TCPAddr{}, remote))
+	if err != nil {
+		log.Error().Err(err).Str("agent_id", parent.ID).Msg("could not run transport over relayed connection")
+		stream.Close()
+		return
+	}
+
+	log.Info().Str("agent_id", parent.ID).Msgf("agent connecting from %s through relay", req.RemoteAddr)
+	s.startHandshake(ctx, conn, parent.ID, s.relay.Name())
+}
+
+// handshake exchanges protocol versions and capabilities with a freshly
+// connected agent. The returned response carries the negotiated version and
+// capability set.
+func (s *Server) handshake(ctx context.Context, conn transport.StreamConn) (protocol.HandshakeResp, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.HandshakeTimeout)
+	defer cancel()
+
+	stream, err := conn.GetStream(ctx)
+	if err != nil {
+		return protocol.HandshakeResp{}, fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer stream.Close()
+
+	nonce := make([]byte, protocol.NonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return protocol.HandshakeResp{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	if err := protocol.Send(stream, protocol.HandshakeCmd, protocol.HandshakeReq{
+		Version:      protocol.Version,
+		Capabilities: protocol.SupportedCapabilities,
+		Nonce:        nonce,
+	}); err != nil {
+		return protocol.HandshakeResp{}, fmt.Errorf("failed to encode handshake: %w", err)
+	}
+
+	type result struct {
+		resp protocol.HandshakeResp
+		err  error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		var resp protocol.HandshakeResp
+		err := protocol.Receive(stream, protocol.HandshakeRespCmd, &resp)
+		resCh <- result{resp: resp, err: err}
+	}()
+
+	var res result
+	select {
+	case <-ctx.Done():
+		return protocol.HandshakeResp{}, fmt.Errorf("agent did not answer handshake: %w", ctx.Err())
+	case res = <-resCh:
+	}
+	if res.err != nil {
+		return protocol.HandshakeResp{}, fmt.Errorf("failed to decode handshake response: %w", res.err)
+	}
+
+	resp := res.resp
+	if resp.Error != "" {
+		return protocol.HandshakeResp{}, fmt.Errorf("agent refused handshake: %s", resp.Error)
+	}
+
+	if resp.Version, err = protocol.NegotiateVersion(resp.Version); err != nil {
+		return protocol.HandshakeResp{}, err
+	}
+	// Agents older than VersionIdentity have no identity key.
+	if resp.Version >= protocol.VersionIdentity || resp.PublicKey != nil {
+		if len(resp.PublicKey) != ed25519.PublicKeySize ||
+			!ed25519.Verify(resp.PublicKey, protocol.IdentityProof(nonce), resp.Signature) {
+			return protocol.HandshakeResp{}, errors.New("agent failed to prove its identity")
+		}
+	}
+	resp.Capabilities &= protocol.SupportedCapabilities
+
+	return resp, nil
+}
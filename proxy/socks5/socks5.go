@@ -0,0 +1,362 @@
+// Package socks5 implements a SOCKS5 server (RFC 1928) whose connections are
+// relayed through an agent. It lets proxy-aware tools reach the agent's
+// networks without a TUN interface. CONNECT and UDP ASSOCIATE are supported,
+// BIND is not.
+package socks5
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/fr13n8/raido/proxy/forward"
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/relay"
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/rs/zerolog/log"
+)
+
+const socksVersion = 5
+
+// Authentication methods.
+const (
+	methodNoAuth       = 0x00
+	methodNoAcceptable = 0xff
+)
+
+// Request commands.
+const (
+	cmdConnect      = 0x01
+	cmdBind         = 0x02
+	cmdUDPAssociate = 0x03
+)
+
+// Address types.
+const (
+	atypIPv4   = 0x01
+	atypDomain = 0x03
+	atypIPv6   = 0x04
+)
+
+// Reply codes.
+const (
+	repSucceeded           = 0x00
+	repGeneralFailure      = 0x01
+	repNotAllowed          = 0x02
+	repNetworkUnreachable  = 0x03
+	repHostUnreachable     = 0x04
+	repConnectionRefused   = 0x05
+	repTTLExpired          = 0x06
+	repCommandNotSupported = 0x07
+	repAddressNotSupported = 0x08
+)
+
+// Server is a SOCKS5 listener relaying through an agent.
+type Server struct {
+	Address string
+
+	conn   transport.StreamConn
+	caps   protocol.Capability
+	ln     net.Listener
+	cancel context.CancelFunc
+	active atomic.Int64
+}
+
+// Listen starts a SOCKS5 server on address that relays through the agent
+// connected over conn. caps are the capabilities negotiated with the agent,
+// host names are only accepted if the agent resolves them.
+func Listen(ctx context.Context, conn transport.StreamConn, caps protocol.Capability, address string) (*Server, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", address)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("could not listen on %s: %w", address, err)
+	}
+
+	s := &Server{
+		Address: ln.Addr().String(),
+		conn:    conn,
+		caps:    caps,
+		ln:      ln,
+		cancel:  cancel,
+	}
+	go s.serve(ctx)
+
+	log.Info().Msgf("SOCKS5 server listening on %s", s.Address)
+	return s, nil
+}
+
+// Connections returns the number of connections and UDP associations
+// currently relayed.
+func (s *Server) Connections() int64 {
+	return s.active.Load()
+}
+
+// Close stops the listener and every relayed connection.
+func (s *Server) Close() error {
+	s.cancel()
+	return s.ln.Close()
+}
+
+func (s *Server) serve(ctx context.Context) {
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error().Err(err).Msg("could not accept SOCKS5 connection")
+			}
+			return
+		}
+
+		go s.handle(ctx, c)
+	}
+}
+
+func (s *Server) handle(ctx context.Context, c net.Conn) {
+	defer c.Close()
+	s.active.Add(1)
+	defer s.active.Add(-1)
+
+	// Close the client connection when the server is closed.
+	stop := context.AfterFunc(ctx, func() {
+		c.Close()
+	})
+	defer stop()
+
+	if err := negotiate(c); err != nil {
+		log.Debug().Err(err).Msgf("SOCKS5 negotiation with %s failed", c.RemoteAddr())
+		return
+	}
+
+	cmd, addr, err := readRequest(c)
+	if err != nil {
+		log.Debug().Err(err).Msgf("could not read SOCKS5 request from %s", c.RemoteAddr())
+		if errors.Is(err, errAddressType) {
+			writeReply(c, repAddressNotSupported, nil)
+		}
+		return
+	}
+
+	switch cmd {
+	case cmdConnect:
+		s.handleConnect(ctx, c, addr)
+	case cmdUDPAssociate:
+		s.handleAssociate(ctx, c)
+	default:
+		writeReply(c, repCommandNotSupported, nil)
+	}
+}
+
+func (s *Server) handleConnect(ctx context.Context, c net.Conn, addr address) {
+	target, rep := s.target(ctx, addr, protocol.TransportTCP)
+	if rep != repSucceeded {
+		writeReply(c, rep, nil)
+		return
+	}
+
+	stream, rep := s.open(ctx, target)
+	if rep != repSucceeded {
+		writeReply(c, rep, nil)
+		return
+	}
+	// Streams carry a single request and are never reused.
+	defer stream.Close()
+
+	stop := transport.AbortOnDone(ctx, stream)
+	defer stop()
+
+	if err := writeReply(c, repSucceeded, nil); err != nil {
+		return
+	}
+
+	if err := relay.Pipe(stream, c); err != nil {
+		log.Error().Err(err).Msg("could not pipe data between stream and SOCKS5 connection")
+	}
+}
+
+// open asks the agent to connect to target. On failure it returns a nil
+// stream and the SOCKS5 reply code for the client.
+func (s *Server) open(ctx context.Context, target protocol.IPAddressWithPortProtocol) (transport.Stream, byte) {
+	stream, resp, err := forward.Open(ctx, s.conn, target)
+	if err != nil {
+		log.Error().Err(err).Msg("could not open connection")
+		return nil, repGeneralFailure
+	}
+	if !resp.Established {
+		log.Debug().Stringer("reason", resp.Reason).Msgf("agent could not connect to %s", target.IP)
+		return nil, replyCode(resp.Reason)
+	}
+
+	return stream, repSucceeded
+}
+
+// target returns the address the agent connects to for addr, resolving host
+// names through the agent.
+func (s *Server) target(ctx context.Context, addr address, proto uint8) (protocol.IPAddressWithPortProtocol, byte) {
+	ip := addr.IP
+	if ip == nil {
+		if !s.caps.Has(protocol.CapDNS) {
+			log.Debug().Msgf("cannot resolve %s, the agent does not resolve names", addr.Host)
+			return protocol.IPAddressWithPortProtocol{}, repHostUnreachable
+		}
+
+		var err error
+		if ip, err = forward.LookupIP(ctx, s.conn, addr.Host); err != nil {
+			log.Debug().Err(err).Msgf("could not resolve %s", addr.Host)
+			return protocol.IPAddressWithPortProtocol{}, repHostUnreachable
+		}
+	}
+
+	target := protocol.IPAddressWithPortProtocol{
+		IP:       ip,
+		Port:     addr.Port,
+		Network:  protocol.Networkv4,
+		Protocol: proto,
+	}
+	if ip.To4() == nil {
+		target.Network = protocol.Networkv6
+	}
+
+	return target, repSucceeded
+}
+
+// replyCode maps the reason the agent could not connect to a SOCKS5 reply.
+func replyCode(reason protocol.Reason) byte {
+	switch reason {
+	case protocol.ReasonDenied:
+		return repNotAllowed
+	case protocol.ReasonRefused:
+		return repConnectionRefused
+	case protocol.ReasonNetUnreachable:
+		return repNetworkUnreachable
+	case protocol.ReasonHostUnreachable:
+		return repHostUnreachable
+	case protocol.ReasonTimeout:
+		return repTTLExpired
+	default:
+		return repGeneralFailure
+	}
+}
+
+// negotiate reads the client greeting and selects the authentication method.
+// Only clients offering "no authentication" are accepted.
+func negotiate(rw io.ReadWriter) error {
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(rw, hdr); err != nil {
+		return err
+	}
+	if hdr[0] != socksVersion {
+		return fmt.Errorf("unsupported SOCKS version %d", hdr[0])
+	}
+	methods := make([]byte, hdr[1])
+	if _, err := io.ReadFull(rw, methods); err != nil {
+		return err
+	}
+
+	for _, m := range methods {
+		if m == methodNoAuth {
+			_, err := rw.Write([]byte{socksVersion, methodNoAuth})
+			return err
+		}
+	}
+
+	rw.Write([]byte{socksVersion, methodNoAcceptable})
+	return errors.New("no acceptable authentication method")
+}
+
+var errAddressType = errors.New("unsupported address type")
+
+// address is a SOCKS5 address, either an IP or a host name.
+type address struct {
+	IP   net.IP
+	Host string
+	Port uint16
+}
+
+func (a address) String() string {
+	if a.IP != nil {
+		return net.JoinHostPort(a.IP.String(), strconv.Itoa(int(a.Port)))
+	}
+	return net.JoinHostPort(a.Host, strconv.Itoa(int(a.Port)))
+}
+
+func readRequest(r io.Reader) (byte, address, error) {
+	hdr := make([]byte, 3)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return 0, address{}, err
+	}
+	if hdr[0] != socksVersion {
+		return 0, address{}, fmt.Errorf("unsupported SOCKS version %d", hdr[0])
+	}
+
+	addr, err := readAddress(r)
+	return hdr[1], addr, err
+}
+
+func readAddress(r io.Reader) (address, error) {
+	atyp := make([]byte, 1)
+	if _, err := io.ReadFull(r, atyp); err != nil {
+		return address{}, err
+	}
+
+	var addr address
+	switch atyp[0] {
+	case atypIPv4, atypIPv6:
+		size := map[bool]int{true: net.IPv4len, false: net.IPv6len}[atyp[0] == atypIPv4]
+		addr.IP = make(net.IP, size)
+		if _, err := io.ReadFull(r, addr.IP); err != nil {
+			return address{}, err
+		}
+	case atypDomain:
+		size := make([]byte, 1)
+		if _, err := io.ReadFull(r, size); err != nil {
+			return address{}, err
+		}
+		host := make([]byte, size[0])
+		if _, err := io.ReadFull(r, host); err != nil {
+			return address{}, err
+		}
+		addr.Host = string(host)
+		// Clients may send IP literals as names.
+		if ip := net.ParseIP(addr.Host); ip != nil {
+			addr.IP = ip
+		}
+	default:
+		return address{}, errAddressType
+	}
+
+	if err := binary.Read(r, binary.BigEndian, &addr.Port); err != nil {
+		return address{}, err
+	}
+
+	return addr, nil
+}
+
+// appendAddress appends the SOCKS5 encoding of addr to b. A nil address is
+// encoded as 0.0.0.0:0.
+func appendAddress(b []byte, addr net.Addr) []byte {
+	ip, port := net.IPv4zero.To4(), 0
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		ip, port = a.IP, a.Port
+	case *net.UDPAddr:
+		ip, port = a.IP, a.Port
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		b = append(append(b, atypIPv4), ip4...)
+	} else {
+		b = append(append(b, atypIPv6), ip.To16()...)
+	}
+	return binary.BigEndian.AppendUint16(b, uint16(port))
+}
+
+func writeReply(w io.Writer, rep byte, bound net.Addr) error {
+	_, err := w.Write(appendAddress([]byte{socksVersion, rep, 0}, bound))
+	return err
+}
@@ -0,0 +1,267 @@
+package socks5
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/relay"
+	"github.com/fr13n8/raido/proxy/transport"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// agentConn answers every stream like an agent would, dialing the requested
+// address on the local host and resolving every name to 127.0.0.1.
+type agentConn struct {
+	transport.StreamConn
+}
+
+func (c agentConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	proxySide, agentSide := net.Pipe()
+	go func() {
+		defer agentSide.Close()
+
+		dec, err := protocol.ReadData(agentSide)
+		if err != nil {
+			return
+		}
+		if dec.Command == protocol.ResolveCmd {
+			var req protocol.ResolveRequest
+			if req.UnmarshalBinary(dec.Body) != nil {
+				return
+			}
+			protocol.Send(agentSide, protocol.ResolveReplyCmd, protocol.ResolveReply{Response: answer(req.Query)})
+			return
+		}
+
+		var addr protocol.IPAddressWithPortProtocol
+		if addr.UnmarshalBinary(dec.Body) != nil {
+			return
+		}
+		network := map[uint8]string{protocol.TransportTCP: "tcp", protocol.TransportUDP: "udp"}[addr.Protocol]
+		target, err := net.Dial(network, net.JoinHostPort(addr.IP.String(), fmt.Sprint(addr.Port)))
+		if err != nil {
+			protocol.Send(agentSide, protocol.ConnectResponseCmd, protocol.ConnectResponse{Reason: protocol.ReasonRefused})
+			return
+		}
+		defer target.Close()
+		protocol.Send(agentSide, protocol.ConnectResponseCmd, protocol.ConnectResponse{Established: true})
+
+		var stream io.ReadWriteCloser = agentSide
+		if network == "udp" {
+			stream = relay.NewDatagramStream(agentSide)
+		}
+		relay.Pipe(target, stream)
+	}()
+	return proxySide, nil
+}
+
+// answer resolves A queries to 127.0.0.1 and answers others without records.
+func answer(query []byte) []byte {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil {
+		return nil
+	}
+	msg.Header.Response = true
+	if q := msg.Questions[0]; q.Type == dnsmessage.TypeA {
+		msg.Answers = []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class},
+			Body:   &dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}},
+		}}
+	}
+	resp, _ := msg.Pack()
+	return resp
+}
+
+func echoTCP(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(c, c)
+		}
+	}()
+	return ln
+}
+
+// request dials the server and sends a SOCKS5 request for cmd and addr. It
+// returns the connection and the reply.
+func request(t *testing.T, s *Server, cmd byte, addr []byte) (net.Conn, []byte) {
+	t.Helper()
+	c, err := net.Dial("tcp", s.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := c.Write([]byte{socksVersion, 1, methodNoAuth}); err != nil {
+		t.Fatal(err)
+	}
+	method := make([]byte, 2)
+	if _, err := io.ReadFull(c, method); err != nil {
+		t.Fatal(err)
+	}
+	if method[1] != methodNoAuth {
+		t.Fatalf("got method %d, want %d", method[1], methodNoAuth)
+	}
+
+	if _, err := c.Write(append([]byte{socksVersion, cmd, 0}, addr...)); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, 4+net.IPv4len+2)
+	if _, err := io.ReadFull(c, reply); err != nil {
+		t.Fatal(err)
+	}
+	return c, reply
+}
+
+func ipv4Address(t *testing.T, addr string) []byte {
+	t.Helper()
+	a, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return binary.BigEndian.AppendUint16(append([]byte{atypIPv4}, a.IP.To4()...), uint16(a.Port))
+}
+
+func domainAddress(host string, port uint16) []byte {
+	b := append([]byte{atypDomain, byte(len(host))}, host...)
+	return binary.BigEndian.AppendUint16(b, port)
+}
+
+func TestConnect(t *testing.T) {
+	ln := echoTCP(t)
+	defer ln.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	var p uint16
+	fmt.Sscan(port, &p)
+
+	s, err := Listen(context.Background(), agentConn{}, protocol.CapDNS, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, addr := range [][]byte{ipv4Address(t, ln.Addr().String()), domainAddress("echo.internal", p)} {
+		c, reply := request(t, s, cmdConnect, addr)
+		if reply[1] != repSucceeded {
+			t.Fatalf("got reply %d, want %d", reply[1], repSucceeded)
+		}
+
+		if _, err := c.Write([]byte("hello")); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 5)
+		if _, err := io.ReadFull(c, buf); err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != "hello" {
+			t.Errorf("got %q, want %q", buf, "hello")
+		}
+		c.Close()
+	}
+}
+
+func TestConnectFailures(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := ln.Addr().String()
+	ln.Close()
+
+	s, err := Listen(context.Background(), agentConn{}, 0, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	tests := []struct {
+		name string
+		cmd  byte
+		addr []byte
+		want byte
+	}{
+		{"refused", cmdConnect, ipv4Address(t, closed), repConnectionRefused},
+		{"no resolver", cmdConnect, domainAddress("echo.internal", 80), repHostUnreachable},
+		{"bind", cmdBind, ipv4Address(t, closed), repCommandNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, reply := request(t, s, tt.cmd, tt.addr)
+			defer c.Close()
+			if reply[1] != tt.want {
+				t.Errorf("got reply %d, want %d", reply[1], tt.want)
+			}
+		})
+	}
+}
+
+func TestUDPAssociate(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			pc.WriteTo(buf[:n], addr)
+		}
+	}()
+
+	s, err := Listen(context.Background(), agentConn{}, protocol.CapUDPFraming, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	c, reply := request(t, s, cmdUDPAssociate, ipv4Address(t, "0.0.0.0:0"))
+	defer c.Close()
+	if reply[1] != repSucceeded {
+		t.Fatalf("got reply %d, want %d", reply[1], repSucceeded)
+	}
+	relayAddr := &net.UDPAddr{IP: net.IP(reply[4:8]), Port: int(binary.BigEndian.Uint16(reply[8:]))}
+
+	u, err := net.DialUDP("udp", nil, relayAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer u.Close()
+	u.SetDeadline(time.Now().Add(5 * time.Second))
+
+	hdr := append([]byte{0, 0, 0}, ipv4Address(t, pc.LocalAddr().String())...)
+	for _, msg := range []string{"one", "two"} {
+		if _, err := u.Write(append(hdr, msg...)); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 1500)
+		n, err := u.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf[:len(hdr)], hdr) {
+			t.Errorf("got header %v, want %v", buf[:len(hdr)], hdr)
+		}
+		if got := string(buf[len(hdr):n]); got != msg {
+			t.Errorf("got %q, want %q", got, msg)
+		}
+	}
+}
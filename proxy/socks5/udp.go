@@ -0,0 +1,179 @@
+package socks5
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/relay"
+	"github.com/rs/zerolog/log"
+)
+
+// udpHeaderSize is the size of the reserved and fragment fields that start
+// every UDP request.
+const udpHeaderSize = 3
+
+// udpQueueSize is the number of datagrams queued per destination, more are
+// dropped. Datagrams queue up while the flow is set up.
+const udpQueueSize = 64
+
+// udpFlow carries the datagrams of an association to one destination.
+type udpFlow struct {
+	queue chan []byte
+}
+
+// handleAssociate relays the datagrams of a UDP association until the
+// client closes the control connection c. Every destination is relayed as a
+// separate flow through the agent, set up without holding up the others.
+func (s *Server) handleAssociate(ctx context.Context, c net.Conn) {
+	local := c.LocalAddr().(*net.TCPAddr)
+	pc, err := (&net.ListenConfig{}).ListenPacket(ctx, "udp", net.JoinHostPort(local.IP.String(), "0"))
+	if err != nil {
+		log.Error().Err(err).Msg("could not open UDP association")
+		writeReply(c, repGeneralFailure, nil)
+		return
+	}
+	defer pc.Close()
+
+	if err := writeReply(c, repSucceeded, pc.LocalAddr()); err != nil {
+		return
+	}
+
+	// The association lives as long as the control connection.
+	go func() {
+		io.Copy(io.Discard, c)
+		pc.Close()
+	}()
+	// Flows end with the association.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := c.RemoteAddr().(*net.TCPAddr).IP
+	var (
+		mu    sync.Mutex
+		flows = make(map[string]*udpFlow)
+		peer  net.Addr
+	)
+
+	// relayFlow sets up the flow to addr and relays its datagrams until it is
+	// idle or the association ends. hdr is the header of the first datagram.
+	relayFlow := func(key string, addr address, hdr []byte, flow *udpFlow) {
+		defer func() {
+			mu.Lock()
+			if flows[key] == flow {
+				delete(flows, key)
+			}
+			mu.Unlock()
+		}()
+
+		target, rep := s.target(ctx, addr, protocol.TransportUDP)
+		if rep != repSucceeded {
+			return
+		}
+		stream, rep := s.open(ctx, target)
+		if rep != repSucceeded {
+			return
+		}
+		conn := relay.NewIdleConn(relay.NewUDPStream(stream, s.caps), config.UDPIdleTimeout)
+		defer conn.Close()
+
+		// Answers carry the destination as it was requested.
+		hdr = append([]byte{0, 0, 0}, hdr[udpHeaderSize:]...)
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+
+			buf := make([]byte, len(hdr), len(hdr)+relay.MaxDatagramSize)
+			copy(buf, hdr)
+			for {
+				n, err := conn.Read(buf[len(hdr):cap(buf)])
+				if err != nil {
+					return
+				}
+
+				mu.Lock()
+				to := peer
+				mu.Unlock()
+				if _, err := pc.WriteTo(buf[:len(hdr)+n], to); err != nil {
+					return
+				}
+			}
+		}()
+
+		for {
+			select {
+			case <-closed:
+				return
+			case <-ctx.Done():
+				return
+			case payload := <-flow.queue:
+				if _, err := conn.Write(payload); err != nil {
+					log.Debug().Err(err).Msgf("could not relay SOCKS5 datagram to %s", key)
+				}
+			}
+		}
+	}
+
+	buf := make([]byte, relay.MaxDatagramSize)
+	for {
+		n, from, err := pc.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error().Err(err).Msg("could not read SOCKS5 datagram")
+			}
+			return
+		}
+		// Only the client that opened the association may use it.
+		if !from.(*net.UDPAddr).IP.Equal(client) {
+			continue
+		}
+
+		hdr, addr, payload, err := parseDatagram(buf[:n])
+		if err != nil {
+			log.Debug().Err(err).Msgf("dropping SOCKS5 datagram from %s", from)
+			continue
+		}
+
+		key := addr.String()
+		mu.Lock()
+		peer = from
+		flow, ok := flows[key]
+		if !ok {
+			flow = &udpFlow{queue: make(chan []byte, udpQueueSize)}
+			flows[key] = flow
+			go relayFlow(key, addr, bytes.Clone(hdr), flow)
+		}
+		mu.Unlock()
+
+		select {
+		case flow.queue <- bytes.Clone(payload):
+		default:
+			log.Debug().Msgf("dropping SOCKS5 datagram to %s, the queue is full", key)
+		}
+	}
+}
+
+// parseDatagram splits a SOCKS5 UDP request into its header, destination and
+// payload. Fragmented datagrams are not supported.
+func parseDatagram(b []byte) ([]byte, address, []byte, error) {
+	if len(b) < udpHeaderSize {
+		return nil, address{}, nil, io.ErrUnexpectedEOF
+	}
+	if b[2] != 0 {
+		return nil, address{}, nil, errors.New("fragmented datagrams are not supported")
+	}
+
+	r := bytes.NewReader(b[udpHeaderSize:])
+	addr, err := readAddress(r)
+	if err != nil {
+		return nil, address{}, nil, err
+	}
+
+	size := len(b) - r.Len()
+	return b[:size:size], addr, b[size:], nil
+}
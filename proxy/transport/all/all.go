@@ -0,0 +1,12 @@
+// Package all registers every transport, binaries import it for its side
+// effect:
+//
+//	import _ "github.com/fr13n8/raido/proxy/transport/all"
+package all
+
+import (
+	_ "github.com/fr13n8/raido/proxy/transport/quic"
+	_ "github.com/fr13n8/raido/proxy/transport/ssh"
+	_ "github.com/fr13n8/raido/proxy/transport/tcp"
+	_ "github.com/fr13n8/raido/proxy/transport/ws"
+)
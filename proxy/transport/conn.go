@@ -0,0 +1,61 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// AbortOnDone makes pending and later reads and writes on stream fail once ctx
+// is done. Closing is not enough on its own: the Close of a QUIC stream only
+// ends its send side and leaves a blocked Read waiting for the peer. stop
+// behaves like the one returned by context.AfterFunc.
+func AbortOnDone(ctx context.Context, stream Stream) (stop func() bool) {
+	return context.AfterFunc(ctx, func() {
+		if d, ok := stream.(interface{ SetDeadline(time.Time) error }); ok {
+			d.SetDeadline(time.Unix(1, 0))
+		}
+		stream.Close()
+	})
+}
+
+// NetConn wraps stream as a net.Conn with the given addresses. Deadlines are
+// applied if the stream supports them and ignored otherwise.
+func NetConn(stream Stream, local, remote net.Addr) net.Conn {
+	return &streamConn{Stream: stream, local: local, remote: remote}
+}
+
+type streamConn struct {
+	Stream
+	local  net.Addr
+	remote net.Addr
+}
+
+func (c *streamConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *streamConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *streamConn) SetDeadline(t time.Time) error {
+	if d, ok := c.Stream.(interface{ SetDeadline(time.Time) error }); ok {
+		return d.SetDeadline(t)
+	}
+	return nil
+}
+
+func (c *streamConn) SetReadDeadline(t time.Time) error {
+	if d, ok := c.Stream.(interface{ SetReadDeadline(time.Time) error }); ok {
+		return d.SetReadDeadline(t)
+	}
+	return nil
+}
+
+func (c *streamConn) SetWriteDeadline(t time.Time) error {
+	if d, ok := c.Stream.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		return d.SetWriteDeadline(t)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+// halfCloseStream behaves like a QUIC stream: Close only ends the send side,
+// a pending Read returns on its deadline.
+type halfCloseStream struct {
+	net.Conn
+}
+
+func (s halfCloseStream) Close() error {
+	return nil
+}
+
+func TestAbortOnDone(t *testing.T) {
+	a, b := net.Pipe()
+	defer b.Close()
+	stream := halfCloseStream{a}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	stop := AbortOnDone(ctx, stream)
+	defer stop()
+
+	read := make(chan error, 1)
+	go func() {
+		_, err := stream.Read(make([]byte, 1))
+		read <- err
+	}()
+
+	select {
+	case err := <-read:
+		if err == nil {
+			t.Fatal("read succeeded")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("read still blocked after ctx is done")
+	}
+}
@@ -0,0 +1,191 @@
+package transport
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrDatagramTooLarge is returned when a datagram does not fit into a single
+// unreliable datagram of the connection.
+var ErrDatagramTooLarge = errors.New("datagram too large")
+
+// DatagramConn is implemented by connections that can carry unreliable
+// datagrams next to their streams.
+type DatagramConn interface {
+	// SupportsDatagrams reports whether both peers enabled datagrams.
+	SupportsDatagrams() bool
+	SendDatagram(b []byte) error
+	ReceiveDatagram(ctx context.Context) ([]byte, error)
+}
+
+// flowIDSize is the size of the flow ID that prefixes every datagram.
+const flowIDSize = 4
+
+// flowQueueSize is the number of datagrams buffered per flow before new ones
+// are dropped.
+const flowQueueSize = 128
+
+// DatagramMux multiplexes flows over the datagrams of a single connection.
+// Every datagram starts with the 4 byte big endian ID of its flow.
+type DatagramMux struct {
+	conn   DatagramConn
+	nextID atomic.Uint32
+
+	mu    sync.Mutex
+	flows map[uint32]*DatagramFlow
+}
+
+func NewDatagramMux(conn DatagramConn) *DatagramMux {
+	return &DatagramMux{
+		conn:  conn,
+		flows: make(map[uint32]*DatagramFlow),
+	}
+}
+
+// Serve dispatches received datagrams to their flows until the context is
+// cancelled or the connection is closed.
+func (m *DatagramMux) Serve(ctx context.Context) error {
+	defer m.closeFlows()
+
+	for {
+		b, err := m.conn.ReceiveDatagram(ctx)
+		if err != nil {
+			return err
+		}
+		if len(b) < flowIDSize {
+			continue
+		}
+
+		m.mu.Lock()
+		flow, ok := m.flows[binary.BigEndian.Uint32(b)]
+		m.mu.Unlock()
+		if !ok {
+			continue
+		}
+		flow.deliver(b[flowIDSize:])
+	}
+}
+
+// Open creates a flow with a new ID.
+func (m *DatagramMux) Open() *DatagramFlow {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for {
+		id := m.nextID.Add(1)
+		if _, ok := m.flows[id]; ok || id == 0 {
+			continue
+		}
+		return m.register(id)
+	}
+}
+
+// Accept creates a flow for an ID chosen by the peer.
+func (m *DatagramMux) Accept(id uint32) (*DatagramFlow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.flows[id]; ok {
+		return nil, fmt.Errorf("flow %d already exists", id)
+	}
+	return m.register(id), nil
+}
+
+func (m *DatagramMux) register(id uint32) *DatagramFlow {
+	f := &DatagramFlow{
+		id:     id,
+		mux:    m,
+		queue:  make(chan []byte, flowQueueSize),
+		closed: make(chan struct{}),
+	}
+	m.flows[id] = f
+	return f
+}
+
+func (m *DatagramMux) remove(id uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.flows, id)
+}
+
+func (m *DatagramMux) closeFlows() {
+	m.mu.Lock()
+	flows := make([]*DatagramFlow, 0, len(m.flows))
+	for _, f := range m.flows {
+		flows = append(flows, f)
+	}
+	m.mu.Unlock()
+
+	for _, f := range flows {
+		f.Close()
+	}
+}
+
+// DatagramFlow is a single flow of a DatagramMux. Reads return one datagram
+// at a time, writes send a single datagram.
+type DatagramFlow struct {
+	id    uint32
+	mux   *DatagramMux
+	queue chan []byte
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (f *DatagramFlow) ID() uint32 {
+	return f.id
+}
+
+func (f *DatagramFlow) deliver(b []byte) {
+	select {
+	case f.queue <- b:
+	case <-f.closed:
+	default:
+		log.Debug().Uint32("flow", f.id).Msg("datagram queue full, dropping datagram")
+	}
+}
+
+// Read reads the next datagram into p, discarding what does not fit.
+func (f *DatagramFlow) Read(p []byte) (int, error) {
+	select {
+	case b := <-f.queue:
+		return copy(p, b), nil
+	case <-f.closed:
+		return 0, io.EOF
+	}
+}
+
+// Write sends p as a single datagram. It returns ErrDatagramTooLarge if p
+// does not fit, in which case the caller may use a stream instead.
+func (f *DatagramFlow) Write(p []byte) (int, error) {
+	select {
+	case <-f.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+
+	b := make([]byte, flowIDSize, flowIDSize+len(p))
+	binary.BigEndian.PutUint32(b, f.id)
+	b = append(b, p...)
+	if err := f.mux.conn.SendDatagram(b); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (f *DatagramFlow) Close() error {
+	f.closeOnce.Do(func() {
+		close(f.closed)
+		f.mux.remove(f.id)
+	})
+	return nil
+}
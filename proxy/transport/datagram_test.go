@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// pipeDatagramConn delivers datagrams to its peer in memory.
+type pipeDatagramConn struct {
+	in   chan []byte
+	out  chan []byte
+	max  int
+	done chan struct{}
+}
+
+func newPipeDatagramConns(max int) (*pipeDatagramConn, *pipeDatagramConn) {
+	a, b := make(chan []byte, 16), make(chan []byte, 16)
+	done := make(chan struct{})
+	return &pipeDatagramConn{in: a, out: b, max: max, done: done},
+		&pipeDatagramConn{in: b, out: a, max: max, done: done}
+}
+
+func (c *pipeDatagramConn) SupportsDatagrams() bool {
+	return true
+}
+
+func (c *pipeDatagramConn) SendDatagram(b []byte) error {
+	if len(b) > c.max {
+		return ErrDatagramTooLarge
+	}
+	c.out <- append([]byte(nil), b...)
+	return nil
+}
+
+func (c *pipeDatagramConn) ReceiveDatagram(ctx context.Context) ([]byte, error) {
+	select {
+	case b := <-c.in:
+		return b, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestDatagramMux(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	connA, connB := newPipeDatagramConns(64)
+	muxA, muxB := NewDatagramMux(connA), NewDatagramMux(connB)
+	serveErr := make(chan error, 2)
+	go func() { serveErr <- muxA.Serve(ctx) }()
+	go func() { serveErr <- muxB.Serve(ctx) }()
+
+	flowA1, flowA2 := muxA.Open(), muxA.Open()
+	if flowA1.ID() == flowA2.ID() {
+		t.Fatalf("flows share ID %d", flowA1.ID())
+	}
+	flowB1, err := muxB.Accept(flowA1.ID())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	flowB2, err := muxB.Accept(flowA2.ID())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := muxB.Accept(flowA1.ID()); err == nil {
+		t.Error("expected error for duplicate flow ID but got nil")
+	}
+
+	for _, tt := range []struct {
+		src, dst *DatagramFlow
+		data     string
+	}{
+		{flowA1, flowB1, "first flow"},
+		{flowA2, flowB2, "second flow"},
+		{flowB1, flowA1, "first flow reply"},
+	} {
+		if _, err := tt.src.Write([]byte(tt.data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		buf := make([]byte, 64)
+		n, err := tt.dst.Read(buf)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(buf[:n]) != tt.data {
+			t.Errorf("got %q, want %q", buf[:n], tt.data)
+		}
+	}
+
+	if _, err := flowA1.Write(make([]byte, 64)); !errors.Is(err, ErrDatagramTooLarge) {
+		t.Errorf("got error %v, want %v", err, ErrDatagramTooLarge)
+	}
+
+	flowB2.Close()
+	if _, err := flowB2.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Errorf("got error %v, want %v", err, io.EOF)
+	}
+
+	cancel()
+	for range 2 {
+		if err := <-serveErr; !errors.Is(err, context.Canceled) {
+			t.Errorf("got error %v, want %v", err, context.Canceled)
+		}
+	}
+	if _, err := flowA1.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Errorf("flows must be closed when Serve returns, got %v", err)
+	}
+}
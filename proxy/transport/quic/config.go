@@ -18,6 +18,8 @@ var (
 		MaxStreamReceiveWindow:     6 * (1 << 20),  // 6 MB
 		// InitialPacketSize:          1252,
 		Versions: []quic.Version{quic.Version2},
+		// Datagrams carry UDP flows without head-of-line blocking.
+		EnableDatagrams: true,
 		// Tracer:   NewClientTracer(&log.Logger, 1),
 	}
 )
@@ -3,7 +3,10 @@ package quic
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
 	"net"
+	"net/url"
 
 	"github.com/fr13n8/raido/proxy/transport"
 	"github.com/quic-go/quic-go"
@@ -15,11 +18,25 @@ type QUICTransport struct {
 	quicConfig *quic.Config
 }
 
+func init() {
+	transport.Register("quic", func(u *url.URL, opts transport.Options) (transport.Transport, error) {
+		tlsConfig, err := transport.TLSConfig(u, opts)
+		if err != nil {
+			return nil, err
+		}
+		return NewQUICTransport(tlsConfig), nil
+	})
+}
+
 // NewQUICTransport creates a new QUICTransport instance.
 func NewQUICTransport(tlsConfig *tls.Config) *QUICTransport {
 	return &QUICTransport{tlsConfig: tlsConfig, quicConfig: qConfig}
 }
 
+func (t *QUICTransport) Name() string {
+	return "quic"
+}
+
 func (t *QUICTransport) Dial(ctx context.Context, addr string) (transport.StreamConn, error) {
 	conn, err := quic.DialAddr(ctx, addr, t.tlsConfig, t.quicConfig)
 	if err != nil {
@@ -61,6 +78,27 @@ func (c *QUICStreamConn) CloseWithError(code uint64, reason string) error {
 	return c.conn.CloseWithError(quic.ApplicationErrorCode(code), reason)
 }
 
+func (c *QUICStreamConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+func (c *QUICStreamConn) SupportsDatagrams() bool {
+	state := c.conn.ConnectionState().SupportsDatagrams
+	return state.Local && state.Remote
+}
+
+func (c *QUICStreamConn) SendDatagram(b []byte) error {
+	err := c.conn.SendDatagram(b)
+	if tooLarge := (*quic.DatagramTooLargeError)(nil); errors.As(err, &tooLarge) {
+		return fmt.Errorf("%w: maximum is %d bytes", transport.ErrDatagramTooLarge, tooLarge.MaxDatagramPayloadSize)
+	}
+	return err
+}
+
+func (c *QUICStreamConn) ReceiveDatagram(ctx context.Context) ([]byte, error) {
+	return c.conn.ReceiveDatagram(ctx)
+}
+
 func (c *QUICStreamConn) GetStream(ctx context.Context) (transport.Stream, error) {
 	return c.streamPool.Get(ctx)
 }
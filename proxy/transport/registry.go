@@ -0,0 +1,156 @@
+package transport
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+)
+
+// Options are the settings a transport is created with besides its URI.
+type Options struct {
+	// TLSConfig holds the certificate of the proxy. Agents leave it nil, their
+	// configuration is built from the URI by ClientTLSConfig.
+	TLSConfig *tls.Config
+	// Key is the SSH host key of the proxy or the identity key of the agent.
+	Key crypto.Signer
+}
+
+// Listening reports whether the transport is created for the proxy side.
+func (o Options) Listening() bool {
+	return o.TLSConfig != nil
+}
+
+// Factory creates the transport for the URI u.
+type Factory func(u *url.URL, opts Options) (Transport, error)
+
+var registry = struct {
+	sync.RWMutex
+	factories map[string]Factory
+}{factories: make(map[string]Factory)}
+
+// Register makes the transport created by f available under the URI scheme
+// scheme. It is meant to be called from the init function of the transport's
+// package and panics if the scheme is already registered.
+func Register(scheme string, f Factory) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.factories[scheme]; ok {
+		panic(fmt.Sprintf("transport %s registered twice", scheme))
+	}
+	registry.factories[scheme] = f
+}
+
+// Schemes returns the registered URI schemes in lexical order.
+func Schemes() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	schemes := make([]string, 0, len(registry.factories))
+	for s := range registry.factories {
+		schemes = append(schemes, s)
+	}
+	slices.Sort(schemes)
+	return schemes
+}
+
+// ParseURI parses a transport URI such as quic://host:8787?ch=<hash>. The
+// scheme selects the transport and the host is the address of the proxy.
+// Query parameters configure the transport:
+//
+//	ch               hex SHA-256 hash the proxy certificate must match
+//	insecure=true    skip the verification of the proxy certificate
+//
+// Transports may accept further parameters.
+func ParseURI(uri string) (*url.URL, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid transport URI \"%s\", please use scheme://host:port", uri)
+	}
+	if _, _, err := net.SplitHostPort(u.Host); err != nil {
+		return nil, fmt.Errorf("invalid address in transport URI \"%s\", please use host:port: %w", uri, err)
+	}
+	return u, nil
+}
+
+// New creates the transport for the URI u with the factory registered for
+// its scheme.
+func New(u *url.URL, opts Options) (Transport, error) {
+	registry.RLock()
+	f, ok := registry.factories[u.Scheme]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported transport protocol: %s", u.Scheme)
+	}
+
+	return f(u, opts)
+}
+
+// CertHash returns the decoded ch parameter of u, nil if it is not set.
+func CertHash(u *url.URL) ([]byte, error) {
+	ch := u.Query().Get("ch")
+	if ch == "" {
+		return nil, nil
+	}
+	hash, err := hex.DecodeString(ch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode certificate hash: %w", err)
+	}
+	return hash, nil
+}
+
+// Insecure reports whether u disables the verification of the proxy.
+func Insecure(u *url.URL) bool {
+	insecure, _ := strconv.ParseBool(u.Query().Get("insecure"))
+	return insecure
+}
+
+// ClientTLSConfig returns the TLS configuration agents connect to the proxy
+// at u with. The proxy certificate is checked against the ch parameter if it
+// is set and against the system roots otherwise.
+func ClientTLSConfig(u *url.URL) (*tls.Config, error) {
+	crtMatch, err := CertHash(u)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS13,
+		NextProtos:         []string{protocol.Name},
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: Insecure(u),
+	}
+	if crtMatch != nil {
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+			crtFingerprint := sha256.Sum256(rawCerts[0])
+			if !bytes.Equal(crtMatch, crtFingerprint[:]) {
+				return fmt.Errorf("certificate hash mismatch %x != %x", crtMatch, crtFingerprint[:])
+			}
+			return nil
+		}
+	}
+	return tlsConfig, nil
+}
+
+// TLSConfig returns the TLS configuration of the side opts are for.
+func TLSConfig(u *url.URL, opts Options) (*tls.Config, error) {
+	if opts.Listening() {
+		return opts.TLSConfig, nil
+	}
+	return ClientTLSConfig(u)
+}
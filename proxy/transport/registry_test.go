@@ -0,0 +1,94 @@
+package transport
+
+import (
+	"crypto/tls"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+// fakeTransport remembers the URI and options it was created with.
+type fakeTransport struct {
+	Transport
+	u    *url.URL
+	opts Options
+}
+
+func TestRegistry(t *testing.T) {
+	Register("fake", func(u *url.URL, opts Options) (Transport, error) {
+		return &fakeTransport{u: u, opts: opts}, nil
+	})
+	if !slices.Contains(Schemes(), "fake") {
+		t.Fatalf("Schemes() = %v, want fake", Schemes())
+	}
+
+	u, err := ParseURI("fake://10.0.0.1:8787?ch=ab01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := &tls.Config{}
+	tr, err := New(u, Options{TLSConfig: tc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ft := tr.(*fakeTransport)
+	if ft.u.Host != "10.0.0.1:8787" || ft.opts.TLSConfig != tc || !ft.opts.Listening() {
+		t.Fatalf("created with %v %+v", ft.u, ft.opts)
+	}
+
+	u, _ = ParseURI("unknown://10.0.0.1:8787")
+	if _, err := New(u, Options{}); err == nil {
+		t.Fatal("created a transport for an unregistered scheme")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering a scheme twice did not panic")
+		}
+	}()
+	Register("fake", nil)
+}
+
+func TestParseURI(t *testing.T) {
+	for _, uri := range []string{
+		"10.0.0.1:8787",
+		"quic://10.0.0.1",
+		"quic:///path",
+		"://10.0.0.1:8787",
+	} {
+		if _, err := ParseURI(uri); err == nil {
+			t.Errorf("ParseURI(%q) succeeded", uri)
+		}
+	}
+
+	u, err := ParseURI("tcp+tls://proxy.example.com:443?ch=00ff&insecure=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "tcp+tls" || u.Host != "proxy.example.com:443" {
+		t.Fatalf("ParseURI() = %v", u)
+	}
+	hash, err := CertHash(u)
+	if err != nil || !slices.Equal(hash, []byte{0x00, 0xff}) {
+		t.Fatalf("CertHash() = %x, %v", hash, err)
+	}
+	if !Insecure(u) {
+		t.Fatal("Insecure() = false")
+	}
+
+	tc, err := ClientTLSConfig(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tc.ServerName != "proxy.example.com" || tc.VerifyPeerCertificate == nil {
+		t.Fatalf("ClientTLSConfig() does not verify %s by hash", tc.ServerName)
+	}
+	if err := tc.VerifyPeerCertificate([][]byte{[]byte("other")}, nil); err == nil {
+		t.Fatal("accepted a certificate with another hash")
+	}
+
+	u, _ = ParseURI("quic://10.0.0.1:8787?ch=zz")
+	if _, err := CertHash(u); err == nil {
+		t.Fatal("decoded an invalid hash")
+	}
+}
@@ -0,0 +1,420 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/ssh"
+)
+
+// channelType is the type of the SSH channels carrying streams, channels of
+// other types are rejected.
+const channelType = protocol.Name
+
+func init() {
+	transport.Register("ssh", func(u *url.URL, opts transport.Options) (transport.Transport, error) {
+		if opts.Key == nil {
+			return nil, errors.New("the ssh transport needs a key")
+		}
+		if opts.Listening() {
+			var sshOpt Option
+			switch path := u.Query().Get("authorized_keys"); {
+			case path != "":
+				keys, err := LoadAuthorizedKeys(path)
+				if err != nil {
+					return nil, err
+				}
+				sshOpt = WithAuthorizedKeys(keys...)
+			case transport.Insecure(u):
+				sshOpt = WithAnyClientKey()
+			default:
+				return nil, errors.New("the ssh transport needs the authorized keys of the agents or insecure=true")
+			}
+			return NewSSHTransport(opts.Key, sshOpt)
+		}
+
+		// The hash is the one of the proxy's host key.
+		hash, err := transport.CertHash(u)
+		if err != nil {
+			return nil, err
+		}
+		var hostKeyCallback ssh.HostKeyCallback
+		switch {
+		case hash != nil:
+			hostKeyCallback = HostKeyHash(hash)
+		case transport.Insecure(u):
+			hostKeyCallback = ssh.InsecureIgnoreHostKey()
+		default:
+			return nil, errors.New("the ssh transport needs the host key hash of the proxy (ch) or insecure=true")
+		}
+		return NewSSHTransport(opts.Key, WithHostKeyCallback(hostKeyCallback))
+	})
+}
+
+// SSHTransport implements the Transport interface for SSH, every stream is an
+// SSH channel. The proxy runs an embedded SSH server and agents authenticate
+// with a public key.
+type SSHTransport struct {
+	// signer is the host key when listening and the client key when dialing.
+	signer          ssh.Signer
+	hostKeyCallback ssh.HostKeyCallback
+	// authorized are the client keys accepted by the server.
+	authorized []ssh.PublicKey
+	// anyKey makes the server accept every client key.
+	anyKey bool
+}
+
+type Option func(*SSHTransport)
+
+// WithHostKeyCallback sets how the client verifies the host key of the
+// server, see HostKeyHash. Dialing fails without it.
+func WithHostKeyCallback(cb ssh.HostKeyCallback) Option {
+	return func(t *SSHTransport) {
+		t.hostKeyCallback = cb
+	}
+}
+
+// WithAuthorizedKeys restricts the server to clients authenticating with one
+// of keys. Listening fails without it or WithAnyClientKey.
+func WithAuthorizedKeys(keys ...ssh.PublicKey) Option {
+	return func(t *SSHTransport) {
+		t.authorized = keys
+	}
+}
+
+// WithAnyClientKey makes the server accept clients authenticating with any
+// key. Listening fails without it or WithAuthorizedKeys.
+func WithAnyClientKey() Option {
+	return func(t *SSHTransport) {
+		t.anyKey = true
+	}
+}
+
+// NewSSHTransport creates a new SSHTransport instance. key is the host key of
+// the server when listening and the key the client authenticates with when
+// dialing.
+func NewSSHTransport(key crypto.Signer, opts ...Option) (*SSHTransport, error) {
+	signer, err := ssh.NewSignerFromSigner(key)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported SSH key: %w", err)
+	}
+
+	t := &SSHTransport{signer: signer}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t, nil
+}
+
+// HashHostKey returns the SHA-256 hash of the wire encoding of key, the
+// value HostKeyHash compares against.
+func HashHostKey(key ssh.PublicKey) []byte {
+	h := sha256.Sum256(key.Marshal())
+	return h[:]
+}
+
+// HostKeyHash returns a callback accepting only the host key whose
+// HashHostKey is hash.
+func HostKeyHash(hash []byte) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if got := HashHostKey(key); !bytes.Equal(got, hash) {
+			return fmt.Errorf("host key hash mismatch %x != %x", hash, got)
+		}
+		return nil
+	}
+}
+
+// LoadAuthorizedKeys reads the public keys of an OpenSSH authorized_keys
+// file.
+func LoadAuthorizedKeys(path string) ([]ssh.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read authorized keys: %w", err)
+	}
+
+	var keys []ssh.PublicKey
+	for len(bytes.TrimSpace(data)) > 0 {
+		key, _, _, rest, err := ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse authorized keys in %s: %w", path, err)
+		}
+		keys = append(keys, key)
+		data = rest
+	}
+	return keys, nil
+}
+
+// PublicKey returns the public part of the transport's key.
+func (t *SSHTransport) PublicKey() ssh.PublicKey {
+	return t.signer.PublicKey()
+}
+
+func (t *SSHTransport) Name() string {
+	return "ssh"
+}
+
+// Dial connects to the SSH server at addr and authenticates with the client
+// key.
+func (t *SSHTransport) Dial(ctx context.Context, addr string) (transport.StreamConn, error) {
+	if t.hostKeyCallback == nil {
+		return nil, errors.New("no host key callback to verify the server with")
+	}
+
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not dial address: %w", err)
+	}
+
+	// Abort the handshake when ctx is done.
+	stop := context.AfterFunc(ctx, func() {
+		c.Close()
+	})
+	defer stop()
+
+	c.SetDeadline(time.Now().Add(config.HandshakeTimeout))
+	conn, chans, reqs, err := ssh.NewClientConn(c, addr, &ssh.ClientConfig{
+		User:            protocol.Name,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(t.signer)},
+		HostKeyCallback: t.hostKeyCallback,
+	})
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("SSH handshake failed: %w", err)
+	}
+	c.SetDeadline(time.Time{})
+
+	return newStreamConn(conn, chans, reqs), nil
+}
+
+// Listen runs an SSH server on addr.
+func (t *SSHTransport) Listen(ctx context.Context, addr string) (transport.StreamListener, error) {
+	if len(t.authorized) == 0 && !t.anyKey {
+		return nil, errors.New("no authorized keys to verify clients with")
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &ssh.ServerConfig{
+		PublicKeyCallback: t.authorize,
+	}
+	cfg.AddHostKey(t.signer)
+
+	l := &SSHStreamListener{
+		listener: ln,
+		config:   cfg,
+		conns:    make(chan transport.StreamConn),
+		done:     make(chan struct{}),
+	}
+	go l.serve()
+
+	return l, nil
+}
+
+// authorize accepts the keys the transport was configured with.
+func (t *SSHTransport) authorize(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	if t.anyKey {
+		return nil, nil
+	}
+	for _, k := range t.authorized {
+		if bytes.Equal(k.Marshal(), key.Marshal()) {
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("key %s of %s is not authorized", ssh.FingerprintSHA256(key), meta.RemoteAddr())
+}
+
+// SSHStreamConn wraps an SSH connection as a StreamConn.
+type SSHStreamConn struct {
+	conn       ssh.Conn
+	chans      <-chan ssh.NewChannel
+	streamPool *transport.StreamPool
+}
+
+func newStreamConn(conn ssh.Conn, chans <-chan ssh.NewChannel, reqs <-chan *ssh.Request) *SSHStreamConn {
+	go ssh.DiscardRequests(reqs)
+	go keepAlive(conn)
+
+	c := &SSHStreamConn{conn: conn, chans: chans}
+	// Opening a channel waits for the peer to accept it, so none are opened
+	// ahead: the peer only accepts streams once its handshake returned.
+	c.streamPool = transport.NewStreamPool(0, c)
+	return c
+}
+
+// keepAlive closes conn when the peer stops answering requests, SSH itself
+// does not notice a dead connection.
+func keepAlive(conn ssh.Conn) {
+	closed := make(chan struct{})
+	go func() {
+		conn.Wait()
+		close(closed)
+	}()
+
+	ticker := time.NewTicker(config.SSHKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+		}
+
+		replied := make(chan error, 1)
+		go func() {
+			// Any reply, even a refusal, shows the peer is alive.
+			_, _, err := conn.SendRequest("keepalive@openssh.com", true, nil)
+			replied <- err
+		}()
+
+		timer := time.NewTimer(config.SSHKeepAlive)
+		select {
+		case err := <-replied:
+			timer.Stop()
+			if err == nil {
+				continue
+			}
+		case <-closed:
+			timer.Stop()
+			return
+		case <-timer.C:
+			log.Debug().Msgf("SSH connection to %s timed out", conn.RemoteAddr())
+		}
+		conn.Close()
+		return
+	}
+}
+
+func (c *SSHStreamConn) OpenStream(ctx context.Context) (transport.Stream, error) {
+	ch, reqs, err := c.conn.OpenChannel(channelType, nil)
+	if err != nil {
+		return nil, err
+	}
+	go ssh.DiscardRequests(reqs)
+	return ch, nil
+}
+
+func (c *SSHStreamConn) AcceptStream(ctx context.Context) (transport.Stream, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case nc, ok := <-c.chans:
+			if !ok {
+				return nil, io.EOF
+			}
+			if nc.ChannelType() != channelType {
+				nc.Reject(ssh.UnknownChannelType, "unsupported channel type")
+				continue
+			}
+
+			ch, reqs, err := nc.Accept()
+			if err != nil {
+				return nil, err
+			}
+			go ssh.DiscardRequests(reqs)
+			return ch, nil
+		}
+	}
+}
+
+func (c *SSHStreamConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *SSHStreamConn) CloseWithError(code uint64, reason string) error {
+	return c.conn.Close()
+}
+
+func (c *SSHStreamConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+func (c *SSHStreamConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	return c.streamPool.Get(ctx)
+}
+
+func (c *SSHStreamConn) PutStream(stream transport.Stream) {
+	c.streamPool.Put(stream)
+}
+
+// SSHStreamListener accepts SSH connections, running their handshakes
+// concurrently so a slow client does not hold up the others.
+type SSHStreamListener struct {
+	listener  net.Listener
+	config    *ssh.ServerConfig
+	conns     chan transport.StreamConn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (l *SSHStreamListener) serve() {
+	for {
+		c, err := l.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error().Err(err).Msg("could not accept SSH connection")
+			}
+			return
+		}
+		go l.handshake(c)
+	}
+}
+
+func (l *SSHStreamListener) handshake(c net.Conn) {
+	c.SetDeadline(time.Now().Add(config.HandshakeTimeout))
+	conn, chans, reqs, err := ssh.NewServerConn(c, l.config)
+	if err != nil {
+		log.Debug().Err(err).Msgf("SSH handshake with %s failed", c.RemoteAddr())
+		c.Close()
+		return
+	}
+	c.SetDeadline(time.Time{})
+
+	select {
+	case l.conns <- newStreamConn(conn, chans, reqs):
+	case <-l.done:
+		conn.Close()
+	}
+}
+
+func (l *SSHStreamListener) Accept(ctx context.Context) (transport.StreamConn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *SSHStreamListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.done)
+		err = l.listener.Close()
+	})
+	return err
+}
+
+func (l *SSHStreamListener) Addr() net.Addr {
+	return l.listener.Addr()
+}
@@ -0,0 +1,148 @@
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fr13n8/raido/proxy/transport"
+	"golang.org/x/crypto/ssh"
+)
+
+func newKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func listen(t *testing.T, opts ...Option) (*SSHTransport, transport.StreamListener) {
+	t.Helper()
+	server, err := NewSSHTransport(newKey(t), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := server.Listen(context.Background(), "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	return server, ln
+}
+
+func echo(ctx context.Context, conn transport.StreamConn) {
+	for {
+		s, err := conn.AcceptStream(ctx)
+		if err != nil {
+			return
+		}
+		go func() {
+			defer s.Close()
+			io.Copy(s, s)
+		}()
+	}
+}
+
+func roundTrip(t *testing.T, ctx context.Context, conn transport.StreamConn) {
+	t.Helper()
+	s, err := conn.GetStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(s, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "hello" {
+		t.Errorf("got %q, want %q", buf, "hello")
+	}
+}
+
+func TestStreams(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	clientKey := newKey(t)
+	pub, _ := ssh.NewPublicKey(clientKey.Public())
+	server, ln := listen(t, WithAuthorizedKeys(pub))
+
+	client, err := NewSSHTransport(clientKey, WithHostKeyCallback(HostKeyHash(HashHostKey(server.PublicKey()))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := client.Dial(ctx, ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	go echo(ctx, conn)
+
+	accepted, err := ln.Accept(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer accepted.Close()
+	go echo(ctx, accepted)
+
+	// Streams are opened by both sides.
+	roundTrip(t, ctx, conn)
+	roundTrip(t, ctx, accepted)
+}
+
+func TestAuthentication(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	authorized, _ := ssh.NewPublicKey(newKey(t).Public())
+	server, ln := listen(t, WithAuthorizedKeys(authorized))
+
+	client, _ := NewSSHTransport(newKey(t), WithHostKeyCallback(HostKeyHash(HashHostKey(server.PublicKey()))))
+	if _, err := client.Dial(ctx, ln.Addr().String()); err == nil {
+		t.Error("dial succeeded with an unauthorized key")
+	}
+
+	client, _ = NewSSHTransport(newKey(t), WithHostKeyCallback(HostKeyHash(make([]byte, 32))))
+	if _, err := client.Dial(ctx, ln.Addr().String()); err == nil {
+		t.Error("dial succeeded with a wrong host key hash")
+	}
+}
+
+func TestListenNeedsAuthorizedKeys(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	server, err := NewSSHTransport(newKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ln, err := server.Listen(ctx, "127.0.0.1:0"); err == nil {
+		ln.Close()
+		t.Fatal("listening without authorized keys succeeded")
+	}
+
+	for _, uri := range []string{"ssh://127.0.0.1:0", "ssh://127.0.0.1:0?insecure=false"} {
+		u, _ := url.Parse(uri)
+		if _, err := transport.New(u, transport.Options{TLSConfig: &tls.Config{}, Key: newKey(t)}); err == nil {
+			t.Errorf("%s: created a listening transport without authorized keys", uri)
+		}
+	}
+
+	// Any key is accepted only when asked for.
+	server, ln := listen(t, WithAnyClientKey())
+	client, _ := NewSSHTransport(newKey(t), WithHostKeyCallback(HostKeyHash(HashHostKey(server.PublicKey()))))
+	conn, err := client.Dial(ctx, ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net"
+	"net/url"
 
 	"github.com/fr13n8/raido/proxy/transport"
 	"github.com/hashicorp/yamux"
@@ -15,11 +16,28 @@ type TCPTransport struct {
 	tlsConfig *tls.Config // Optional TLS configuration
 }
 
+func init() {
+	newTransport := func(u *url.URL, opts transport.Options) (transport.Transport, error) {
+		tlsConfig, err := transport.TLSConfig(u, opts)
+		if err != nil {
+			return nil, err
+		}
+		return NewTCPTransport(tlsConfig), nil
+	}
+	// tcp always runs over TLS, tcp+tls spells it out.
+	transport.Register("tcp", newTransport)
+	transport.Register("tcp+tls", newTransport)
+}
+
 // NewTCPTransport creates a new TCPTransport instance.
 func NewTCPTransport(tlsConfig *tls.Config) *TCPTransport {
 	return &TCPTransport{tlsConfig: tlsConfig}
 }
 
+func (t *TCPTransport) Name() string {
+	return "tcp"
+}
+
 // Dial establishes a TCP connection and wraps it with a yamux session.
 func (t *TCPTransport) Dial(ctx context.Context, addr string) (transport.StreamConn, error) {
 	var conn net.Conn
@@ -58,6 +76,23 @@ func (t *TCPTransport) Listen(ctx context.Context, addr string) (transport.Strea
 	return &TCPStreamListener{listener: listener}, nil
 }
 
+// Server runs the proxy side of the transport on an existing connection,
+// such as one relayed by another agent.
+func (t *TCPTransport) Server(conn net.Conn) (transport.StreamConn, error) {
+	if t.tlsConfig != nil {
+		conn = tls.Server(conn, t.tlsConfig)
+	}
+	session, err := yamux.Server(conn, nil)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not establish yamux session: %w", err)
+	}
+
+	streamConn := &TCPStreamConn{session: session}
+	streamConn.streamPool = transport.NewStreamPool(16, streamConn)
+	return streamConn, nil
+}
+
 // TCPStreamConn wraps a yamux session as a StreamConn.
 type TCPStreamConn struct {
 	session    *yamux.Session
@@ -80,6 +115,10 @@ func (c *TCPStreamConn) CloseWithError(code uint64, reason string) error {
 	return c.session.Close()
 }
 
+func (c *TCPStreamConn) RemoteAddr() net.Addr {
+	return c.session.RemoteAddr()
+}
+
 func (c *TCPStreamConn) GetStream(ctx context.Context) (transport.Stream, error) {
 	return c.streamPool.Get(ctx)
 }
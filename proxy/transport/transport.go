@@ -18,6 +18,8 @@ type StreamConn interface {
 	AcceptStream(ctx context.Context) (Stream, error)
 	Close() error
 	CloseWithError(code uint64, reason string) error
+	// RemoteAddr returns the address of the peer.
+	RemoteAddr() net.Addr
 
 	// GetStream and PutStream are used to manage streams in a pool.
 	GetStream(ctx context.Context) (Stream, error)
@@ -33,6 +35,16 @@ type StreamListener interface {
 
 // Transport defines the interface for establishing connections and managing streams.
 type Transport interface {
+	// Name returns the name the transport is selected by, e.g. "quic".
+	Name() string
 	Dial(ctx context.Context, addr string) (StreamConn, error)
 	Listen(ctx context.Context, addr string) (StreamListener, error)
 }
+
+// Upgrader is implemented by transports that can run on top of an existing
+// connection, such as the stream relaying a downstream agent.
+type Upgrader interface {
+	Name() string
+	// Server runs the proxy side of the transport on conn.
+	Server(conn net.Conn) (StreamConn, error)
+}
@@ -0,0 +1,302 @@
+package ws
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/hashicorp/yamux"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/websocket"
+)
+
+// DefaultPath is the HTTP path the WebSocket is served on by default.
+const DefaultPath = "/"
+
+func init() {
+	newTransport := func(u *url.URL, opts transport.Options) (transport.Transport, error) {
+		var wsOpts []Option
+		if u.Path != "" {
+			wsOpts = append(wsOpts, WithPath(u.Path))
+		}
+		if p := u.Query().Get("proxy"); p != "" {
+			proxyURL, err := url.Parse(p)
+			if err != nil || proxyURL.Host == "" {
+				return nil, fmt.Errorf("invalid HTTP proxy \"%s\", please use http://host:port", p)
+			}
+			wsOpts = append(wsOpts, WithHTTPProxy(proxyURL))
+		}
+
+		if u.Scheme == "ws" {
+			return NewWSTransport(nil, wsOpts...), nil
+		}
+		tlsConfig, err := transport.TLSConfig(u, opts)
+		if err != nil {
+			return nil, err
+		}
+		return NewWSTransport(tlsConfig, wsOpts...), nil
+	}
+	transport.Register("ws", newTransport)
+	transport.Register("wss", newTransport)
+}
+
+// WSTransport implements the Transport interface for yamux multiplexing over a
+// WebSocket, which passes networks that only allow web traffic. With a TLS
+// configuration it speaks wss, otherwise ws.
+type WSTransport struct {
+	tlsConfig *tls.Config
+	// proxy returns the HTTP proxy to connect through for a request, nil for
+	// a direct connection.
+	proxy func(*http.Request) (*url.URL, error)
+	path  string
+}
+
+type Option func(*WSTransport)
+
+// WithHTTPProxy makes the transport connect through the HTTP proxy at u with
+// a CONNECT request. By default the proxy is taken from the HTTPS_PROXY,
+// HTTP_PROXY and NO_PROXY environment variables.
+func WithHTTPProxy(u *url.URL) Option {
+	return func(t *WSTransport) {
+		t.proxy = http.ProxyURL(u)
+	}
+}
+
+// WithPath sets the HTTP path of the WebSocket, DefaultPath if not set.
+func WithPath(path string) Option {
+	return func(t *WSTransport) {
+		t.path = path
+	}
+}
+
+// NewWSTransport creates a new WSTransport instance. tlsConfig may be nil for
+// plain ws.
+func NewWSTransport(tlsConfig *tls.Config, opts ...Option) *WSTransport {
+	t := &WSTransport{proxy: http.ProxyFromEnvironment, path: DefaultPath}
+	if tlsConfig != nil {
+		// Look like any other HTTPS connection to middleboxes.
+		t.tlsConfig = tlsConfig.Clone()
+		t.tlsConfig.NextProtos = []string{"http/1.1"}
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func (t *WSTransport) Name() string {
+	if t.tlsConfig != nil {
+		return "wss"
+	}
+	return "ws"
+}
+
+// Dial connects to the WebSocket at addr, through the HTTP proxy if one is
+// configured, and wraps it with a yamux session.
+func (t *WSTransport) Dial(ctx context.Context, addr string) (transport.StreamConn, error) {
+	conn, err := t.dial(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not dial address: %w", err)
+	}
+
+	// Bound the TLS handshake and the WebSocket upgrade.
+	conn.SetDeadline(time.Now().Add(config.HandshakeTimeout))
+	if t.tlsConfig != nil {
+		tlsConn := tls.Client(conn, t.tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	scheme, origin := "ws", "http"
+	if t.tlsConfig != nil {
+		scheme, origin = "wss", "https"
+	}
+	wsConfig, err := websocket.NewConfig(scheme+"://"+addr+t.path, origin+"://"+addr)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	ws, err := websocket.NewClient(wsConfig, conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("WebSocket handshake failed: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+	ws.PayloadType = websocket.BinaryFrame
+
+	session, err := yamux.Client(ws, nil)
+	if err != nil {
+		ws.Close()
+		return nil, fmt.Errorf("could not establish yamux session: %w", err)
+	}
+
+	return newStreamConn(session, conn.RemoteAddr()), nil
+}
+
+// dial opens the TCP connection to addr, through the HTTP proxy chosen for it
+// if any.
+func (t *WSTransport) dial(ctx context.Context, addr string) (net.Conn, error) {
+	// The proxy is chosen like for the equivalent HTTP request.
+	scheme := "http"
+	if t.tlsConfig != nil {
+		scheme = "https"
+	}
+	proxyURL, err := t.proxy(&http.Request{URL: &url.URL{Scheme: scheme, Host: addr}})
+	if err != nil {
+		return nil, fmt.Errorf("could not get HTTP proxy: %w", err)
+	}
+	if proxyURL != nil {
+		return transport.DialHTTPProxy(ctx, proxyURL, addr)
+	}
+
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", addr)
+}
+
+// Listen serves WebSocket connections on addr and wraps them with yamux.
+func (t *WSTransport) Listen(ctx context.Context, addr string) (transport.StreamListener, error) {
+	var ln net.Listener
+	var err error
+	if t.tlsConfig != nil {
+		ln, err = tls.Listen("tcp", addr, t.tlsConfig)
+	} else {
+		ln, err = net.Listen("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l := &WSStreamListener{
+		listener: ln,
+		conns:    make(chan transport.StreamConn),
+		done:     make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.Handle(t.path, websocket.Server{Handler: l.handle})
+	l.srv = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	go func() {
+		if err := l.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("WebSocket listener stopped")
+		}
+	}()
+
+	return l, nil
+}
+
+// WSStreamConn wraps a yamux session over a WebSocket as a StreamConn.
+type WSStreamConn struct {
+	session    *yamux.Session
+	remote     net.Addr
+	streamPool *transport.StreamPool
+}
+
+func newStreamConn(session *yamux.Session, remote net.Addr) *WSStreamConn {
+	c := &WSStreamConn{session: session, remote: remote}
+	c.streamPool = transport.NewStreamPool(16, c)
+	return c
+}
+
+func (c *WSStreamConn) OpenStream(ctx context.Context) (transport.Stream, error) {
+	return c.session.OpenStream()
+}
+
+func (c *WSStreamConn) AcceptStream(ctx context.Context) (transport.Stream, error) {
+	return c.session.AcceptStream()
+}
+
+func (c *WSStreamConn) Close() error {
+	return c.session.Close()
+}
+
+func (c *WSStreamConn) CloseWithError(code uint64, reason string) error {
+	return c.session.Close()
+}
+
+func (c *WSStreamConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *WSStreamConn) GetStream(ctx context.Context) (transport.Stream, error) {
+	return c.streamPool.Get(ctx)
+}
+
+func (c *WSStreamConn) PutStream(stream transport.Stream) {
+	c.streamPool.Put(stream)
+}
+
+// WSStreamListener hands out the yamux sessions of the WebSocket connections
+// accepted by its HTTP server.
+type WSStreamListener struct {
+	listener  net.Listener
+	srv       *http.Server
+	conns     chan transport.StreamConn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// handle runs a WebSocket connection, which ends when handle returns.
+func (l *WSStreamListener) handle(ws *websocket.Conn) {
+	ws.PayloadType = websocket.BinaryFrame
+
+	session, err := yamux.Server(ws, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("could not establish yamux session")
+		return
+	}
+	var remote net.Addr
+	if ap, err := netip.ParseAddrPort(ws.Request().RemoteAddr); err == nil {
+		remote = net.TCPAddrFromAddrPort(ap)
+	}
+
+	select {
+	case l.conns <- newStreamConn(session, remote):
+	case <-l.done:
+		session.Close()
+		return
+	}
+
+	select {
+	case <-session.CloseChan():
+	case <-l.done:
+		session.Close()
+	}
+}
+
+func (l *WSStreamListener) Accept(ctx context.Context) (transport.StreamConn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *WSStreamListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.done)
+		err = l.srv.Close()
+	})
+	return err
+}
+
+func (l *WSStreamListener) Addr() net.Addr {
+	return l.listener.Addr()
+}
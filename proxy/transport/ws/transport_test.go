@@ -0,0 +1,152 @@
+package ws
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fr13n8/raido/config"
+)
+
+// connectProxy is an HTTP proxy answering CONNECT requests.
+func connectProxy(t *testing.T, tunnels *atomic.Int32) *url.URL {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect || r.Header.Get("Proxy-Authorization") == "" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer target.Close()
+		tunnels.Add(1)
+
+		c, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		c.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+		go io.Copy(target, rw)
+		io.Copy(c, target)
+	}))
+	t.Cleanup(srv.Close)
+
+	u, _ := url.Parse(srv.URL)
+	u.User = url.UserPassword("user", "secret")
+	return u
+}
+
+func TestDialThroughHTTPProxy(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tr := NewWSTransport(nil)
+	ln, err := tr.Listen(ctx, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var tunnels atomic.Int32
+	client := NewWSTransport(nil, WithHTTPProxy(connectProxy(t, &tunnels)))
+	conn, err := client.Dial(ctx, ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	server, err := ln.Accept(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	if tunnels.Load() != 1 {
+		t.Errorf("got %d CONNECT tunnels, want 1", tunnels.Load())
+	}
+	if server.RemoteAddr() == nil {
+		t.Error("accepted connection has no remote address")
+	}
+
+	go func() {
+		for {
+			s, err := server.AcceptStream(ctx)
+			if err != nil {
+				return
+			}
+			go io.Copy(s, s)
+		}
+	}()
+
+	s, err := conn.GetStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(s, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "hello" {
+		t.Errorf("got %q, want %q", buf, "hello")
+	}
+}
+
+func TestHTTPProxyRefused(t *testing.T) {
+	var tunnels atomic.Int32
+	proxyURL := connectProxy(t, &tunnels)
+	proxyURL.User = nil
+
+	client := NewWSTransport(nil, WithHTTPProxy(proxyURL))
+	if _, err := client.Dial(context.Background(), "127.0.0.1:1"); err == nil {
+		t.Fatal("dial succeeded although the proxy refused to connect")
+	}
+}
+
+func TestDialUpgradeTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { config.HandshakeTimeout = timeout }(config.HandshakeTimeout)
+	config.HandshakeTimeout = 50 * time.Millisecond
+
+	// The server accepts connections but never answers the upgrade.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewWSTransport(nil).Dial(context.Background(), ln.Addr().String())
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("dial succeeded without an upgrade")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("dial still blocked in the upgrade")
+	}
+}
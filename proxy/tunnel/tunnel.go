@@ -3,21 +3,32 @@ package tunnel
 import (
 	"context"
 	"fmt"
+	"slices"
 
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/protocol"
 	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/fr13n8/raido/utils/ip"
 	"github.com/fr13n8/raido/viface/netstack"
 	"github.com/fr13n8/raido/viface/sysnetops"
 	"github.com/fr13n8/raido/viface/tun"
 )
 
 type Tunnel struct {
+	// ctx and ct are kept to rebuild the network stack when the tunnel is
+	// rebound to a new connection.
+	ctx          context.Context
+	ct           config.Conntrack
 	stack        *netstack.NetStack
 	device       tun.TUNDevice
 	link         *sysnetops.LinkTun
 	activeRoutes []string
+	// resolver is set when the agent answers DNS queries sent to the
+	// resolver address of the loopback range.
+	resolver bool
 }
 
-func NewTunnel(ctx context.Context, conn transport.StreamConn) (*Tunnel, error) {
+func NewTunnel(ctx context.Context, conn transport.StreamConn, caps protocol.Capability, ct config.Conntrack) (*Tunnel, error) {
 	link, err := sysnetops.NewLinkTun()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TUN interface: %w", err)
@@ -27,26 +38,41 @@ func NewTunnel(ctx context.Context, conn transport.StreamConn) (*Tunnel, error)
 		return nil, fmt.Errorf("failed to add loopback route: %w", err)
 	}
 
+	resolver := caps.Has(protocol.CapDNS)
+	if resolver {
+		if err := addResolverRoute(link); err != nil {
+			return nil, fmt.Errorf("failed to add resolver route: %w", err)
+		}
+	}
+
 	tun, err := tun.Open(link.Name())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open TUN device: %w", err)
 	}
 
-	s, err := netstack.NewNetStack(ctx, tun.Device(), conn)
+	s, err := netstack.NewNetStack(ctx, tun.Device(), conn, caps, ct)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create network stack: %w", err)
 	}
 
 	return &Tunnel{
-		stack:  s,
-		link:   link,
-		device: tun,
+		ctx:      ctx,
+		ct:       ct,
+		stack:    s,
+		link:     link,
+		device:   tun,
+		resolver: resolver,
 	}, nil
 }
 
 func (t *Tunnel) Close() error {
-	t.device.Device().Close()
-	t.stack.Close()
+	// A failed Rebind leaves the tunnel without a stack and device.
+	if t.stack != nil {
+		t.stack.Close()
+	}
+	if t.device != nil {
+		t.device.Close()
+	}
 
 	if err := t.link.Destroy(); err != nil {
 		return fmt.Errorf("failed to destroy TUN device: %w", err)
@@ -55,6 +81,55 @@ func (t *Tunnel) Close() error {
 	return nil
 }
 
+// Rebind moves the tunnel to conn, a new connection to the same agent. The
+// TUN interface keeps its routes and loopback address, only the connections
+// relayed over the previous connection are lost. If Rebind fails the tunnel
+// can only be closed.
+func (t *Tunnel) Rebind(conn transport.StreamConn, caps protocol.Capability) error {
+	// The agent may have come back with a different set of capabilities.
+	// Nothing is torn down yet if the route cannot be changed.
+	if resolver := caps.Has(protocol.CapDNS); resolver != t.resolver {
+		route, err := resolverRoute(t.link)
+		if err != nil {
+			return err
+		}
+		if resolver {
+			err = t.link.AddRoutes(route)
+		} else {
+			err = t.link.RemoveRoutes(route)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update resolver route: %w", err)
+		}
+		t.resolver = resolver
+	}
+
+	// The TUN device has a single queue, the old stack has to let go of it
+	// before a new one can attach. Forget both so Close does not close them
+	// again if the new ones cannot be created.
+	t.stack.Close()
+	t.stack = nil
+	err := t.device.Close()
+	t.device = nil
+	if err != nil {
+		return fmt.Errorf("failed to close TUN device: %w", err)
+	}
+
+	device, err := tun.Open(t.link.Name())
+	if err != nil {
+		return fmt.Errorf("failed to open TUN device: %w", err)
+	}
+	s, err := netstack.NewNetStack(t.ctx, device.Device(), conn, caps, t.ct)
+	if err != nil {
+		device.Close()
+		return fmt.Errorf("failed to create network stack: %w", err)
+	}
+	t.device = device
+	t.stack = s
+
+	return nil
+}
+
 func (t *Tunnel) Name() string {
 	return t.link.Name()
 }
@@ -82,18 +157,9 @@ func (t *Tunnel) RemoveRoutes(routes ...string) error {
 		return fmt.Errorf("failed to remove routes: %w", err)
 	}
 
-	var newRoutes []string
-	for _, r := range t.activeRoutes {
-		for _, route := range routes {
-			if r == route {
-				continue
-			}
-
-			newRoutes = append(newRoutes, r)
-		}
-	}
-
-	t.activeRoutes = newRoutes
+	t.activeRoutes = slices.DeleteFunc(t.activeRoutes, func(r string) bool {
+		return slices.Contains(routes, r)
+	})
 
 	return nil
 }
@@ -123,6 +189,12 @@ func (t *Tunnel) Resume() error {
 		return fmt.Errorf("failed to add loopback route: %w", err)
 	}
 
+	if t.resolver {
+		if err := addResolverRoute(t.link); err != nil {
+			return fmt.Errorf("failed to add resolver route: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -147,3 +219,57 @@ func (t *Tunnel) GetLoopbackRoute() (string, error) {
 
 	return addr, nil
 }
+
+func (t *Tunnel) GetLoopbackRoute6() (string, error) {
+	addr, err := t.link.GetLoopbackRoute6()
+	if err != nil {
+		return "", fmt.Errorf("failed to get IPv6 address: %w", err)
+	}
+
+	return addr, nil
+}
+
+// ResolverAddress returns the address of the DNS resolver in the tunnel's
+// loopback range, or an empty string if the agent does not resolve names.
+func (t *Tunnel) ResolverAddress() (string, error) {
+	if !t.resolver {
+		return "", nil
+	}
+
+	loopback, err := t.GetLoopbackRoute()
+	if err != nil || loopback == "" {
+		return "", err
+	}
+	addr, err := ip.ParseNetAddress(loopback)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse loopback route: %w", err)
+	}
+
+	return ip.ResolverAddress(addr.IP).String(), nil
+}
+
+// addResolverRoute routes the resolver address of the link's loopback range
+// into the tunnel.
+func addResolverRoute(link *sysnetops.LinkTun) error {
+	route, err := resolverRoute(link)
+	if err != nil {
+		return err
+	}
+
+	return link.AddRoutes(route)
+}
+
+// resolverRoute returns the route of the resolver address in the link's
+// loopback range.
+func resolverRoute(link *sysnetops.LinkTun) (string, error) {
+	loopback, err := link.GetLoopbackRoute()
+	if err != nil {
+		return "", err
+	}
+	addr, err := ip.ParseNetAddress(loopback)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse loopback route: %w", err)
+	}
+
+	return ip.ResolverAddress(addr.IP).String() + "/32", nil
+}
@@ -0,0 +1,87 @@
+// Package identity manages the key an agent proves its identity with. The
+// proxy derives the agent ID from the public key, so an agent keeps its ID
+// across reconnects and restarts as long as it keeps its key.
+package identity
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/lithammer/shortuuid/v4"
+)
+
+const pemType = "PRIVATE KEY"
+
+// Generate returns a new random identity key.
+func Generate() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate identity key: %w", err)
+	}
+	return key, nil
+}
+
+// Load reads the identity key stored at path. A new key is generated and
+// stored there if the file does not exist yet.
+func Load(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return create(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read identity key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemType {
+		return nil, fmt.Errorf("no identity key found in %s", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse identity key: %w", err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("identity key in %s is not an ed25519 key", path)
+	}
+	return key, nil
+}
+
+func create(path string) (ed25519.PrivateKey, error) {
+	key, err := Generate()
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode identity key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("could not create identity directory: %w", err)
+	}
+	// Fail rather than overwrite a key written concurrently by another agent.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("could not store identity key: %w", err)
+	}
+	defer f.Close()
+	if err := pem.Encode(f, &pem.Block{Type: pemType, Bytes: der}); err != nil {
+		return nil, fmt.Errorf("could not store identity key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ID returns the agent ID belonging to the public key pub.
+func ID(pub ed25519.PublicKey) string {
+	return shortuuid.NewWithNamespace(hex.EncodeToString(pub))
+}
@@ -0,0 +1,45 @@
+package identity
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raido", "agent.key")
+
+	key, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("key stored with mode %o, want 600", perm)
+	}
+
+	again, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.Equal(again) {
+		t.Error("reloaded key differs from the generated one")
+	}
+}
+
+func TestID(t *testing.T) {
+	a, _ := Generate()
+	b, _ := Generate()
+
+	idA := ID(a.Public().(ed25519.PublicKey))
+	if idA != ID(a.Public().(ed25519.PublicKey)) {
+		t.Error("ID is not stable for the same key")
+	}
+	if idA == ID(b.Public().(ed25519.PublicKey)) {
+		t.Error("different keys share an ID")
+	}
+}
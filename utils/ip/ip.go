@@ -8,10 +8,45 @@ import (
 var (
 	LoopbackRoute   NetAddress
 	loopbackNetwork = "240.0.0.0/4"
+
+	// LoopbackRoute6 is the IPv6 counterpart of LoopbackRoute, a unique
+	// local range whose addresses are forwarded to the agent's ::1.
+	LoopbackRoute6   NetAddress
+	loopbackNetwork6 = "fd52:6169:646f::/48"
 )
 
 func init() {
 	LoopbackRoute, _ = ParseNetAddress(loopbackNetwork)
+	LoopbackRoute6, _ = ParseNetAddress(loopbackNetwork6)
+}
+
+// IsLoopback reports whether addr belongs to the IPv4 or IPv6 loopback range.
+func IsLoopback(addr net.IP) bool {
+	return LoopbackRoute.Network.Contains(addr) || LoopbackRoute6.Network.Contains(addr)
+}
+
+// LoopbackAddress6 returns the IPv6 loopback address of the tunnel whose IPv4
+// loopback address is 240.n.0.0, e.g. fd52:6169:646f:1:: for 240.1.0.0.
+func LoopbackAddress6(n byte) net.IP {
+	addr := make(net.IP, net.IPv6len)
+	copy(addr, LoopbackRoute6.Network.IP)
+	addr[7] = n
+	return addr
+}
+
+// LoopbackIndex returns n for the loopback addresses of the tunnel n,
+// 240.n.0.0 and fd52:6169:646f:n::, and false for addresses of other ranges.
+func LoopbackIndex(addr net.IP) (byte, bool) {
+	if v4 := addr.To4(); v4 != nil {
+		if v4[0] != 240 {
+			return 0, false
+		}
+		return v4[1], true
+	}
+	if LoopbackRoute6.Network.Contains(addr) {
+		return addr[7], true
+	}
+	return 0, false
 }
 
 type NetAddress struct {
@@ -34,3 +69,22 @@ func (addr NetAddress) String() string {
 	maskSize, _ := addr.Network.Mask.Size()
 	return fmt.Sprintf("%s/%d", addr.IP.String(), maskSize)
 }
+
+// resolverHost is the last octet of the resolver address in a tunnel's
+// loopback range, e.g. 240.1.0.53 for the loopback route 240.1.0.0/32.
+const resolverHost = 53
+
+// ResolverAddress returns the address of the DNS resolver exposed in the
+// loopback range of loopback.
+func ResolverAddress(loopback net.IP) net.IP {
+	addr := make(net.IP, net.IPv4len)
+	copy(addr, loopback.To4())
+	addr[3] = resolverHost
+	return addr
+}
+
+// IsResolverAddress reports whether addr is the resolver address of a tunnel.
+func IsResolverAddress(addr net.IP) bool {
+	addr = addr.To4()
+	return addr != nil && LoopbackRoute.Network.Contains(addr) && addr[2] == 0 && addr[3] == resolverHost
+}
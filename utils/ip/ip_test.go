@@ -0,0 +1,62 @@
+package ip
+
+import (
+	"net"
+	"testing"
+)
+
+func TestLoopbackAddress6(t *testing.T) {
+	tests := []struct {
+		n    byte
+		want string
+	}{
+		{0, "fd52:6169:646f::"},
+		{1, "fd52:6169:646f:1::"},
+		{0x7f, "fd52:6169:646f:7f::"},
+		{0xff, "fd52:6169:646f:ff::"},
+	}
+	for _, tt := range tests {
+		got := LoopbackAddress6(tt.n)
+		if !got.Equal(net.ParseIP(tt.want)) {
+			t.Errorf("LoopbackAddress6(%d) = %s, want %s", tt.n, got, tt.want)
+		}
+		if n, ok := LoopbackIndex(got); !ok || n != tt.n {
+			t.Errorf("LoopbackIndex(%s) = %d, %t, want %d", got, n, ok, tt.n)
+		}
+	}
+}
+
+func TestLoopback(t *testing.T) {
+	tests := []struct {
+		addr     string
+		loopback bool
+		index    byte
+		tunnel   bool
+	}{
+		{"240.0.0.0", true, 0, true},
+		{"240.1.0.0", true, 1, true},
+		{"240.255.0.0", true, 255, true},
+		{"240.1.0.53", true, 1, true},
+		{"255.255.255.255", true, 0, false},
+		{"239.255.255.255", false, 0, false},
+		{"127.0.0.1", false, 0, false},
+		{"::ffff:240.1.0.0", true, 1, true},
+		{"fd52:6169:646f::", true, 0, true},
+		{"fd52:6169:646f:1::", true, 1, true},
+		{"fd52:6169:646f:ff::1", true, 255, true},
+		{"fd52:6169:646f:ffff:ffff:ffff:ffff:ffff", true, 255, true},
+		{"fd52:6169:6470::", false, 0, false},
+		// The first byte of an IPv6 address is not the 240 of 240/4.
+		{"f000::1", false, 0, false},
+		{"::1", false, 0, false},
+	}
+	for _, tt := range tests {
+		addr := net.ParseIP(tt.addr)
+		if got := IsLoopback(addr); got != tt.loopback {
+			t.Errorf("IsLoopback(%s) = %t, want %t", tt.addr, got, tt.loopback)
+		}
+		if n, ok := LoopbackIndex(addr); n != tt.index || ok != tt.tunnel {
+			t.Errorf("LoopbackIndex(%s) = %d, %t, want %d, %t", tt.addr, n, ok, tt.index, tt.tunnel)
+		}
+	}
+}
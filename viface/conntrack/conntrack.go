@@ -0,0 +1,207 @@
+// Package conntrack tracks the UDP flows forwarded by a tunnel so that idle
+// flows are expired and their resources released.
+package conntrack
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/fr13n8/raido/config"
+	"github.com/rs/zerolog/log"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+)
+
+// Table tracks the UDP flows of a tunnel.
+type Table struct {
+	idleTimeout time.Duration
+	maxFlows    int
+
+	mu    sync.Mutex
+	flows map[stack.TransportEndpointID]*Flow
+	// lru orders the flows from the most to the least recently active.
+	lru *list.List
+}
+
+// New creates a table with the limits of cfg. Zero values select the
+// defaults from the config package.
+func New(cfg config.Conntrack) *Table {
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = config.UDPIdleTimeout
+	}
+	if cfg.MaxFlows <= 0 {
+		cfg.MaxFlows = config.UDPMaxFlows
+	}
+
+	return &Table{
+		idleTimeout: cfg.IdleTimeout,
+		maxFlows:    cfg.MaxFlows,
+		flows:       make(map[stack.TransportEndpointID]*Flow),
+		lru:         list.New(),
+	}
+}
+
+// Run expires idle flows until the context is cancelled, then closes all
+// remaining flows.
+func (t *Table) Run(ctx context.Context) {
+	ticker := time.NewTicker(max(t.idleTimeout/4, 100*time.Millisecond))
+	defer ticker.Stop()
+	defer t.closeAll()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			t.expire(now)
+		}
+	}
+}
+
+// Add starts tracking conn as the flow id. If the table is full the least
+// recently used flow is evicted first.
+func (t *Table) Add(id stack.TransportEndpointID, conn io.ReadWriteCloser) *Flow {
+	f := &Flow{ReadWriteCloser: conn, id: id, table: t}
+
+	var closed []*Flow
+	t.mu.Lock()
+	if old, ok := t.flows[id]; ok {
+		t.unlink(old)
+		closed = append(closed, old)
+	}
+	if len(t.flows) >= t.maxFlows {
+		evicted := t.lru.Back().Value.(*Flow)
+		log.Debug().Msgf("conntrack table full, evicting UDP flow %s", evicted)
+		t.unlink(evicted)
+		closed = append(closed, evicted)
+	}
+	f.lastActive = time.Now()
+	f.elem = t.lru.PushFront(f)
+	t.flows[id] = f
+	t.mu.Unlock()
+
+	for _, o := range closed {
+		o.Close()
+	}
+
+	return f
+}
+
+// Len returns the number of tracked flows.
+func (t *Table) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.flows)
+}
+
+func (t *Table) expire(now time.Time) {
+	deadline := now.Add(-t.idleTimeout)
+
+	var idle []*Flow
+	t.mu.Lock()
+	for e := t.lru.Back(); e != nil; {
+		f := e.Value.(*Flow)
+		if !f.lastActive.Before(deadline) {
+			break
+		}
+		e = e.Prev()
+		t.unlink(f)
+		idle = append(idle, f)
+	}
+	t.mu.Unlock()
+
+	for _, f := range idle {
+		log.Debug().Msgf("UDP flow %s idle, closing", f)
+		f.Close()
+	}
+}
+
+// touch marks f as the most recently active flow.
+func (t *Table) touch(f *Flow) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f.lastActive = time.Now()
+	if f.elem != nil {
+		t.lru.MoveToFront(f.elem)
+	}
+}
+
+func (t *Table) remove(f *Flow) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if f.elem != nil {
+		t.unlink(f)
+	}
+}
+
+// unlink stops tracking f, t.mu must be held.
+func (t *Table) unlink(f *Flow) {
+	delete(t.flows, f.id)
+	t.lru.Remove(f.elem)
+	f.elem = nil
+}
+
+func (t *Table) closeAll() {
+	t.mu.Lock()
+	var flows []*Flow
+	for e := t.lru.Front(); e != nil; e = e.Next() {
+		f := e.Value.(*Flow)
+		f.elem = nil
+		flows = append(flows, f)
+	}
+	t.flows = make(map[stack.TransportEndpointID]*Flow)
+	t.lru.Init()
+	t.mu.Unlock()
+
+	for _, f := range flows {
+		f.Close()
+	}
+}
+
+// Flow is a tracked connection. Reads and writes mark it as active.
+type Flow struct {
+	io.ReadWriteCloser
+	id    stack.TransportEndpointID
+	table *Table
+	// elem is the flow's entry in the table's LRU list, nil once the flow
+	// is no longer tracked. elem and lastActive are guarded by the table.
+	elem       *list.Element
+	lastActive time.Time
+	closeOnce  sync.Once
+}
+
+func (f *Flow) Read(p []byte) (int, error) {
+	n, err := f.ReadWriteCloser.Read(p)
+	if n > 0 {
+		f.table.touch(f)
+	}
+	return n, err
+}
+
+func (f *Flow) Write(p []byte) (int, error) {
+	n, err := f.ReadWriteCloser.Write(p)
+	if n > 0 {
+		f.table.touch(f)
+	}
+	return n, err
+}
+
+// Close stops tracking the flow and closes its connection.
+func (f *Flow) Close() error {
+	var err error
+	f.closeOnce.Do(func() {
+		f.table.remove(f)
+		err = f.ReadWriteCloser.Close()
+	})
+	return err
+}
+
+func (f *Flow) String() string {
+	return fmt.Sprintf("%s->%s",
+		net.JoinHostPort(f.id.RemoteAddress.String(), fmt.Sprint(f.id.RemotePort)),
+		net.JoinHostPort(f.id.LocalAddress.String(), fmt.Sprint(f.id.LocalPort)))
+}
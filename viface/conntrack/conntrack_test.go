@@ -0,0 +1,128 @@
+package conntrack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fr13n8/raido/config"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+)
+
+// fakeConn records whether it was closed.
+type fakeConn struct {
+	closed bool
+}
+
+func (c *fakeConn) Read(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (c *fakeConn) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func flowID(port uint16) stack.TransportEndpointID {
+	return stack.TransportEndpointID{LocalPort: 53, RemotePort: port}
+}
+
+func TestTable(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Conntrack
+		// run uses the table holding flows, flows[i] is tracked as port i.
+		run func(t *Table, flows []*Flow)
+		// closed lists the ports whose connection is closed.
+		closed []int
+	}{
+		{
+			name: "idle flows expire",
+			cfg:  config.Conntrack{IdleTimeout: time.Minute},
+			run: func(t *Table, flows []*Flow) {
+				time.Sleep(time.Millisecond)
+				flows[1].Write([]byte("active"))
+				t.expire(flows[1].lastActive.Add(time.Minute))
+			},
+			closed: []int{0, 2},
+		},
+		{
+			name: "active flows are kept",
+			cfg:  config.Conntrack{IdleTimeout: time.Minute},
+			run: func(t *Table, flows []*Flow) {
+				t.expire(time.Now())
+			},
+		},
+		{
+			name: "least recently used flow is evicted",
+			cfg:  config.Conntrack{MaxFlows: 3},
+			run: func(t *Table, flows []*Flow) {
+				flows[0].Read(make([]byte, 1))
+				t.Add(flowID(3), &fakeConn{})
+			},
+			closed: []int{1},
+		},
+		{
+			name: "evictions keep the limit",
+			cfg:  config.Conntrack{MaxFlows: 3},
+			run: func(t *Table, flows []*Flow) {
+				for port := range uint16(4) {
+					t.Add(flowID(10+port), &fakeConn{})
+				}
+			},
+			closed: []int{0, 1, 2},
+		},
+		{
+			name: "flow replaced by the same ID",
+			cfg:  config.Conntrack{},
+			run: func(t *Table, flows []*Flow) {
+				t.Add(flowID(2), &fakeConn{})
+			},
+			closed: []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := New(tt.cfg)
+			conns := make([]*fakeConn, 3)
+			flows := make([]*Flow, len(conns))
+			for i := range conns {
+				conns[i] = &fakeConn{}
+				flows[i] = table.Add(flowID(uint16(i)), conns[i])
+			}
+
+			tt.run(table, flows)
+
+			want := make(map[int]bool)
+			for _, port := range tt.closed {
+				want[port] = true
+			}
+			for i, c := range conns {
+				if c.closed != want[i] {
+					t.Errorf("flow %d closed = %t, want %t", i, c.closed, want[i])
+				}
+			}
+			if tt.cfg.MaxFlows > 0 && table.Len() > tt.cfg.MaxFlows {
+				t.Errorf("table holds %d flows, limit is %d", table.Len(), tt.cfg.MaxFlows)
+			}
+			if table.Len() != table.lru.Len() {
+				t.Errorf("table holds %d flows but %d LRU entries", table.Len(), table.lru.Len())
+			}
+		})
+	}
+}
+
+func TestFlowClose(t *testing.T) {
+	table := New(config.Conntrack{})
+	c := &fakeConn{}
+	f := table.Add(flowID(1), c)
+
+	f.Close()
+	if !c.closed || table.Len() != 0 || table.lru.Len() != 0 {
+		t.Errorf("closed flow still tracked, %d flows", table.Len())
+	}
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"time"
+
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/protocol"
+	"github.com/fr13n8/raido/proxy/relay"
+	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/fr13n8/raido/utils/ip"
+	"github.com/fr13n8/raido/viface/conntrack"
+	"github.com/rs/zerolog/log"
+	"gvisor.dev/gvisor/pkg/tcpip/adapters/gonet"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+	"gvisor.dev/gvisor/pkg/tcpip/transport/tcp"
+	"gvisor.dev/gvisor/pkg/tcpip/transport/udp"
+	"gvisor.dev/gvisor/pkg/waiter"
+)
+
+// dnsPort is the port the tunnel resolver listens on.
+const dnsPort = 53
+
+// TransportHandler is the signature of a stack transport protocol handler.
+type TransportHandler func(stack.TransportEndpointID, *stack.PacketBuffer) bool
+
+// DNSHandler answers DNS queries sent to the resolver address of a tunnel by
+// forwarding them to the agent, which resolves them with its system resolver.
+type DNSHandler struct {
+	ctx       context.Context
+	stack     *stack.Stack
+	conn      transport.StreamConn
+	conntrack *conntrack.Table
+	forwarder *tcp.Forwarder
+}
+
+func NewDNSHandler(ctx context.Context, s *stack.Stack, conn transport.StreamConn, ct *conntrack.Table) *DNSHandler {
+	h := &DNSHandler{ctx: ctx, stack: s, conn: conn, conntrack: ct}
+	h.forwarder = tcp.NewForwarder(s, 0, 64, func(fr *tcp.ForwarderRequest) {
+		h.handleTCP(ctx, fr)
+	})
+	return h
+}
+
+// UDP returns a UDP protocol handler that takes queries to the resolver and
+// passes any other packet to next.
+func (h *DNSHandler) UDP(next TransportHandler) TransportHandler {
+	return func(id stack.TransportEndpointID, pkt *stack.PacketBuffer) bool {
+		if !isResolver(id) {
+			return next(id, pkt)
+		}
+
+		fr := udp.NewForwarderRequest(h.stack, id, pkt)
+		var wq waiter.Queue
+		ep, tcperr := fr.CreateEndpoint(&wq)
+		if tcperr != nil {
+			log.Error().Msgf("could not create UDP endpoint: %s", tcperr)
+			return true
+		}
+
+		go h.handleUDP(h.ctx, h.conntrack.Add(id, gonet.NewUDPConn(&wq, ep)))
+		return true
+	}
+}
+
+// TCP returns a TCP protocol handler that takes connections to the resolver
+// and passes any other packet to next.
+func (h *DNSHandler) TCP(next TransportHandler) TransportHandler {
+	return func(id stack.TransportEndpointID, pkt *stack.PacketBuffer) bool {
+		if !isResolver(id) {
+			return next(id, pkt)
+		}
+		return h.forwarder.HandlePacket(id, pkt)
+	}
+}
+
+func (h *DNSHandler) handleUDP(ctx context.Context, conn io.ReadWriteCloser) {
+	defer conn.Close()
+
+	buf := make([]byte, relay.MaxDatagramSize)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+
+		query := append([]byte(nil), buf[:n]...)
+		go func() {
+			resp, err := h.resolve(ctx, protocol.ResolveRequest{Query: query})
+			if err != nil {
+				log.Error().Err(err).Msg("could not resolve DNS query")
+				return
+			}
+			if _, err := conn.Write(resp); err != nil {
+				log.Debug().Err(err).Msg("could not write DNS response")
+			}
+		}()
+	}
+}
+
+// handleTCP answers the length-prefixed queries of a DNS over TCP connection
+// one at a time.
+func (h *DNSHandler) handleTCP(ctx context.Context, fr *tcp.ForwarderRequest) {
+	var wq waiter.Queue
+	ep, tcperr := fr.CreateEndpoint(&wq)
+	if tcperr != nil {
+		log.Error().Msgf("failed to create TCP endpoint: %s", tcperr)
+		fr.Complete(true)
+		return
+	}
+	fr.Complete(false)
+
+	conn := gonet.NewTCPConn(&wq, ep)
+	defer conn.Close()
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(config.UDPIdleTimeout))
+
+		var size uint16
+		if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+			return
+		}
+		query := make([]byte, size)
+		if _, err := io.ReadFull(conn, query); err != nil {
+			return
+		}
+
+		resp, err := h.resolve(ctx, protocol.ResolveRequest{MaxSize: math.MaxUint16, Query: query})
+		if err != nil {
+			log.Error().Err(err).Msg("could not resolve DNS query")
+			return
+		}
+
+		msg := binary.BigEndian.AppendUint16(make([]byte, 0, 2+len(resp)), uint16(len(resp)))
+		if _, err := conn.Write(append(msg, resp...)); err != nil {
+			return
+		}
+	}
+}
+
+// resolve sends req to the agent, giving up after config.ResolveTimeout.
+func (h *DNSHandler) resolve(ctx context.Context, req protocol.ResolveRequest) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.ResolveTimeout)
+	defer cancel()
+
+	stream, err := h.conn.GetStream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not open stream: %w", err)
+	}
+	// The stream is done after a single request and response.
+	defer stream.Close()
+
+	// Unblock the request when the agent does not answer in time.
+	stop := transport.AbortOnDone(ctx, stream)
+	defer stop()
+
+	resp, err := protocol.Resolve(stream, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) == 0 {
+		return nil, errors.New("empty DNS response")
+	}
+
+	return resp, nil
+}
+
+func isResolver(id stack.TransportEndpointID) bool {
+	return id.LocalPort == dnsPort && ip.IsResolverAddress(net.IP(id.LocalAddress.AsSlice()))
+}
@@ -1,104 +1,186 @@
 package handler
 
 import (
-	"bytes"
 	"context"
-	"errors"
 
+	"github.com/fr13n8/raido/proxy/protocol"
 	"github.com/fr13n8/raido/proxy/transport"
 	"github.com/rs/zerolog/log"
 	"gvisor.dev/gvisor/pkg/buffer"
 	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/checksum"
 	"gvisor.dev/gvisor/pkg/tcpip/header"
-	"gvisor.dev/gvisor/pkg/tcpip/network/ipv4"
 	"gvisor.dev/gvisor/pkg/tcpip/stack"
-	"gvisor.dev/gvisor/pkg/tcpip/transport/icmp"
-	"gvisor.dev/gvisor/pkg/tcpip/transport/raw"
-	"gvisor.dev/gvisor/pkg/waiter"
 )
 
+// ICMPHandler proxies ICMP echo requests to the agent and injects the replies
+// back into the stack.
 type ICMPHandler struct {
+	ctx   context.Context
 	stack *stack.Stack
+	nicID tcpip.NICID
 	conn  transport.StreamConn
 }
 
-func NewICMPHandler(s *stack.Stack, conn transport.StreamConn) *ICMPHandler {
-	return &ICMPHandler{stack: s, conn: conn}
+func NewICMPHandler(ctx context.Context, s *stack.Stack, nicID tcpip.NICID, conn transport.StreamConn) *ICMPHandler {
+	return &ICMPHandler{ctx: ctx, stack: s, nicID: nicID, conn: conn}
 }
 
-func (h *ICMPHandler) Start(ctx context.Context) error {
-	var wq waiter.Queue
+// echoPacket is an ICMP echo request read from the link.
+type echoPacket struct {
+	src, dst tcpip.Address
+	id, seq  uint16
+	data     []byte
+	quote    packetQuote
+}
 
-	ep, err := raw.NewEndpoint(h.stack, ipv4.ProtocolNumber, icmp.ProtocolNumber4, &wq)
-	if err != nil {
-		log.Error().Msgf("Could not create raw endpoint: %s", err.String())
-		return errors.New(err.String())
+// HandlePacket takes ICMPv4 and ICMPv6 echo requests before they reach the
+// stack, which would otherwise answer them on behalf of every destination.
+// It returns false for any other packet.
+func (h *ICMPHandler) HandlePacket(proto tcpip.NetworkProtocolNumber, pkt *stack.PacketBuffer) bool {
+	var (
+		echo echoPacket
+		ok   bool
+	)
+	switch proto {
+	case header.IPv4ProtocolNumber:
+		echo, ok = parseEchoV4(pkt)
+	case header.IPv6ProtocolNumber:
+		echo, ok = parseEchoV6(pkt)
 	}
-	if err := ep.Bind(tcpip.FullAddress{}); err != nil {
-		log.Error().Msgf("Could not bind raw endpoint: %s", err.String())
-		return errors.New(err.String())
+	if !ok {
+		return false
 	}
 
-	// ep, err := s.GetStack().NewRawEndpoint(icmp.ProtocolNumber4, ipv4.ProtocolNumber, &wq, true)
-	// if err != nil {
-	// 	log.Debug().Msgf("Icmp responder error: %v", err)
-	// 	return errors.New(err.String())
-	// }
+	echo.quote.nicID = h.nicID
+	echo.quote.protocol = proto
+	go h.handleEcho(h.ctx, echo)
 
-	waitEntry, ch := waiter.NewChannelEntry(waiter.ReadableEvents)
-	wq.EventRegister(&waitEntry)
+	return true
+}
 
-	go h.processICMPPackets(ctx, ep, ch)
+func (h *ICMPHandler) handleEcho(ctx context.Context, echo echoPacket) {
+	log.Debug().Msgf("received ICMP echo request from %s to %s, id=%d seq=%d", echo.src, echo.dst, echo.id, echo.seq)
 
-	return nil
-}
+	stream, err := h.conn.GetStream(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("could not open stream with target")
+		return
+	}
+	// The stream is done after a single request and response.
+	defer stream.Close()
+
+	resp, err := protocol.Echo(stream, protocol.EchoRequest{
+		IP:   agentAddress(echo.dst),
+		ID:   echo.id,
+		Seq:  echo.seq,
+		Data: echo.data,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("could not proxy ICMP echo request")
+		return
+	}
 
-func (h *ICMPHandler) processICMPPackets(ctx context.Context, ep tcpip.Endpoint, ch chan struct{}) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ch:
-			var buf bytes.Buffer
-			_, err := ep.Read(&buf, tcpip.ReadOptions{})
-			if err != nil {
-				if _, ok := err.(*tcpip.ErrWouldBlock); !ok {
-					log.Error().Err(errors.New(err.String())).Msg("ICMP read error")
-				}
-				continue
-			}
-
-			neth := header.IPv4(buf.Bytes())
-			h.handleICMPPacket(neth, buf)
+	switch resp.Reason {
+	case protocol.ReasonNone:
+		var pkt []byte
+		if echo.quote.protocol == header.IPv4ProtocolNumber {
+			pkt = buildICMPv4EchoReply(echo, resp.Data)
+		} else {
+			pkt = buildICMPv6EchoReply(echo, resp.Data)
+		}
+		if err := h.stack.WriteRawPacket(h.nicID, echo.quote.protocol, buffer.MakeWithData(pkt)); err != nil {
+			log.Error().Msgf("could not write ICMP echo reply: %s", err)
 		}
+	case protocol.ReasonHostUnreachable, protocol.ReasonNetUnreachable, protocol.ReasonDenied:
+		if err := sendUnreachable(h.stack, echo.quote, resp.Reason); err != nil {
+			log.Error().Err(err).Msg("could not send ICMP destination unreachable")
+		}
+	default:
+		log.Debug().Stringer("reason", resp.Reason).Msgf("no ICMP echo reply from %s", echo.dst)
 	}
 }
 
-func (h *ICMPHandler) handleICMPPacket(neth header.IPv4, buf bytes.Buffer) {
-	view := buffer.MakeWithData(buf.Bytes())
-	packetbuff := stack.NewPacketBuffer(stack.PacketBufferOptions{
-		Payload:            view,
-		ReserveHeaderBytes: int(neth.HeaderLength()),
-		IsForwardedPacket:  true,
-	})
+func parseEchoV4(pkt *stack.PacketBuffer) (echoPacket, bool) {
+	v, ok := pkt.Data().PullUp(header.IPv4MinimumSize)
+	if !ok || header.IPv4(v).TransportProtocol() != header.ICMPv4ProtocolNumber {
+		return echoPacket{}, false
+	}
+
+	data := pkt.Data().AsRange().ToSlice()
+	ip := header.IPv4(data)
+	if !ip.IsValid(len(data)) || ip.More() || ip.FragmentOffset() != 0 {
+		return echoPacket{}, false
+	}
+	dst := ip.DestinationAddress()
+	if header.IsV4MulticastAddress(dst) || dst == header.IPv4Broadcast {
+		return echoPacket{}, false
+	}
 
-	packetbuff.NetworkProtocolNumber = ipv4.ProtocolNumber
-	packetbuff.TransportProtocolNumber = icmp.ProtocolNumber4
-	packetbuff.NetworkHeader().Consume(int(neth.HeaderLength()))
+	icmp := header.ICMPv4(ip.Payload())
+	if len(icmp) < header.ICMPv4MinimumSize || icmp.Type() != header.ICMPv4Echo {
+		return echoPacket{}, false
+	}
 
-	v, ok := packetbuff.Data().PullUp(header.ICMPv4MinimumSize)
-	if !ok {
-		log.Error().Msg("Failed to pull up ICMP header")
-		return
+	return echoPacket{
+		src:   ip.SourceAddress(),
+		dst:   dst,
+		id:    icmp.Ident(),
+		seq:   icmp.Sequence(),
+		data:  icmp.Payload(),
+		quote: packetQuote{data: data[:min(len(data), maxQuoteSize)]},
+	}, true
+}
+
+func parseEchoV6(pkt *stack.PacketBuffer) (echoPacket, bool) {
+	v, ok := pkt.Data().PullUp(header.IPv6MinimumSize)
+	if !ok || header.IPv6(v).TransportProtocol() != header.ICMPv6ProtocolNumber {
+		return echoPacket{}, false
+	}
+
+	data := pkt.Data().AsRange().ToSlice()
+	ip := header.IPv6(data)
+	if !ip.IsValid(len(data)) {
+		return echoPacket{}, false
 	}
-	hr := header.ICMPv4(v)
+	dst := ip.DestinationAddress()
+	if header.IsV6MulticastAddress(dst) {
+		return echoPacket{}, false
+	}
+
+	icmp := header.ICMPv6(ip.Payload())
+	if len(icmp) < header.ICMPv6EchoMinimumSize || icmp.Type() != header.ICMPv6EchoRequest {
+		return echoPacket{}, false
+	}
+
+	return echoPacket{
+		src:   ip.SourceAddress(),
+		dst:   dst,
+		id:    icmp.Ident(),
+		seq:   icmp.Sequence(),
+		data:  icmp.Payload(),
+		quote: packetQuote{data: data[:min(len(data), maxQuoteSize)]},
+	}, true
+}
+
+func buildICMPv4EchoReply(echo echoPacket, data []byte) []byte {
+	pkt, icmp := newICMPv4Packet(echo.dst, echo.src, header.ICMPv4MinimumSize+len(data))
+	icmp.SetType(header.ICMPv4EchoReply)
+	icmp.SetIdent(echo.id)
+	icmp.SetSequence(echo.seq)
+	copy(icmp[header.ICMPv4MinimumSize:], data)
+	icmp.SetChecksum(^checksum.Checksum(icmp, 0))
+
+	return pkt
+}
 
-	log.Info().
-		Str("source", neth.SourceAddress().String()).
-		Str("destination", neth.DestinationAddress().String()).
-		Bytes("type", []byte{byte(hr.Type())}).
-		Bytes("code", []byte{byte(hr.Code())}).
-		Msg("Received ICMP packet")
+func buildICMPv6EchoReply(echo echoPacket, data []byte) []byte {
+	pkt, icmp := newICMPv6Packet(echo.dst, echo.src, header.ICMPv6EchoMinimumSize+len(data))
+	icmp.SetType(header.ICMPv6EchoReply)
+	icmp.SetIdent(echo.id)
+	icmp.SetSequence(echo.seq)
+	copy(icmp[header.ICMPv6EchoMinimumSize:], data)
+	setICMPv6Checksum(pkt)
 
-	// Add ICMP response handling logic here
+	return pkt
 }
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"bytes"
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/checksum"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+)
+
+func TestBuildICMPv4EchoReply(t *testing.T) {
+	echo := echoPacket{
+		src: tcpip.AddrFrom4([4]byte{10, 0, 0, 1}),
+		dst: tcpip.AddrFrom4([4]byte{240, 0, 0, 1}),
+		id:  0x1234,
+		seq: 0xbeef,
+	}
+	data := []byte("odd sized payload")
+
+	ip := header.IPv4(buildICMPv4EchoReply(echo, data))
+	if !ip.IsValid(len(ip)) || !ip.IsChecksumValid() {
+		t.Fatal("invalid IPv4 header")
+	}
+	if ip.SourceAddress() != echo.dst || ip.DestinationAddress() != echo.src {
+		t.Errorf("got %s -> %s, want %s -> %s", ip.SourceAddress(), ip.DestinationAddress(), echo.dst, echo.src)
+	}
+
+	icmp := header.ICMPv4(ip.Payload())
+	if icmp.Type() != header.ICMPv4EchoReply || icmp.Ident() != echo.id || icmp.Sequence() != echo.seq {
+		t.Errorf("got type %d, id %#x, seq %#x", icmp.Type(), icmp.Ident(), icmp.Sequence())
+	}
+	if !bytes.Equal(icmp.Payload(), data) {
+		t.Errorf("got payload %q, want %q", icmp.Payload(), data)
+	}
+	if checksum.Checksum(icmp, 0) != 0xffff {
+		t.Error("invalid ICMPv4 checksum")
+	}
+}
+
+func TestBuildICMPv6EchoReply(t *testing.T) {
+	echo := echoPacket{
+		src: tcpip.AddrFrom16([16]byte{0xfd, 0, 15: 1}),
+		dst: tcpip.AddrFrom16([16]byte{0xfd, 0x52, 0x61, 0x69, 0x64, 0x6f, 15: 1}),
+		id:  0x1234,
+		seq: 0xbeef,
+	}
+	data := []byte("odd sized payload")
+
+	ip := header.IPv6(buildICMPv6EchoReply(echo, data))
+	if !ip.IsValid(len(ip)) {
+		t.Fatal("invalid IPv6 header")
+	}
+	if ip.SourceAddress() != echo.dst || ip.DestinationAddress() != echo.src {
+		t.Errorf("got %s -> %s, want %s -> %s", ip.SourceAddress(), ip.DestinationAddress(), echo.dst, echo.src)
+	}
+
+	icmp := header.ICMPv6(ip.Payload())
+	if icmp.Type() != header.ICMPv6EchoReply || icmp.Ident() != echo.id || icmp.Sequence() != echo.seq {
+		t.Errorf("got type %d, id %#x, seq %#x", icmp.Type(), icmp.Ident(), icmp.Sequence())
+	}
+	if !bytes.Equal(icmp.Payload(), data) {
+		t.Errorf("got payload %q, want %q", icmp.Payload(), data)
+	}
+	pseudo := header.PseudoHeaderChecksum(header.ICMPv6ProtocolNumber, ip.SourceAddress(), ip.DestinationAddress(), uint16(len(icmp)))
+	if checksum.Checksum(icmp, pseudo) != 0xffff {
+		t.Error("invalid ICMPv6 checksum")
+	}
+}
@@ -4,13 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/fr13n8/raido/proxy/protocol"
 	"github.com/fr13n8/raido/proxy/relay"
 	"github.com/fr13n8/raido/proxy/transport"
-	"github.com/fr13n8/raido/utils/ip"
 	"github.com/rs/zerolog/log"
-	"gvisor.dev/gvisor/pkg/tcpip"
 	"gvisor.dev/gvisor/pkg/tcpip/adapters/gonet"
 	"gvisor.dev/gvisor/pkg/tcpip/stack"
 	"gvisor.dev/gvisor/pkg/tcpip/transport/tcp"
@@ -18,29 +18,37 @@ import (
 )
 
 type TCPHandler struct {
-	conn transport.StreamConn
+	stack     *stack.Stack
+	conn      transport.StreamConn
+	forwarder *tcp.Forwarder
+	// quotes holds a copy of every pending SYN, keyed by flow, so that a
+	// failed connection can be answered with an ICMP error.
+	quotes synQuotes
 }
 
-func NewTCPHandler(conn transport.StreamConn) *TCPHandler {
-	return &TCPHandler{conn: conn}
+func NewTCPHandler(ctx context.Context, s *stack.Stack, conn transport.StreamConn) *TCPHandler {
+	h := &TCPHandler{stack: s, conn: conn}
+	// Set the TCP forwarder with a larger backlog size to handle more concurrent connections.
+	h.forwarder = tcp.NewForwarder(s, 0, 1024, func(fr *tcp.ForwarderRequest) {
+		h.HandleRequest(ctx, fr)
+	})
+	return h
 }
 
-func (h *TCPHandler) HandleRequest(ctx context.Context, fr *tcp.ForwarderRequest) {
-	// Create a waiter queue and TCP endpoint for the forwarded connection.
-	var wq waiter.Queue
-	ep, tcperr := fr.CreateEndpoint(&wq)
-	if tcperr != nil {
-		log.Error().Msgf("failed to create TCP endpoint: %s", tcperr)
-		fr.Complete(true)
-		return
+// HandlePacket is installed as the stack's TCP protocol handler.
+func (h *TCPHandler) HandlePacket(id stack.TransportEndpointID, pkt *stack.PacketBuffer) bool {
+	h.quotes.store(id, quotePacket(pkt))
+	if !h.forwarder.HandlePacket(id, pkt) {
+		h.quotes.delete(id)
+		return false
 	}
-	defer fr.Complete(false)
-
-	// Convert the TCP endpoint into a Go net TCP connection.
-	gonetConn := gonet.NewTCPConn(&wq, ep)
+	return true
+}
 
+func (h *TCPHandler) HandleRequest(ctx context.Context, fr *tcp.ForwarderRequest) {
 	// Get the flow info (source and destination addresses and ports).
 	s := fr.ID()
+	defer h.quotes.delete(s)
 	log.Info().Msgf("received TCP flow from %s to %s",
 		net.JoinHostPort(s.RemoteAddress.String(), fmt.Sprint(s.RemotePort)),
 		net.JoinHostPort(s.LocalAddress.String(), fmt.Sprint(s.LocalPort)))
@@ -49,15 +57,38 @@ func (h *TCPHandler) HandleRequest(ctx context.Context, fr *tcp.ForwarderRequest
 	stream, err := h.conn.GetStream(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("could not open stream with target")
+		fr.Complete(true)
 		return
 	}
-	defer h.conn.PutStream(stream)
+	// Streams carry a single request and are never reused.
+	defer stream.Close()
 
-	if err := h.establishConnection(ctx, stream, s); err != nil {
+	// The agent dials the target before the handshake with the client is
+	// completed, so that failures can be reported the way the target would.
+	resp, err := h.establishConnection(ctx, stream, s)
+	if err != nil {
 		log.Error().Err(err).Msg("Establish connection failed")
+		fr.Complete(true)
+		return
+	}
+	if !resp.Established {
+		h.reject(fr, resp.Reason)
 		return
 	}
 
+	// Create a waiter queue and TCP endpoint for the forwarded connection.
+	var wq waiter.Queue
+	ep, tcperr := fr.CreateEndpoint(&wq)
+	if tcperr != nil {
+		log.Error().Msgf("failed to create TCP endpoint: %s", tcperr)
+		fr.Complete(true)
+		return
+	}
+	defer fr.Complete(false)
+
+	// Convert the TCP endpoint into a Go net TCP connection.
+	gonetConn := gonet.NewTCPConn(&wq, ep)
+
 	// Pipe data between the stream and the TCP connection.
 	if err := relay.Pipe(stream, gonetConn); err != nil {
 		log.Error().Err(err).Msg("could not pipe data between stream and TCP connection")
@@ -65,58 +96,94 @@ func (h *TCPHandler) HandleRequest(ctx context.Context, fr *tcp.ForwarderRequest
 	}
 }
 
-func (h *TCPHandler) establishConnection(_ context.Context, stream transport.Stream, s stack.TransportEndpointID) error {
-	// Determine if the connection is IPv4 or IPv6.
-	network := protocol.Networkv4
-	if s.LocalAddress.To4() == (tcpip.Address{}) {
-		network = protocol.Networkv6
+// reject answers a connection request the agent could not establish.
+func (h *TCPHandler) reject(fr *tcp.ForwarderRequest, reason protocol.Reason) {
+	switch reason {
+	case protocol.ReasonTimeout:
+		// Drop the SYN like a filtering firewall would, the client times out on its own.
+		fr.Complete(false)
+	case protocol.ReasonHostUnreachable, protocol.ReasonNetUnreachable, protocol.ReasonDenied:
+		q, ok := h.quotes.load(fr.ID())
+		if !ok {
+			fr.Complete(true)
+			return
+		}
+		fr.Complete(false)
+		if err := sendUnreachable(h.stack, q, reason); err != nil {
+			log.Error().Err(err).Msg("could not send ICMP destination unreachable")
+		}
+	default:
+		fr.Complete(true)
 	}
+}
 
-	// If the address is from a reserved network range, forward to 127.0.0.1
-	localAddress := net.ParseIP(s.LocalAddress.String())
-	if ip.LoopbackRoute.Network.Contains(localAddress) {
-		localAddress = net.ParseIP("127.0.0.1")
+func (h *TCPHandler) establishConnection(_ context.Context, stream transport.Stream, s stack.TransportEndpointID) (protocol.ConnectResponse, error) {
+	// Send the connection establishment request and wait for the response.
+	dec, err := protocol.EstablishConnection(stream, targetAddress(s, protocol.TransportTCP))
+	if err != nil {
+		log.Error().Err(err).Msg("could not establish connection")
+		return protocol.ConnectResponse{}, err
 	}
 
-	// Create a structure with IP address and port information.
-	ipStruct := protocol.IPAddressWithPortProtocol{
-		IP:       localAddress,
-		Port:     s.LocalPort,
-		Protocol: protocol.TransportTCP,
-		Network:  network,
+	// Check if the connection was established successfully.
+	if !dec.Established {
+		log.Error().Stringer("reason", dec.Reason).Msgf("failed to establish TCP connection with target: %s",
+			net.JoinHostPort(s.LocalAddress.String(), fmt.Sprint(s.LocalPort)))
 	}
 
-	// Encode the IP and port structure.
-	encodedIP, err := ipStruct.Encode()
-	if err != nil {
-		log.Error().Err(err).Msg("could not encode address")
-		return fmt.Errorf("could not encode address: %w", err)
-	}
+	return dec, nil
+}
 
-	// Send the connection establishment request via the QUIC stream.
-	encoder := protocol.NewEncoder[protocol.Data](stream)
-	if err := encoder.Encode(protocol.Data{
-		Command: protocol.EstablishConnectionCmd,
-		Body:    encodedIP,
-	}); err != nil {
-		log.Error().Err(err).Msg("could not send connection establishment data")
-		return fmt.Errorf("could not send connection establishment data: %w", err)
-	}
+// synQuoteTTL bounds the time a SYN is quoted. The forwarder drops SYNs
+// without handling them when too many are in flight, their quotes are only
+// released when they expire.
+const synQuoteTTL = time.Minute
+
+// synQuotes holds the quotes of pending SYNs until their request is handled
+// or they expire.
+type synQuotes struct {
+	mu     sync.Mutex
+	quotes map[stack.TransportEndpointID]synQuote
+	// sweep is the time expired quotes are released next.
+	sweep time.Time
+}
 
-	// Receive the response from the QUIC stream.
-	decoder := protocol.NewDecoder[protocol.ConnectResponse](stream)
-	dec, err := decoder.Decode()
-	if err != nil {
-		log.Error().Err(err).Msg("could not decode connection establishment response")
-		return fmt.Errorf("could not decode connection establishment response: %w", err)
+type synQuote struct {
+	packetQuote
+	expires time.Time
+}
+
+func (q *synQuotes) store(id stack.TransportEndpointID, quote packetQuote) {
+	now := time.Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.quotes == nil {
+		q.quotes = make(map[stack.TransportEndpointID]synQuote)
 	}
+	if now.After(q.sweep) {
+		for id, quote := range q.quotes {
+			if now.After(quote.expires) {
+				delete(q.quotes, id)
+			}
+		}
+		q.sweep = now.Add(synQuoteTTL)
+	}
+	q.quotes[id] = synQuote{packetQuote: quote, expires: now.Add(synQuoteTTL)}
+}
 
-	// Check if the connection was established successfully.
-	if !dec.Established {
-		log.Error().Msgf("failed to establish TCP connection with target: %s",
-			net.JoinHostPort(s.LocalAddress.String(), fmt.Sprint(s.LocalPort)))
-		return fmt.Errorf("failed to establish TCP connection with target")
+func (q *synQuotes) load(id stack.TransportEndpointID) (packetQuote, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	quote, ok := q.quotes[id]
+	if !ok || time.Now().After(quote.expires) {
+		return packetQuote{}, false
 	}
+	return quote.packetQuote, true
+}
 
-	return nil
+func (q *synQuotes) delete(id stack.TransportEndpointID) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.quotes, id)
 }
@@ -3,12 +3,14 @@ package handler
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 
 	"github.com/fr13n8/raido/proxy/protocol"
 	"github.com/fr13n8/raido/proxy/relay"
 	"github.com/fr13n8/raido/proxy/transport"
 	"github.com/fr13n8/raido/utils/ip"
+	"github.com/fr13n8/raido/viface/conntrack"
 	"github.com/rs/zerolog/log"
 	"gvisor.dev/gvisor/pkg/tcpip"
 	"gvisor.dev/gvisor/pkg/tcpip/adapters/gonet"
@@ -18,29 +20,43 @@ import (
 )
 
 type UDPHandler struct {
-	conn transport.StreamConn
+	ctx   context.Context
+	stack *stack.Stack
+	conn  transport.StreamConn
+	// caps are the capabilities negotiated with the agent.
+	caps protocol.Capability
+	// mux carries the flows over connection datagrams, it is nil when the
+	// connection or the agent does not support them.
+	mux *transport.DatagramMux
+	// conntrack expires idle flows and limits their number.
+	conntrack *conntrack.Table
 }
 
-func NewUDPHandler(conn transport.StreamConn) *UDPHandler {
-	return &UDPHandler{conn: conn}
+func NewUDPHandler(ctx context.Context, s *stack.Stack, conn transport.StreamConn, caps protocol.Capability, mux *transport.DatagramMux, ct *conntrack.Table) *UDPHandler {
+	return &UDPHandler{ctx: ctx, stack: s, conn: conn, caps: caps, mux: mux, conntrack: ct}
 }
 
-func (h *UDPHandler) HandleRequest(ctx context.Context, fr *udp.ForwarderRequest) {
+// HandlePacket is installed as the stack's UDP protocol handler.
+func (h *UDPHandler) HandlePacket(id stack.TransportEndpointID, pkt *stack.PacketBuffer) bool {
 	// Create endpoint as quickly as possible to avoid UDP
 	// race conditions, when user sends multiple frames
 	// one after another.
+	fr := udp.NewForwarderRequest(h.stack, id, pkt)
+	q := quotePacket(pkt)
+
 	var wq waiter.Queue
 	ep, tcperr := fr.CreateEndpoint(&wq)
 	if tcperr != nil {
 		log.Error().Msgf("could not create UDP endpoint: %s", tcperr)
-		return
+		return true
 	}
 
-	// Set up the UDP connection with the new endpoint and pipe data
-	gonetConn := gonet.NewUDPConn(&wq, ep)
+	go h.HandleRequest(h.ctx, id, q, h.conntrack.Add(id, gonet.NewUDPConn(&wq, ep)))
+	return true
+}
 
+func (h *UDPHandler) HandleRequest(ctx context.Context, s stack.TransportEndpointID, q packetQuote, gonetConn io.ReadWriteCloser) {
 	// Identify the flow and log it for better visibility
-	s := fr.ID()
 	log.Info().Msgf("received UDP flow from %s to %s",
 		net.JoinHostPort(s.RemoteAddress.String(), fmt.Sprint(s.RemotePort)),
 		net.JoinHostPort(s.LocalAddress.String(), fmt.Sprint(s.LocalPort)))
@@ -49,76 +65,120 @@ func (h *UDPHandler) HandleRequest(ctx context.Context, fr *udp.ForwarderRequest
 	stream, err := h.conn.GetStream(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("could not open stream with target")
+		gonetConn.Close()
+		return
+	}
+	// Streams carry a single request and are never reused.
+	defer stream.Close()
+
+	if h.mux != nil {
+		h.handleDatagramFlow(ctx, stream, s, q, gonetConn)
 		return
 	}
-	defer h.conn.PutStream(stream)
 
-	if err := h.establishConnection(ctx, stream, s); err != nil {
+	resp, err := h.establishConnection(ctx, stream, s)
+	if err != nil {
 		log.Error().Err(err).Msg("Establish connection failed")
+		gonetConn.Close()
+		return
+	}
+	if !resp.Established {
+		gonetConn.Close()
+		h.reject(q, resp.Reason)
 		return
 	}
 
-	// Pipe data between the stream and the UDP connection.
-	if err := relay.Pipe(stream, gonetConn); err != nil {
+	// Pipe datagrams between the stream and the UDP connection.
+	if err := relay.Pipe(relay.NewUDPStream(stream, h.caps), gonetConn); err != nil {
 		log.Error().Err(err).Msg("could not pipe data between stream and UDP connection")
 		return
 	}
 }
 
-func (h *UDPHandler) establishConnection(_ context.Context, stream transport.Stream, s stack.TransportEndpointID) error {
-	// Handle protocol versioning and IP conversion
-	network := protocol.Networkv4
-	if s.LocalAddress.To4() == (tcpip.Address{}) {
-		network = protocol.Networkv6
-	}
+// handleDatagramFlow relays the flow over connection datagrams, the stream
+// only carries the flow setup and datagrams too large for the connection.
+func (h *UDPHandler) handleDatagramFlow(ctx context.Context, stream transport.Stream, s stack.TransportEndpointID, q packetQuote, gonetConn io.ReadWriteCloser) {
+	flow := h.mux.Open()
+	defer flow.Close()
 
-	// If the address is from a reserved network range, forward to 127.0.0.1
-	localAddress := net.ParseIP(s.LocalAddress.String())
-	if ip.LoopbackRoute.Network.Contains(localAddress) {
-		localAddress = net.ParseIP("127.0.0.1")
+	resp, err := protocol.EstablishDatagramFlow(stream, protocol.DatagramFlowRequest{
+		ID:   flow.ID(),
+		Addr: targetAddress(s, protocol.TransportUDP),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Establish datagram flow failed")
+		gonetConn.Close()
+		return
 	}
-
-	// Prepare the IP and port encoding for the protocol
-	ipStruct := protocol.IPAddressWithPortProtocol{
-		IP:       localAddress,
-		Port:     s.LocalPort,
-		Protocol: protocol.TransportUDP,
-		Network:  network,
+	if !resp.Established {
+		log.Error().Stringer("reason", resp.Reason).Msgf("could not establish datagram flow with target UDP:%s",
+			net.JoinHostPort(s.LocalAddress.String(), fmt.Sprint(s.LocalPort)))
+		gonetConn.Close()
+		h.reject(q, resp.Reason)
+		return
 	}
 
-	// Encode the address and send to the stream
-	encodedIP, err := ipStruct.Encode()
-	if err != nil {
-		log.Error().Err(err).Msg("could not encode IP address")
-		return fmt.Errorf("could not encode IP address: %w", err)
+	if err := relay.PipeDatagramFlow(gonetConn, flow, stream); err != nil {
+		log.Error().Err(err).Msg("could not pipe datagrams between flow and UDP connection")
 	}
+}
 
-	encoder := protocol.NewEncoder[protocol.Data](stream)
-	decoder := protocol.NewDecoder[protocol.ConnectResponse](stream)
-
-	// Send the connection establishment command
-	if err := encoder.Encode(protocol.Data{
-		Command: protocol.EstablishConnectionCmd,
-		Body:    encodedIP,
-	}); err != nil {
-		log.Error().Err(err).Msg("could not send establish connection command")
-		return fmt.Errorf("could not send establish connection command: %w", err)
+// reject answers the first datagram of a flow the agent could not establish.
+// Timeouts and unknown failures are dropped silently.
+func (h *UDPHandler) reject(q packetQuote, reason protocol.Reason) {
+	switch reason {
+	case protocol.ReasonRefused, protocol.ReasonHostUnreachable, protocol.ReasonNetUnreachable, protocol.ReasonDenied:
+		if err := sendUnreachable(h.stack, q, reason); err != nil {
+			log.Error().Err(err).Msg("could not send ICMP destination unreachable")
+		}
 	}
+}
 
-	// Await the response from the target
-	dec, err := decoder.Decode()
+func (h *UDPHandler) establishConnection(_ context.Context, stream transport.Stream, s stack.TransportEndpointID) (protocol.ConnectResponse, error) {
+	// Send the connection establishment command and await the response from the target
+	dec, err := protocol.EstablishConnection(stream, targetAddress(s, protocol.TransportUDP))
 	if err != nil {
-		log.Error().Err(err).Msg("could not decode response from target")
-		return fmt.Errorf("could not decode response from target: %w", err)
+		log.Error().Err(err).Msg("could not establish connection")
+		return protocol.ConnectResponse{}, err
 	}
 
 	// Check if the connection was established successfully
 	if !dec.Established {
-		log.Error().Msgf("could not establish connection with target UDP:%s",
-			net.JoinHostPort(s.LocalAddress.String(), fmt.Sprint(s.LocalPort)))
-		return fmt.Errorf("could not establish connection with target UDP:%s",
+		log.Error().Stringer("reason", dec.Reason).Msgf("could not establish connection with target UDP:%s",
 			net.JoinHostPort(s.LocalAddress.String(), fmt.Sprint(s.LocalPort)))
 	}
 
-	return nil
+	return dec, nil
+}
+
+// targetAddress returns the address the agent connects to for a flow.
+func targetAddress(s stack.TransportEndpointID, transportProtocol uint8) protocol.IPAddressWithPortProtocol {
+	// Handle protocol versioning and IP conversion
+	network := protocol.Networkv4
+	if s.LocalAddress.To4() == (tcpip.Address{}) {
+		network = protocol.Networkv6
+	}
+
+	return protocol.IPAddressWithPortProtocol{
+		IP:       agentAddress(s.LocalAddress),
+		Port:     s.LocalPort,
+		Protocol: transportProtocol,
+		Network:  network,
+	}
+}
+
+// agentAddress returns the address the agent uses for a destination of the
+// stack.
+func agentAddress(addr tcpip.Address) net.IP {
+	// If the address is from a reserved network range, forward to the
+	// agent's loopback address of the same family.
+	localAddress := net.IP(addr.AsSlice())
+	switch {
+	case ip.LoopbackRoute.Network.Contains(localAddress):
+		localAddress = net.IPv4(127, 0, 0, 1)
+	case ip.LoopbackRoute6.Network.Contains(localAddress):
+		localAddress = net.IPv6loopback
+	}
+
+	return localAddress
 }
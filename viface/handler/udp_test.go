@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net"
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/tcpip"
+)
+
+func TestAgentAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"240.1.0.0", "127.0.0.1"},
+		{"240.0.0.0", "127.0.0.1"},
+		{"240.255.0.53", "127.0.0.1"},
+		{"255.255.255.254", "127.0.0.1"},
+		{"239.255.255.255", "239.255.255.255"},
+		{"10.1.0.3", "10.1.0.3"},
+		{"fd52:6169:646f:1::", "::1"},
+		{"fd52:6169:646f::", "::1"},
+		{"fd52:6169:646f:ff::1", "::1"},
+		{"fd52:6169:6470::", "fd52:6169:6470::"},
+		{"fd00::3", "fd00::3"},
+	}
+	for _, tt := range tests {
+		addr := tcpip.AddrFromSlice(net.ParseIP(tt.addr))
+		if v4 := net.ParseIP(tt.addr).To4(); v4 != nil {
+			addr = tcpip.AddrFromSlice(v4)
+		}
+		if got := agentAddress(addr); !got.Equal(net.ParseIP(tt.want)) {
+			t.Errorf("agentAddress(%s) = %s, want %s", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fr13n8/raido/proxy/protocol"
+	"gvisor.dev/gvisor/pkg/buffer"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/checksum"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+)
+
+const (
+	// maxQuoteSize limits how much of the offending packet is echoed back in an
+	// ICMP error, which keeps the error below the minimum IPv4 MTU (RFC 1812).
+	maxQuoteSize = 548
+	icmpTTL      = 64
+)
+
+// packetQuote is a copy of the beginning of a packet that may have to be
+// answered with an ICMP error after the packet buffer itself is gone.
+type packetQuote struct {
+	nicID    tcpip.NICID
+	protocol tcpip.NetworkProtocolNumber
+	data     []byte
+}
+
+func quotePacket(pkt *stack.PacketBuffer) packetQuote {
+	payload := stack.PayloadSince(pkt.NetworkHeader())
+	defer payload.Release()
+
+	data := payload.AsSlice()
+	return packetQuote{
+		nicID:    pkt.NICID,
+		protocol: pkt.NetworkProtocolNumber,
+		data:     append([]byte(nil), data[:min(len(data), maxQuoteSize)]...),
+	}
+}
+
+// sendUnreachable answers the quoted packet with an ICMP destination
+// unreachable message matching the reason reported by the agent.
+func sendUnreachable(s *stack.Stack, q packetQuote, reason protocol.Reason) error {
+	var pkt []byte
+	switch q.protocol {
+	case header.IPv4ProtocolNumber:
+		code, ok := icmpv4UnreachableCode(reason)
+		if !ok {
+			return fmt.Errorf("no ICMPv4 code for reason \"%s\"", reason)
+		}
+		pkt = buildICMPv4Unreachable(q.data, code)
+	case header.IPv6ProtocolNumber:
+		code, ok := icmpv6UnreachableCode(reason)
+		if !ok {
+			return fmt.Errorf("no ICMPv6 code for reason \"%s\"", reason)
+		}
+		pkt = buildICMPv6Unreachable(q.data, code)
+	default:
+		return fmt.Errorf("unsupported network protocol %d", q.protocol)
+	}
+	if pkt == nil {
+		return errors.New("quoted packet is malformed")
+	}
+
+	if err := s.WriteRawPacket(q.nicID, q.protocol, buffer.MakeWithData(pkt)); err != nil {
+		return fmt.Errorf("could not write ICMP packet: %s", err)
+	}
+
+	return nil
+}
+
+func icmpv4UnreachableCode(reason protocol.Reason) (header.ICMPv4Code, bool) {
+	switch reason {
+	case protocol.ReasonRefused:
+		return header.ICMPv4PortUnreachable, true
+	case protocol.ReasonHostUnreachable:
+		return header.ICMPv4HostUnreachable, true
+	case protocol.ReasonNetUnreachable:
+		return header.ICMPv4NetUnreachable, true
+	case protocol.ReasonDenied:
+		return header.ICMPv4AdminProhibited, true
+	default:
+		return 0, false
+	}
+}
+
+func icmpv6UnreachableCode(reason protocol.Reason) (header.ICMPv6Code, bool) {
+	switch reason {
+	case protocol.ReasonRefused:
+		return header.ICMPv6PortUnreachable, true
+	case protocol.ReasonHostUnreachable:
+		return header.ICMPv6AddressUnreachable, true
+	case protocol.ReasonNetUnreachable:
+		return header.ICMPv6NetworkUnreachable, true
+	case protocol.ReasonDenied:
+		return header.ICMPv6Prohibited, true
+	default:
+		return 0, false
+	}
+}
+
+func buildICMPv4Unreachable(quoted []byte, code header.ICMPv4Code) []byte {
+	if len(quoted) < header.IPv4MinimumSize {
+		return nil
+	}
+	orig := header.IPv4(quoted)
+
+	pkt, icmp := newICMPv4Packet(orig.DestinationAddress(), orig.SourceAddress(), header.ICMPv4MinimumSize+len(quoted))
+	icmp.SetType(header.ICMPv4DstUnreachable)
+	icmp.SetCode(code)
+	copy(icmp[header.ICMPv4MinimumSize:], quoted)
+	icmp.SetChecksum(^checksum.Checksum(icmp, 0))
+
+	return pkt
+}
+
+func buildICMPv6Unreachable(quoted []byte, code header.ICMPv6Code) []byte {
+	if len(quoted) < header.IPv6MinimumSize {
+		return nil
+	}
+	orig := header.IPv6(quoted)
+
+	pkt, icmp := newICMPv6Packet(orig.DestinationAddress(), orig.SourceAddress(), header.ICMPv6DstUnreachableMinimumSize+len(quoted))
+	icmp.SetType(header.ICMPv6DstUnreachable)
+	icmp.SetCode(code)
+	copy(icmp[header.ICMPv6DstUnreachableMinimumSize:], quoted)
+	setICMPv6Checksum(pkt)
+
+	return pkt
+}
+
+// newICMPv4Packet allocates an IPv4 packet from src to dst carrying an ICMP
+// message of the given size and returns it along with the ICMP part.
+func newICMPv4Packet(src, dst tcpip.Address, size int) ([]byte, header.ICMPv4) {
+	pkt := make([]byte, header.IPv4MinimumSize+size)
+	ip := header.IPv4(pkt)
+	ip.Encode(&header.IPv4Fields{
+		TotalLength: uint16(len(pkt)),
+		TTL:         icmpTTL,
+		Protocol:    uint8(header.ICMPv4ProtocolNumber),
+		SrcAddr:     src,
+		DstAddr:     dst,
+	})
+	ip.SetChecksum(^ip.CalculateChecksum())
+
+	return pkt, header.ICMPv4(pkt[header.IPv4MinimumSize:])
+}
+
+// newICMPv6Packet is the IPv6 counterpart of newICMPv4Packet. The checksum
+// must be set with setICMPv6Checksum once the message is complete.
+func newICMPv6Packet(src, dst tcpip.Address, size int) ([]byte, header.ICMPv6) {
+	pkt := make([]byte, header.IPv6MinimumSize+size)
+	ip := header.IPv6(pkt)
+	ip.Encode(&header.IPv6Fields{
+		PayloadLength:     uint16(size),
+		TransportProtocol: header.ICMPv6ProtocolNumber,
+		HopLimit:          icmpTTL,
+		SrcAddr:           src,
+		DstAddr:           dst,
+	})
+
+	return pkt, header.ICMPv6(pkt[header.IPv6MinimumSize:])
+}
+
+func setICMPv6Checksum(pkt []byte) {
+	ip := header.IPv6(pkt)
+	icmp := header.ICMPv6(ip.Payload())
+	icmp.SetChecksum(0)
+	icmp.SetChecksum(header.ICMPv6Checksum(header.ICMPv6ChecksumParams{
+		Header: icmp,
+		Src:    ip.SourceAddress(),
+		Dst:    ip.DestinationAddress(),
+	}))
+}
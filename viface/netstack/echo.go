@@ -0,0 +1,29 @@
+package netstack
+
+import (
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/link/nested"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+)
+
+// echoEndpoint is a link endpoint that hands ICMP echo requests to a handler
+// before they reach the stack. gVisor answers IPv6 echo requests to any
+// address in promiscuous mode, so they have to be taken off the link.
+type echoEndpoint struct {
+	nested.Endpoint
+	handle func(tcpip.NetworkProtocolNumber, *stack.PacketBuffer) bool
+}
+
+func newEchoEndpoint(child stack.LinkEndpoint, handle func(tcpip.NetworkProtocolNumber, *stack.PacketBuffer) bool) *echoEndpoint {
+	e := &echoEndpoint{handle: handle}
+	e.Init(child, e)
+	return e
+}
+
+// DeliverNetworkPacket implements stack.NetworkDispatcher.
+func (e *echoEndpoint) DeliverNetworkPacket(protocol tcpip.NetworkProtocolNumber, pkt *stack.PacketBuffer) {
+	if e.handle(protocol, pkt) {
+		return
+	}
+	e.Endpoint.DeliverNetworkPacket(protocol, pkt)
+}
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/fr13n8/raido/proxy/protocol"
 	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/fr13n8/raido/viface/conntrack"
 	"github.com/fr13n8/raido/viface/handler"
 	"gvisor.dev/gvisor/pkg/tcpip"
 	"gvisor.dev/gvisor/pkg/tcpip/header"
@@ -18,12 +20,6 @@ import (
 
 type Option func(*stack.Stack) error
 
-func icmpHandler(conn transport.StreamConn) Option {
-	return func(s *stack.Stack) error {
-		return handler.NewICMPHandler(s, conn).Start(context.TODO())
-	}
-}
-
 func tcpSackEnabledOption(v bool) Option {
 	return func(s *stack.Stack) error {
 		sackEnabledOpt := tcpip.TCPSACKEnabled(v)
@@ -54,13 +50,13 @@ func tcpUseSynCookies(v bool) Option {
 	}
 }
 
-func tcpHandler(ctx context.Context, conn transport.StreamConn) Option {
+func tcpHandler(ctx context.Context, conn transport.StreamConn, dns *handler.DNSHandler) Option {
 	return func(s *stack.Stack) error {
-		// Set the TCP forwarder with a larger backlog size to handle more concurrent connections.
-		tcpForwarder := tcp.NewForwarder(s, 0, 1024, func(fr *tcp.ForwarderRequest) {
-			go handler.NewTCPHandler(conn).HandleRequest(ctx, fr)
-		})
-		s.SetTransportProtocolHandler(tcp.ProtocolNumber, tcpForwarder.HandlePacket)
+		h := handler.NewTCPHandler(ctx, s, conn).HandlePacket
+		if dns != nil {
+			h = dns.TCP(h)
+		}
+		s.SetTransportProtocolHandler(tcp.ProtocolNumber, h)
 		return nil
 	}
 }
@@ -91,13 +87,13 @@ func tcpSendReceiveBufSize(size int) Option {
 	}
 }
 
-func udpHandler(ctx context.Context, conn transport.StreamConn) Option {
+func udpHandler(ctx context.Context, conn transport.StreamConn, caps protocol.Capability, mux *transport.DatagramMux, ct *conntrack.Table, dns *handler.DNSHandler) Option {
 	return func(s *stack.Stack) error {
-		udpForwarder := udp.NewForwarder(s, func(fr *udp.ForwarderRequest) bool {
-			go handler.NewUDPHandler(conn).HandleRequest(ctx, fr)
-			return true
-		})
-		s.SetTransportProtocolHandler(udp.ProtocolNumber, udpForwarder.HandlePacket)
+		h := handler.NewUDPHandler(ctx, s, conn, caps, mux, ct).HandlePacket
+		if dns != nil {
+			h = dns.UDP(h)
+		}
+		s.SetTransportProtocolHandler(udp.ProtocolNumber, h)
 		return nil
 	}
 }
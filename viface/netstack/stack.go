@@ -2,9 +2,15 @@ package netstack
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/fr13n8/raido/config"
+	"github.com/fr13n8/raido/proxy/protocol"
 	"github.com/fr13n8/raido/proxy/transport"
+	"github.com/fr13n8/raido/viface/conntrack"
+	"github.com/fr13n8/raido/viface/handler"
+	"github.com/rs/zerolog/log"
 	"gvisor.dev/gvisor/pkg/tcpip/network/ipv4"
 	"gvisor.dev/gvisor/pkg/tcpip/network/ipv6"
 	"gvisor.dev/gvisor/pkg/tcpip/stack"
@@ -16,10 +22,17 @@ import (
 type NetStack struct {
 	Stack *stack.Stack
 	// device stack.LinkEndpoint
+	cancel context.CancelFunc
 }
 
 // NewNetStack creates and configures a new network stack.
-func NewNetStack(ctx context.Context, device stack.LinkEndpoint, conn transport.StreamConn) (*NetStack, error) {
+// ICMP echo requests are proxied to the agent only if it supports CapICMP and
+// UDP flows use connection datagrams only if it supports CapDatagrams. DNS
+// queries to the tunnel resolver are answered only if it supports CapDNS. ct
+// limits the UDP flows tracked by the stack.
+func NewNetStack(ctx context.Context, device stack.LinkEndpoint, conn transport.StreamConn, caps protocol.Capability, ct config.Conntrack) (*NetStack, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
 	// Initialize the network stack with the necessary protocols.
 	s := stack.New(stack.Options{
 		NetworkProtocols: []stack.NetworkProtocolFactory{
@@ -37,36 +50,65 @@ func NewNetStack(ctx context.Context, device stack.LinkEndpoint, conn transport.
 	// Get the next NIC ID from the stack.
 	nicID := s.NextNICID()
 
+	if caps.Has(protocol.CapICMP) {
+		device = newEchoEndpoint(device, handler.NewICMPHandler(ctx, s, nicID, conn).HandlePacket)
+	}
+
+	var mux *transport.DatagramMux
+	if dc, ok := conn.(transport.DatagramConn); ok && dc.SupportsDatagrams() && caps.Has(protocol.CapDatagrams) {
+		mux = transport.NewDatagramMux(dc)
+		go func() {
+			if err := mux.Serve(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				log.Error().Err(err).Msg("datagram multiplexer stopped")
+			}
+		}()
+	}
+
+	udpFlows := conntrack.New(ct)
+	go udpFlows.Run(ctx)
+
+	var dns *handler.DNSHandler
+	if caps.Has(protocol.CapDNS) {
+		dns = handler.NewDNSHandler(ctx, s, conn, udpFlows)
+	}
+
 	// Define the configuration options.
 	options := []Option{
 		tcpSackEnabledOption(true), // Enable TCP SACK.
 		// tcpRecovery(tcpip.TCPRACKLossDetection), // Use RACK loss detection.
-		tcpUseSynCookies(false),                // Enable SYN cookies.
-		routeTableOption(nicID),                // Configure routing.
-		forwardingOption(true),                 // Enable packet forwarding.
-		ttlOption(64),                          // Set default TTL to 64.
-		tcpSendReceiveBufSize(4 * 1024 * 1024), // Set TCP buffer size.
-		tcpBufferSizeAutoTune(true),            // Enable auto-tuning for buffer size.
-		icmpHandler(conn),                      // Set up ICMP handler.
-		tcpHandler(ctx, conn),                  // Set up TCP handler.
-		udpHandler(ctx, conn),                  // Set up UDP handler.
-		createNicOption(ctx, nicID, device),    // Create NIC with the specified ID.
-		promiscuousModeOption(nicID, true),     // Enable promiscuous mode.
-		spoofingOption(nicID, true),            // Enable spoofing.
+		tcpUseSynCookies(false),                         // Enable SYN cookies.
+		routeTableOption(nicID),                         // Configure routing.
+		forwardingOption(true),                          // Enable packet forwarding.
+		ttlOption(64),                                   // Set default TTL to 64.
+		tcpSendReceiveBufSize(4 * 1024 * 1024),          // Set TCP buffer size.
+		tcpBufferSizeAutoTune(true),                     // Enable auto-tuning for buffer size.
+		tcpHandler(ctx, conn, dns),                      // Set up TCP handler.
+		udpHandler(ctx, conn, caps, mux, udpFlows, dns), // Set up UDP handler.
+		createNicOption(ctx, nicID, device),             // Create NIC with the specified ID.
+		promiscuousModeOption(nicID, true),              // Enable promiscuous mode.
+		spoofingOption(nicID, true),                     // Enable spoofing.
 	}
 
 	// Apply the options and return any errors encountered.
 	for _, opt := range options {
 		if err := opt(s); err != nil {
+			cancel()
 			return nil, fmt.Errorf("failed to apply option: %w", err) // Return error instead of logging fatally.
 		}
 	}
 
 	return &NetStack{
-		Stack: s,
+		Stack:  s,
+		cancel: cancel,
 	}, nil
 }
 
 func (ns *NetStack) Close() {
+	ns.cancel()
+	// Removing the NICs detaches the link endpoints, so the device can be
+	// handed to another stack.
+	for id := range ns.Stack.NICInfo() {
+		ns.Stack.RemoveNIC(id)
+	}
 	ns.Stack.Close()
 }
@@ -70,7 +70,8 @@ func NewLinkTun() (*LinkTun, error) {
 }
 
 // add the next available network address to the interface routes from the range 240.0.0.0/4
-// and each time a new interface is created the address is increased by 1 (for example, 240.1.0.0/32)
+// and each time a new interface is created the address is increased by 1 (for example, 240.1.0.0/32).
+// The same number is used for the IPv6 loopback route (for example, fd52:6169:646f:1::/128).
 func (l *LinkTun) AddLoopbackRoute() error {
 	routes, err := netlink.RouteList(nil, netlink.FAMILY_ALL)
 	if err != nil {
@@ -79,10 +80,11 @@ func (l *LinkTun) AddLoopbackRoute() error {
 
 	var nextAddr byte
 	for _, route := range routes {
-		if route.Dst.IP[0] == 240 {
-			if route.Dst.IP[1] > nextAddr {
-				nextAddr = route.Dst.IP[1]
-			}
+		if route.Dst == nil {
+			continue
+		}
+		if n, ok := ip.LoopbackIndex(route.Dst.IP); ok && n > nextAddr {
+			nextAddr = n
 		}
 	}
 
@@ -101,6 +103,17 @@ func (l *LinkTun) AddLoopbackRoute() error {
 		return fmt.Errorf("failed to add route to interface: %w", err)
 	}
 
+	// IPv6 may be disabled on the host, the IPv4 route is enough then.
+	if err := netlink.RouteAdd(&netlink.Route{
+		LinkIndex: l.link.Attrs().Index,
+		Dst: &net.IPNet{
+			IP:   ip.LoopbackAddress6(nextAddr),
+			Mask: net.CIDRMask(128, 128),
+		},
+	}); err != nil && !errors.Is(err, syscall.EAFNOSUPPORT) {
+		return fmt.Errorf("failed to add IPv6 route to interface: %w", err)
+	}
+
 	return nil
 }
 
@@ -233,7 +246,7 @@ func (l *LinkTun) Routes() ([]string, error) {
 			log.Error().Err(err).Msg("could not parse local address")
 			continue
 		}
-		if !ip.LoopbackRoute.Network.Contains(localAddress.IP) {
+		if !ip.IsLoopback(localAddress.IP) {
 			destinationRoutes = append(destinationRoutes, route.Dst.String())
 		}
 	}
@@ -252,7 +265,24 @@ func (l *LinkTun) GetLoopbackRoute() (string, error) {
 			log.Error().Err(err).Msg("could not parse local address")
 			continue
 		}
-		if ip.LoopbackRoute.Network.Contains(localAddress.IP) {
+		if ip.LoopbackRoute.Network.Contains(localAddress.IP) && !ip.IsResolverAddress(localAddress.IP) {
+			return route.Dst.String(), nil
+		}
+	}
+
+	return "", nil
+}
+
+// GetLoopbackRoute6 returns the IPv6 loopback route of the interface, or an
+// empty string if it has none.
+func (l *LinkTun) GetLoopbackRoute6() (string, error) {
+	routes, err := netlink.RouteList(l.link, netlink.FAMILY_V6)
+	if err != nil {
+		return "", fmt.Errorf("failed to get route list: %w", err)
+	}
+
+	for _, route := range routes {
+		if route.Dst != nil && ip.LoopbackRoute6.Network.Contains(route.Dst.IP) {
 			return route.Dst.String(), nil
 		}
 	}
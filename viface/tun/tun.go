@@ -8,4 +8,5 @@ import (
 type TUNDevice interface {
 	Name() string
 	Device() stack.LinkEndpoint
+	Close() error
 }